@@ -1,6 +1,9 @@
 package deck
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // Suit represents the suit of the card (spade, heart, diamond, club)
 type Suit int
@@ -105,3 +108,33 @@ func NewCard(face Face, suit Suit) Card {
 func (c *Card) GetSignature() string {
 	return fmt.Sprintf("%x%x", c.Face(), c.Suit())
 }
+
+// faceLetters and suitLetters name the faces and suits in short notation
+const (
+	faceLetters = "A23456789TJQK"
+	suitLetters = "cdhs"
+)
+
+// ParseCard reads a card in short notation, its face (A23456789TJQK) then its suit (cdhs), e.g. Ah or Tc
+func ParseCard(s string) (Card, error) {
+	if len(s) == 2 {
+		face, suit := strings.IndexByte(faceLetters, s[0]), strings.IndexByte(suitLetters, s[1])
+		if face >= 0 && suit >= 0 {
+			return NewCard(Face(face), Suit(suit)), nil
+		}
+	}
+	return 0, fmt.Errorf("Invalid card %s", s)
+}
+
+// ParseCards reads cards in short notation separated by spaces, e.g. Ah Kd
+func ParseCards(text string) ([]Card, error) {
+	var cards []Card
+	for _, s := range strings.Fields(text) {
+		c, err := ParseCard(s)
+		if err != nil {
+			return nil, err
+		}
+		cards = append(cards, c)
+	}
+	return cards, nil
+}
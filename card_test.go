@@ -60,3 +60,15 @@ func TestCardIsLessThanWithSuit(t *testing.T) {
 	result := DefaultCompare(card1, card2).IsLessThan()
 	assert.Equal(t, true, result, "These should be equal")
 }
+
+func TestParseCards(t *testing.T) {
+	cards, err := ParseCards(" Ah Tc  2s Kd")
+	assert.Nil(t, err)
+	assert.Equal(t, []Card{NewCard(ACE, HEART), NewCard(TEN, CLUB), NewCard(TWO, SPADE), NewCard(KING, DIAMOND)}, cards)
+	for _, text := range []string{"Ah 1c", "Ahs", "Ax", "10h"} {
+		_, err := ParseCards(text)
+		assert.NotNil(t, err, text)
+	}
+	_, err = ParseCard("Ax")
+	assert.Equal(t, "Invalid card Ax", err.Error())
+}
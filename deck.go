@@ -12,6 +12,7 @@ import (
 type Deck struct {
 	Cards         []Card
 	NumberOfDecks int
+	onDeal        []func(Card)
 }
 
 // Options is the struct used to describe now a Deck should be created
@@ -22,6 +23,7 @@ type Options struct {
 	Suits     []Suit
 	Decks     int
 	Signature string
+	seed      *string // shuffle deterministically from this seed, set by FromSeed
 }
 
 // New creates a new deck based on Options
@@ -49,9 +51,13 @@ func New(options ...func(*Options)) (*Deck, error) {
 			}
 		}
 	}
-	deck := Deck{cards, opt.Decks}
+	deck := Deck{Cards: cards, NumberOfDecks: opt.Decks}
 	if opt.Shuffled {
-		deck.Shuffle()
+		if opt.seed != nil {
+			deck.shuffleSeeded(*opt.seed)
+		} else {
+			deck.Shuffle()
+		}
 	}
 	return &deck, nil
 }
@@ -131,10 +137,19 @@ func (d *Deck) Deal(cards int, hands ...*Deck) {
 			card := d.Cards[0]
 			d.Cards = d.Cards[1:]
 			hand.Cards = append(hand.Cards, card)
+			for _, f := range d.onDeal {
+				f(card)
+			}
 		}
 	}
 }
 
+// OnDeal registers a function that is called with every card Deal takes from the deck, in the order they are dealt.
+// Use it to follow a shoe, for example to keep a count.
+func (d *Deck) OnDeal(f func(Card)) {
+	d.onDeal = append(d.onDeal, f)
+}
+
 // Shuffle uses Knuth shuffle algo to randomize the deck in O(n) time
 // sourced from https://gist.github.com/quux00/8258425
 func (d *Deck) Shuffle() {
@@ -189,8 +204,8 @@ func convertSignature(sig string, cards *[]Card) {
 // CompareResult is the custom type returned when comparing cards
 type CompareResult int
 
-// DefaultCompare is the default comparison function
-// Currently not used in any games.
+// DefaultCompare is the default comparison function. It orders cards by face, aces low,
+// then by suit, clubs lowest and spades highest. Stud uses it to break ties for the bring-in.
 func DefaultCompare(i, j Card) CompareResult {
 	if i.Face() > j.Face() {
 		return 1
@@ -1,6 +1,9 @@
 package deck
 
-import "fmt"
+import (
+	"fmt"
+	"math/big"
+)
 
 func ExampleDebugf() {
 	Debugf(true, "This will print")
@@ -97,3 +100,28 @@ func ExampleDeck_Deal() {
 	// Player2 Card Count: 5
 	// Deck Card Count: 42
 }
+
+func ExampleFromRank() {
+	deck, _ := FromRank(big.NewInt(1), Faces(ACE))
+
+	fmt.Printf("Rank: %s\n", deck.Rank())
+	fmt.Printf("%s", deck)
+	//Output:
+	// Rank: 1
+	// A♣
+	// A♦
+	// A♠
+	// A♥
+}
+
+func ExampleFromSeed() {
+	deck, _ := New(FromSeed("8f3a"), Faces(ACE, TEN, JACK, QUEEN, KING), Suits(SPADE))
+
+	fmt.Printf("%s", deck)
+	//Output:
+	// A♠
+	// J♠
+	// Q♠
+	// K♠
+	// T♠
+}
@@ -159,3 +159,15 @@ func TestEmptyShoe(t *testing.T) {
 	result := shoe.NumberOfDecks
 	assert.Equal(t, 0, result, "These should be equal")
 }
+
+func TestOnDeal(t *testing.T) {
+	deck, _ := New(Unshuffled, Suits(SPADE))
+	var dealt []Card
+	deck.OnDeal(func(c Card) { dealt = append(dealt, c) })
+	hand1, _ := New(Empty)
+	hand2, _ := New(Empty)
+	deck.Deal(2, hand1, hand2)
+	assert.Equal(t, []Card{NewCard(ACE, SPADE), NewCard(TWO, SPADE), NewCard(THREE, SPADE), NewCard(FOUR, SPADE)}, dealt)
+	hand1.Deal(1, hand2)
+	assert.Equal(t, 4, len(dealt), "Only the deck being followed is reported")
+}
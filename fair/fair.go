@@ -0,0 +1,152 @@
+// Package fair implements a commit-reveal protocol for provably fair shuffles.
+//
+// Before a hand the server picks a secret seed and publishes its commitment,
+// the SHA-256 hash of the seed. Every client then contributes a seed of its own.
+// The deck is shuffled with deck.FromSeed from all seeds together, so neither
+// the server nor any client can choose the order alone. After the hand the
+// server reveals its seed and anyone can run Verify to check the deck.
+package fair
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"adamclerk/deck"
+)
+
+// Errors returned by Verify
+var (
+	ErrCommitmentMismatch = errors.New("Server seed does not match the commitment")
+	ErrDeckMismatch       = errors.New("Deck does not match the seeds")
+)
+
+// Proof is everything a player needs to verify a hand after the server seed is revealed
+type Proof struct {
+	Commitment  string
+	ServerSeed  string
+	ClientSeeds []string
+	Signature   string // the GetSignature of the dealt deck
+}
+
+// Server runs the server side of the protocol for a single hand
+type Server struct {
+	seed        string
+	clientSeeds []string
+	signature   string
+	dealt       bool
+}
+
+// NewServer creates a server with a fresh random seed
+func NewServer() (*Server, error) {
+	seed, err := NewSeed()
+	if err != nil {
+		return nil, err
+	}
+	return NewServerWithSeed(seed), nil
+}
+
+// NewServerWithSeed creates a server with a specific seed.
+// This is mostly used for testing.
+func NewServerWithSeed(seed string) *Server {
+	return &Server{seed: seed}
+}
+
+// Commitment is published to the clients before they send their seeds
+func (s *Server) Commitment() string {
+	return Commit(s.seed)
+}
+
+// AddClientSeed records a client's seed. Seeds can't be added once the deck is dealt.
+func (s *Server) AddClientSeed(seed string) error {
+	if s.dealt {
+		return errors.New("Deck already dealt")
+	}
+	s.clientSeeds = append(s.clientSeeds, seed)
+	return nil
+}
+
+// Deck creates the deck for the hand from the server and client seeds.
+// The options must be the same ones given to Verify later; any shuffle
+// option is overridden by the seeded shuffle.
+func (s *Server) Deck(options ...func(*deck.Options)) (*deck.Deck, error) {
+	if s.dealt {
+		return nil, errors.New("Deck already dealt")
+	}
+	d, err := New(s.seed, s.clientSeeds, options...)
+	if err != nil {
+		return nil, err
+	}
+	s.signature = d.GetSignature()
+	s.dealt = true
+	return d, nil
+}
+
+// Reveal returns the proof for the hand, including the server seed.
+// It must only be called once the hand is over.
+func (s *Server) Reveal() (Proof, error) {
+	if !s.dealt {
+		return Proof{}, errors.New("Deck not dealt yet")
+	}
+	return Proof{
+		Commitment:  s.Commitment(),
+		ServerSeed:  s.seed,
+		ClientSeeds: append([]string{}, s.clientSeeds...),
+		Signature:   s.signature,
+	}, nil
+}
+
+// NewSeed returns 32 random bytes from crypto/rand, hex encoded
+func NewSeed() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Commit returns the hex encoded SHA-256 hash of the seed
+func Commit(seed string) string {
+	sum := sha256.Sum256([]byte(seed))
+	return hex.EncodeToString(sum[:])
+}
+
+// CombineSeeds merges the server seed and client seeds into the seed given to deck.FromSeed.
+// Every seed is length prefixed so that no two different seed lists combine to the same value.
+func CombineSeeds(serverSeed string, clientSeeds []string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d:%s", len(serverSeed), serverSeed)
+	for _, seed := range clientSeeds {
+		fmt.Fprintf(h, "%d:%s", len(seed), seed)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// New creates the deck for the given seeds
+func New(serverSeed string, clientSeeds []string, options ...func(*deck.Options)) (*deck.Deck, error) {
+	opts := append([]func(*deck.Options){}, options...)
+	opts = append(opts, shuffled, deck.FromSeed(CombineSeeds(serverSeed, clientSeeds)))
+	return deck.New(opts...)
+}
+
+// Verify checks that the server seed matches the commitment and that the seeds produce the deck in the proof.
+// options must be the deck options the server used.
+func Verify(proof Proof, options ...func(*deck.Options)) error {
+	if Commit(proof.ServerSeed) != proof.Commitment {
+		return ErrCommitmentMismatch
+	}
+	d, err := New(proof.ServerSeed, proof.ClientSeeds, options...)
+	if err != nil {
+		return err
+	}
+	if d.GetSignature() != proof.Signature {
+		return ErrDeckMismatch
+	}
+	return nil
+}
+
+func shuffled(o *deck.Options) {
+	o.Shuffled = true
+}
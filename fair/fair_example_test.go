@@ -0,0 +1,26 @@
+package fair
+
+import (
+	"fmt"
+
+	"adamclerk/deck"
+)
+
+func Example() {
+	server := NewServerWithSeed("a secret only the server knows")
+	fmt.Printf("Commitment: %s\n", server.Commitment())
+
+	server.AddClientSeed("player one")
+	server.AddClientSeed("player two")
+	d, _ := server.Deck()
+	hand, _ := deck.New(deck.Empty)
+	d.Deal(2, hand)
+	fmt.Printf("Hand: %s %s\n", hand.Cards[0], hand.Cards[1])
+
+	proof, _ := server.Reveal()
+	fmt.Printf("Verified: %v\n", Verify(proof) == nil)
+	//Output:
+	// Commitment: a31d3e0bbeef7bbee935a5cfae7b18e40a78b0231e22a17225efec73f849864f
+	// Hand: 9♦ 4♦
+	// Verified: true
+}
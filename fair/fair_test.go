@@ -0,0 +1,79 @@
+package fair
+
+import (
+	"testing"
+
+	"adamclerk/deck"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerRoundTrip(t *testing.T) {
+	server, err := NewServer()
+	assert.Nil(t, err)
+	commitment := server.Commitment()
+	assert.Nil(t, server.AddClientSeed("alice"))
+	assert.Nil(t, server.AddClientSeed("bob"))
+
+	d, err := server.Deck()
+	assert.Nil(t, err)
+	assert.Equal(t, 52, d.NumberOfCards())
+
+	proof, err := server.Reveal()
+	assert.Nil(t, err)
+	assert.Equal(t, commitment, proof.Commitment)
+	assert.Equal(t, d.GetSignature(), proof.Signature)
+	assert.Nil(t, Verify(proof))
+}
+
+func TestServerLocksSeedsOnceDealt(t *testing.T) {
+	server := NewServerWithSeed("server")
+	_, err := server.Reveal()
+	assert.Equal(t, "Deck not dealt yet", err.Error())
+	server.Deck()
+	assert.Equal(t, "Deck already dealt", server.AddClientSeed("late").Error())
+	_, err = server.Deck()
+	assert.Equal(t, "Deck already dealt", err.Error())
+
+	// an empty deck has an empty signature, and is dealt all the same
+	server = NewServerWithSeed("server")
+	d, err := server.Deck(deck.Empty)
+	assert.Nil(t, err)
+	assert.Equal(t, "", d.GetSignature())
+	assert.Equal(t, "Deck already dealt", server.AddClientSeed("late").Error())
+	_, err = server.Reveal()
+	assert.Nil(t, err)
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	server := NewServerWithSeed("server")
+	server.AddClientSeed("alice")
+	server.Deck(deck.Decks(2))
+	proof, _ := server.Reveal()
+	assert.Nil(t, Verify(proof, deck.Decks(2)))
+
+	wrongSeed := proof
+	wrongSeed.ServerSeed = "other"
+	assert.Equal(t, ErrCommitmentMismatch, Verify(wrongSeed, deck.Decks(2)))
+
+	wrongClient := proof
+	wrongClient.ClientSeeds = []string{"mallory"}
+	assert.Equal(t, ErrDeckMismatch, Verify(wrongClient, deck.Decks(2)))
+
+	assert.Equal(t, ErrDeckMismatch, Verify(proof))
+}
+
+func TestNewIgnoresUnshuffled(t *testing.T) {
+	d1, _ := New("server", []string{"alice"}, deck.Unshuffled)
+	d2, _ := New("server", []string{"alice"})
+	assert.Equal(t, d2.GetSignature(), d1.GetSignature())
+}
+
+func TestCombineSeedsIsUnambiguous(t *testing.T) {
+	assert.NotEqual(t, CombineSeeds("ab", []string{"c"}), CombineSeeds("a", []string{"bc"}))
+	assert.NotEqual(t, CombineSeeds("a", []string{"b", "c"}), CombineSeeds("a", []string{"bc"}))
+	assert.Equal(t, CombineSeeds("a", []string{"b"}), CombineSeeds("a", []string{"b"}))
+}
+
+func TestCommit(t *testing.T) {
+	assert.Equal(t, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", Commit(""))
+}
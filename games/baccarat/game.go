@@ -0,0 +1,341 @@
+// Package baccarat is a Punto Banco engine dealing from an eight deck shoe.
+package baccarat
+
+import (
+	"fmt"
+
+	"adamclerk/deck"
+)
+
+// Baccarat is a Punto Banco table: coup after coup, the player's and the banker's hands are dealt
+// by the tableau and bets on either, the tie and the pairs are settled.
+// Rules can be found here: https://bicyclecards.com/how-to-play/baccarat/
+type Baccarat struct {
+	debug       bool
+	deckOptions []func(*deck.Options)
+	shuffle     func(*deck.Deck)
+	rules       Rules
+	coups       int
+	shoe        *deck.Deck
+	burned      []deck.Card
+	scoreboard  *Scoreboard
+}
+
+// Options how to configure a game of baccarat
+type Options struct {
+	DeckOptions []func(*deck.Options)
+	Shuffle     func(*deck.Deck)
+	Rules       Rules
+	Debug       bool
+}
+
+// WithDeck allows the a game to be configured with a specific shoe.
+// A new shoe is created with these options, after the Decks rule, every time it is shuffled.
+func WithDeck(deckOptions ...func(*deck.Options)) func(*Options) {
+	return func(o *Options) {
+		o.DeckOptions = deckOptions
+	}
+}
+
+// WithShuffle shuffles the shoe with the given function instead of the deck's own shuffle
+func WithShuffle(shuffle func(*deck.Deck)) func(*Options) {
+	return func(o *Options) {
+		o.Shuffle = shuffle
+	}
+}
+
+// WithRules replaces every rule. Options after it change single rules.
+func WithRules(rules Rules) func(*Options) {
+	return func(o *Options) {
+		o.Rules = rules
+	}
+}
+
+// Decks sets the number of decks in the shoe
+func Decks(count int) func(*Options) {
+	return func(o *Options) {
+		o.Rules.Decks = count
+	}
+}
+
+// Commission sets the share taken from winning banker bets
+func Commission(rate float64) func(*Options) {
+	return func(o *Options) {
+		o.Rules.Commission = rate
+	}
+}
+
+// NoCommission pays winning banker bets even money, but half when the banker wins with 6
+func NoCommission(o *Options) {
+	o.Rules.NoCommission = true
+}
+
+// TiePays sets the tie payout, to 1
+func TiePays(odds float64) func(*Options) {
+	return func(o *Options) {
+		o.Rules.TiePays = odds
+	}
+}
+
+// PairPays sets the pair payout, to 1
+func PairPays(odds float64) func(*Options) {
+	return func(o *Options) {
+		o.Rules.PairPays = odds
+	}
+}
+
+// CutCard sets how many cards are left behind the cut card
+func CutCard(cards int) func(*Options) {
+	return func(o *Options) {
+		o.Rules.CutCard = cards
+	}
+}
+
+// NoBurn deals a new shoe without burning cards
+func NoBurn(o *Options) {
+	o.Rules.Burn = false
+}
+
+// Debug sets the debug param for the game
+func Debug(o *Options) {
+	o.Debug = true
+}
+
+// New function creates a new game of baccarat
+func New(options ...func(*Options)) (*Baccarat, error) {
+	opt := Options{DeckOptions: []func(*deck.Options){}, Rules: DefaultRules()}
+	for _, option := range options {
+		option(&opt)
+	}
+	if err := opt.Rules.validate(); err != nil {
+		return nil, err
+	}
+	return &Baccarat{
+		debug:       opt.Debug,
+		deckOptions: opt.DeckOptions,
+		shuffle:     opt.Shuffle,
+		rules:       opt.Rules,
+		scoreboard:  &Scoreboard{},
+	}, nil
+}
+
+// Rules returns the table rules
+func (g *Baccarat) Rules() Rules {
+	return g.rules
+}
+
+// Shoe returns the cards left to deal, nil before the first coup
+func (g *Baccarat) Shoe() *deck.Deck {
+	return g.shoe
+}
+
+// Burned returns the cards burned from the current shoe, the first one shown
+func (g *Baccarat) Burned() []deck.Card {
+	return g.burned
+}
+
+// Scoreboard returns the results of the current shoe
+func (g *Baccarat) Scoreboard() *Scoreboard {
+	return g.scoreboard
+}
+
+func (g *Baccarat) debugf(format string, a ...interface{}) {
+	deck.Debugf(g.debug, format, a...)
+}
+
+// newShoe shuffles a new shoe, burns cards from it and starts a new scoreboard
+func (g *Baccarat) newShoe() error {
+	options := append([]func(*deck.Options){deck.Decks(g.rules.Decks)}, g.deckOptions...)
+	if g.shuffle != nil {
+		options = append(options, deck.Unshuffled)
+	}
+	shoe, err := deck.New(options...)
+	if err != nil {
+		return err
+	}
+	if g.shuffle != nil {
+		g.shuffle(shoe)
+	}
+	g.shoe, g.burned, g.scoreboard = shoe, nil, &Scoreboard{}
+	g.debugf("Shoe shuffled, %d cards\n", len(shoe.Cards))
+	if g.rules.Burn && len(shoe.Cards) > 0 {
+		burn := Value(shoe.Cards[0])
+		if burn == 0 {
+			burn = 10
+		}
+		if burn+1 > len(shoe.Cards) {
+			burn = len(shoe.Cards) - 1
+		}
+		g.burned = append(g.burned, shoe.Cards[:burn+1]...)
+		shoe.Cards = shoe.Cards[burn+1:]
+		g.debugf("Burned %v\n", g.burned)
+	}
+	return nil
+}
+
+// Outcome is who won a coup
+type Outcome int
+
+// Constants for Outcome
+const (
+	PlayerWins Outcome = iota
+	BankerWins
+	Tie
+)
+
+var outcomes = []string{"Player", "Banker", "Tie"}
+
+func (o Outcome) String() string {
+	if o < 0 || int(o) >= len(outcomes) {
+		return fmt.Sprintf("Outcome(%d)", int(o))
+	}
+	return outcomes[o]
+}
+
+// Coup is one deal: the player's and the banker's hands and who won
+type Coup struct {
+	Number      int // counted from the start of the game
+	Shuffled    bool
+	Player      []deck.Card
+	Banker      []deck.Card
+	PlayerTotal int
+	BankerTotal int
+	Outcome     Outcome
+	Natural     bool // a hand was a natural 8 or 9
+	PlayerPair  bool // the player's first two cards have the same face
+	BankerPair  bool
+	rules       Rules
+}
+
+// Deal deals the next coup. A new shoe is shuffled when the cut card has come out.
+func (g *Baccarat) Deal() (*Coup, error) {
+	shuffled := false
+	if g.shoe == nil || len(g.shoe.Cards) <= g.rules.CutCard || len(g.shoe.Cards) < 6 {
+		if err := g.newShoe(); err != nil {
+			return nil, err
+		}
+		if len(g.shoe.Cards) < 6 {
+			return nil, fmt.Errorf("A coup needs 6 cards, the shoe has %d", len(g.shoe.Cards))
+		}
+		shuffled = true
+	}
+	g.coups++
+	c := &Coup{Number: g.coups, Shuffled: shuffled, rules: g.rules}
+	player, _ := deck.New(deck.Empty)
+	banker, _ := deck.New(deck.Empty)
+	// player, banker, player, banker
+	g.shoe.Deal(2, player, banker)
+	p, b := Total(player.Cards), Total(banker.Cards)
+	drew, third := false, 0
+	if PlayerDraws(p, b) {
+		g.shoe.Deal(1, player)
+		drew, third = true, Value(player.Cards[2])
+	}
+	if BankerDraws(b, p, drew, third) {
+		g.shoe.Deal(1, banker)
+	}
+
+	c.Player, c.Banker = player.Cards, banker.Cards
+	c.PlayerTotal, c.BankerTotal = Total(c.Player), Total(c.Banker)
+	c.Natural = IsNatural(p) || IsNatural(b)
+	c.PlayerPair = c.Player[0].Face() == c.Player[1].Face()
+	c.BankerPair = c.Banker[0].Face() == c.Banker[1].Face()
+	switch {
+	case c.PlayerTotal > c.BankerTotal:
+		c.Outcome = PlayerWins
+	case c.BankerTotal > c.PlayerTotal:
+		c.Outcome = BankerWins
+	default:
+		c.Outcome = Tie
+	}
+	g.scoreboard.Add(c)
+	g.debugf("Coup %d: Player %v %d, Banker %v %d, %s\n", c.Number, c.Player, c.PlayerTotal, c.Banker, c.BankerTotal, c.Outcome)
+	return c, nil
+}
+
+// Spot is where a bet is placed
+type Spot int
+
+// Constants for Spot
+const (
+	OnPlayer Spot = iota
+	OnBanker
+	OnTie
+	OnPlayerPair
+	OnBankerPair
+)
+
+var spots = []string{"Player", "Banker", "Tie", "Player Pair", "Banker Pair"}
+
+func (s Spot) String() string {
+	if s < 0 || int(s) >= len(spots) {
+		return fmt.Sprintf("Spot(%d)", int(s))
+	}
+	return spots[s]
+}
+
+// Bet is a wager on a spot
+type Bet struct {
+	Spot   Spot
+	Amount float64
+}
+
+// Net returns what the bet won, or lost, on the coup. Player and banker bets push on a tie.
+func (c *Coup) Net(bet Bet) float64 {
+	switch bet.Spot {
+	case OnPlayer, OnBanker:
+		if c.Outcome == Tie {
+			return 0
+		}
+		if bet.Spot == OnPlayer {
+			if c.Outcome == PlayerWins {
+				return bet.Amount
+			}
+			return -bet.Amount
+		}
+		if c.Outcome != BankerWins {
+			return -bet.Amount
+		}
+		if c.rules.NoCommission {
+			if c.BankerTotal == 6 {
+				return bet.Amount / 2
+			}
+			return bet.Amount
+		}
+		return bet.Amount * (1 - c.rules.Commission)
+	case OnTie:
+		if c.Outcome == Tie {
+			return bet.Amount * c.rules.TiePays
+		}
+	case OnPlayerPair:
+		if c.PlayerPair {
+			return bet.Amount * c.rules.PairPays
+		}
+	case OnBankerPair:
+		if c.BankerPair {
+			return bet.Amount * c.rules.PairPays
+		}
+	}
+	return -bet.Amount
+}
+
+// PlayCoup checks the bets, deals a coup and returns what each bet won or lost
+func (g *Baccarat) PlayCoup(bets ...Bet) (*Coup, []float64, error) {
+	for _, bet := range bets {
+		if bet.Spot < OnPlayer || bet.Spot > OnBankerPair {
+			return nil, nil, fmt.Errorf("Unknown spot %s", bet.Spot)
+		}
+		if bet.Amount <= 0 {
+			return nil, nil, fmt.Errorf("Invalid bet %v on %s", bet.Amount, bet.Spot)
+		}
+	}
+	c, err := g.Deal()
+	if err != nil {
+		return nil, nil, err
+	}
+	nets := make([]float64, len(bets))
+	for i, bet := range bets {
+		nets[i] = c.Net(bet)
+	}
+	return c, nets, nil
+}
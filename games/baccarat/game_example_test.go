@@ -0,0 +1,60 @@
+package baccarat
+
+import (
+	"fmt"
+	"strings"
+
+	"adamclerk/deck"
+)
+
+// This example deals a few coups from an unshuffled shoe, betting on the banker and the tie
+func Example() {
+	game, err := New(WithDeck(deck.Unshuffled), NoBurn)
+	if err != nil {
+		panic(err)
+	}
+	for i := 0; i < 5; i++ {
+		coup, nets, err := game.PlayCoup(Bet{OnBanker, 20}, Bet{OnTie, 5})
+		if err != nil {
+			panic(err)
+		}
+		fmt.Printf("Player %v %d, Banker %v %d: %s, %v\n", coup.Player, coup.PlayerTotal, coup.Banker, coup.BankerTotal, coup.Outcome, nets)
+	}
+	// Output:
+	// Player [A♣ 3♣ 5♣] 9, Banker [2♣ 4♣] 6: Player, [-20 -5]
+	// Player [6♣ 8♣ T♣] 4, Banker [7♣ 9♣] 6: Banker, [19 -5]
+	// Player [J♣ K♣ 2♦] 2, Banker [Q♣ A♦ 3♦] 4: Banker, [19 -5]
+	// Player [4♦ 6♦ 8♦] 8, Banker [5♦ 7♦ 9♦] 1: Player, [-20 -5]
+	// Player [T♦ Q♦ A♥] 1, Banker [J♦ K♦ 2♥] 2: Banker, [19 -5]
+}
+
+// This example draws the big road of a shoe shuffled from a seed: a column for each streak,
+// lower case when ties followed
+func ExampleScoreboard_BigRoad() {
+	game, _ := New(WithDeck(deck.FromSeed("baccarat")))
+	for i := 0; i < 40; i++ {
+		game.Deal()
+	}
+	fmt.Printf("%+v\n", game.Scoreboard().Tally())
+	for row := 0; row < Rows; row++ {
+		for _, col := range game.Scoreboard().BigRoad() {
+			switch m := col[row]; {
+			case m == nil:
+				fmt.Print(".")
+			case m.Ties > 0:
+				fmt.Print(strings.ToLower(m.Outcome.String()[:1]))
+			default:
+				fmt.Print(m.Outcome.String()[:1])
+			}
+		}
+		fmt.Println()
+	}
+	// Output:
+	// {Player:18 Banker:16 Tie:6 PlayerPairs:2 BankerPairs:4 Naturals:15}
+	// PBPBpBPBPBPBPBPb
+	// P......B.bPBPbPB
+	// P........bPBp...
+	// ..........PBP...
+	// ..........P.....
+	// ................
+}
@@ -0,0 +1,263 @@
+package baccarat
+
+import (
+	"testing"
+
+	"adamclerk/deck"
+	"adamclerk/deck/internal/decktest"
+	"github.com/stretchr/testify/assert"
+)
+
+// stacked returns options dealing exactly the given cards, in order, without a burn: player, banker,
+// player, banker, then the third cards. The shoe starts again from the top once it runs short.
+func stacked(cards ...string) []func(*Options) {
+	return []func(*Options){WithDeck(deck.Unshuffled, deck.WithCards(decktest.Cards(cards...)...)), NoBurn, CutCard(0)}
+}
+
+func TestNew(t *testing.T) {
+	g, err := New()
+	if assert.Nil(t, err) {
+		assert.Equal(t, DefaultRules(), g.Rules())
+		assert.Nil(t, g.Shoe())
+	}
+
+	tests := []struct {
+		option func(*Options)
+		err    string
+	}{
+		{Decks(0), "At least one deck is needed"},
+		{Commission(-0.05), "Invalid commission"},
+		{Commission(1), "Invalid commission"},
+		{TiePays(0), "Invalid payout"},
+		{PairPays(-1), "Invalid payout"},
+		{CutCard(-1), "Invalid cut card"},
+		{CutCard(52 * 8), "Invalid cut card"},
+	}
+	for _, test := range tests {
+		_, err := New(test.option)
+		if assert.NotNil(t, err, test.err) {
+			assert.Equal(t, test.err, err.Error())
+		}
+	}
+}
+
+func TestValue(t *testing.T) {
+	assert.Equal(t, 1, Value(deck.NewCard(deck.ACE, deck.SPADE)))
+	assert.Equal(t, 9, Value(deck.NewCard(deck.NINE, deck.HEART)))
+	assert.Equal(t, 0, Value(deck.NewCard(deck.TEN, deck.CLUB)))
+	assert.Equal(t, 0, Value(deck.NewCard(deck.KING, deck.DIAMOND)))
+	assert.Equal(t, 5, Total(decktest.Cards("7c", "8d")))
+	assert.Equal(t, 0, Total(decktest.Cards("Tc", "Kd", "Qh")))
+	assert.Equal(t, 9, Total(decktest.Cards("9c", "Jd")))
+}
+
+func TestTableau(t *testing.T) {
+	// the banker's rows, 0 to 7, by the player's third card: 0 to 9, then - when the player stood.
+	// D draws, S stands.
+	tableau := []string{
+		"DDDDDDDDDD D",
+		"DDDDDDDDDD D",
+		"DDDDDDDDDD D",
+		"DDDDDDDDSD D",
+		"SSDDDDDDSS D",
+		"SSSSDDDDSS D",
+		"SSSSSSDDSS S",
+		"SSSSSSSSSS S",
+	}
+	for banker, row := range tableau {
+		for third := 0; third < 10; third++ {
+			assert.Equal(t, row[third] == 'D', BankerDraws(banker, 3, true, third), "banker %d, third card %d", banker, third)
+		}
+		assert.Equal(t, row[11] == 'D', BankerDraws(banker, 6, false, 0), "banker %d, player stood", banker)
+	}
+
+	for player := 0; player < 10; player++ {
+		assert.Equal(t, player <= 5, PlayerDraws(player, 3), "player %d", player)
+		assert.False(t, PlayerDraws(player, 8))
+		assert.False(t, PlayerDraws(player, 9))
+	}
+	// naturals stop the banker too
+	assert.False(t, BankerDraws(0, 8, false, 0))
+	assert.False(t, BankerDraws(9, 0, false, 0))
+}
+
+func TestDeal(t *testing.T) {
+	tests := []struct {
+		cards   []string
+		player  int
+		banker  int
+		drawn   [2]int
+		outcome Outcome
+		natural bool
+		pairs   [2]bool
+	}{
+		// player natural 8
+		{[]string{"3c", "4d", "5h", "2s", "9c", "9d"}, 8, 6, [2]int{2, 2}, PlayerWins, true, [2]bool{}},
+		// banker natural 9 stops a player's 5
+		{[]string{"Tc", "4d", "5h", "5s", "9c", "9d"}, 5, 9, [2]int{2, 2}, BankerWins, true, [2]bool{}},
+		// player stands on 7, banker draws on 5 like the player
+		{[]string{"3c", "Td", "4h", "5s", "2c", "9d"}, 7, 7, [2]int{2, 3}, Tie, false, [2]bool{}},
+		// player draws, banker 3 stands on a third card 8
+		{[]string{"2c", "Jd", "3h", "3s", "8c", "9d"}, 3, 3, [2]int{3, 2}, Tie, false, [2]bool{}},
+		// player draws, banker 6 draws on a third card 6
+		{[]string{"Ac", "3d", "Ah", "3s", "6c", "Kd"}, 8, 6, [2]int{3, 3}, PlayerWins, false, [2]bool{true, true}},
+		// player draws, banker 5 stands on a third card 3
+		{[]string{"Kc", "2d", "Jh", "3s", "3c", "9d"}, 3, 5, [2]int{3, 2}, BankerWins, false, [2]bool{false, false}},
+	}
+	for i, test := range tests {
+		g, _ := New(stacked(test.cards...)...)
+		c, err := g.Deal()
+		if !assert.Nil(t, err, i) {
+			continue
+		}
+		assert.Equal(t, test.player, c.PlayerTotal, i)
+		assert.Equal(t, test.banker, c.BankerTotal, i)
+		assert.Equal(t, test.drawn, [2]int{len(c.Player), len(c.Banker)}, i)
+		assert.Equal(t, test.outcome, c.Outcome, i)
+		assert.Equal(t, test.natural, c.Natural, i)
+		assert.Equal(t, test.pairs, [2]bool{c.PlayerPair, c.BankerPair}, i)
+		assert.Equal(t, 1, c.Number, i)
+		assert.True(t, c.Shuffled, i)
+	}
+}
+
+func TestNet(t *testing.T) {
+	bets := []Bet{{OnPlayer, 10}, {OnBanker, 10}, {OnTie, 10}, {OnPlayerPair, 10}, {OnBankerPair, 10}}
+	tests := []struct {
+		cards   []string
+		options []func(*Options)
+		nets    []float64
+	}{
+		// player wins, with a player pair
+		{[]string{"4c", "3d", "4h", "2s", "9c", "9d"}, nil, []float64{10, -10, -10, 110, -10}},
+		// banker wins, commission taken
+		{[]string{"Tc", "4d", "5h", "5s", "9c", "9d"}, nil, []float64{-10, 9.5, -10, -10, -10}},
+		{[]string{"Tc", "4d", "5h", "5s", "9c", "9d"}, []func(*Options){Commission(0.04)}, []float64{-10, 9.6, -10, -10, -10}},
+		// tie pushes the player and the banker
+		{[]string{"3c", "Td", "4h", "5s", "2c", "9d"}, nil, []float64{0, 0, 80, -10, -10}},
+		{[]string{"3c", "Td", "4h", "5s", "2c", "9d"}, []func(*Options){TiePays(9)}, []float64{0, 0, 90, -10, -10}},
+		// no commission: a banker win pays even money, half on a 6
+		{[]string{"Tc", "4d", "5h", "5s", "9c", "9d"}, []func(*Options){NoCommission}, []float64{-10, 10, -10, -10, -10}},
+		{[]string{"Tc", "2d", "Jh", "4s", "Qc", "9d"}, []func(*Options){NoCommission}, []float64{-10, 5, -10, -10, -10}},
+		{[]string{"Tc", "2d", "Jh", "4s", "Qc", "9d"}, nil, []float64{-10, 9.5, -10, -10, -10}},
+		// both pairs
+		{[]string{"Ac", "3d", "Ah", "3s", "6c", "Kd"}, []func(*Options){PairPays(5)}, []float64{10, -10, -10, 50, 50}},
+	}
+	for i, test := range tests {
+		g, _ := New(append(stacked(test.cards...), test.options...)...)
+		_, nets, err := g.PlayCoup(bets...)
+		if assert.Nil(t, err, i) {
+			assert.InDeltaSlice(t, test.nets, nets, 1e-9, i)
+		}
+	}
+
+	g, _ := New()
+	_, _, err := g.PlayCoup(Bet{OnBanker, 0})
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "Invalid bet 0 on Banker", err.Error())
+	}
+	_, _, err = g.PlayCoup(Bet{Spot(7), 10})
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "Unknown spot Spot(7)", err.Error())
+	}
+	assert.Nil(t, g.Shoe())
+}
+
+func TestShoe(t *testing.T) {
+	// an unshuffled shoe starts with the ace of clubs: it burns one more card
+	g, _ := New(WithDeck(deck.Unshuffled))
+	c, err := g.Deal()
+	if assert.Nil(t, err) {
+		assert.True(t, c.Shuffled)
+		assert.Equal(t, decktest.Cards("Ac", "2c"), g.Burned())
+		assert.Equal(t, decktest.Cards("3c", "5c"), c.Player[:2])
+		assert.Equal(t, decktest.Cards("4c", "6c"), c.Banker[:2])
+	}
+	g, _ = New(WithDeck(deck.Unshuffled, deck.WithCards(decktest.Cards("Kc", "2c", "3c", "4c", "5c", "6c", "7c", "8c", "9c", "Tc", "Jc", "Qc", "Ac", "Kd", "Qd", "2d", "3d")...)), CutCard(0))
+	c, err = g.Deal()
+	if assert.Nil(t, err) {
+		assert.Len(t, g.Burned(), 11)
+		assert.Equal(t, decktest.Cards("Qc", "Kd", "2d"), c.Player)
+		assert.Equal(t, decktest.Cards("Ac", "Qd", "3d"), c.Banker)
+	}
+
+	// the shoe is shuffled once the cut card comes out, and the scoreboard starts again
+	g, _ = New(Decks(1), CutCard(20))
+	shuffles, coups := 0, 0
+	for i := 0; i < 100; i++ {
+		c, err := g.Deal()
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.Equal(t, i+1, c.Number)
+		coups++
+		if c.Shuffled {
+			shuffles++
+			coups = 1
+		}
+		assert.Len(t, g.Scoreboard().Beads(), coups)
+		assert.True(t, g.Shoe().NumberOfCards() >= 14)
+	}
+	assert.True(t, shuffles > 5)
+}
+
+// TestOdds works out the exact chances of an eight deck shoe, dealing every coup from the top
+func TestOdds(t *testing.T) {
+	var counts [10]float64
+	for v := range counts {
+		counts[v] = 4 * 8
+	}
+	counts[0] = 16 * 8
+	left := 52.0 * 8
+	draw := func(p float64, f func(v int, p float64)) {
+		for v := range counts {
+			if counts[v] == 0 {
+				continue
+			}
+			q := p * counts[v] / left
+			counts[v]--
+			left--
+			f(v, q)
+			counts[v]++
+			left++
+		}
+	}
+	var odds [3]float64
+	draw(1, func(p1 int, p float64) {
+		draw(p, func(b1 int, p float64) {
+			draw(p, func(p2 int, p float64) {
+				draw(p, func(b2 int, p float64) {
+					player, banker := (p1+p2)%10, (b1+b2)%10
+					settle := func(player, banker int, p float64) {
+						switch {
+						case player > banker:
+							odds[PlayerWins] += p
+						case banker > player:
+							odds[BankerWins] += p
+						default:
+							odds[Tie] += p
+						}
+					}
+					bank := func(player int, drew bool, third int, p float64) {
+						if BankerDraws(banker, player, drew, third) {
+							draw(p, func(b3 int, p float64) { settle(player, (banker+b3)%10, p) })
+						} else {
+							settle(player, banker, p)
+						}
+					}
+					if PlayerDraws(player, banker) {
+						draw(p, func(p3 int, p float64) { bank((player+p3)%10, true, p3, p) })
+					} else {
+						bank(player, false, 0, p)
+					}
+				})
+			})
+		})
+	})
+	assert.InDelta(t, 0.446247, odds[PlayerWins], 1e-6)
+	assert.InDelta(t, 0.458597, odds[BankerWins], 1e-6)
+	assert.InDelta(t, 0.095156, odds[Tie], 1e-6)
+	// the house edges: 1.06% on the banker, 1.24% on the player
+	assert.InDelta(t, 0.0106, -(0.95*odds[BankerWins] - odds[PlayerWins]), 1e-4)
+	assert.InDelta(t, 0.0124, -(odds[PlayerWins] - odds[BankerWins]), 1e-4)
+}
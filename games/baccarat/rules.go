@@ -0,0 +1,102 @@
+package baccarat
+
+import (
+	"errors"
+
+	"adamclerk/deck"
+)
+
+// Rules are the house rules of a Punto Banco table
+type Rules struct {
+	Decks        int
+	Commission   float64 // taken from a winning banker bet, 0.05 by default
+	NoCommission bool    // no commission, but a banker win on 6 pays half
+	TiePays      float64 // to 1, 8 by default
+	PairPays     float64 // to 1, 11 by default
+	CutCard      int     // the shoe is shuffled once fewer cards are left, 14 by default
+	Burn         bool    // a new shoe burns as many cards as the first card shows
+}
+
+// DefaultRules are the usual Punto Banco rules: eight decks, 5% commission, tie pays 8 to 1, pairs 11 to 1
+func DefaultRules() Rules {
+	return Rules{
+		Decks:      8,
+		Commission: 0.05,
+		TiePays:    8,
+		PairPays:   11,
+		CutCard:    14,
+		Burn:       true,
+	}
+}
+
+func (r Rules) validate() error {
+	if r.Decks < 1 {
+		return errors.New("At least one deck is needed")
+	}
+	if r.Commission < 0 || r.Commission >= 1 {
+		return errors.New("Invalid commission")
+	}
+	if r.TiePays <= 0 || r.PairPays <= 0 {
+		return errors.New("Invalid payout")
+	}
+	if r.CutCard < 0 || r.CutCard > 52*r.Decks-6 {
+		return errors.New("Invalid cut card")
+	}
+	return nil
+}
+
+// Value returns the card's baccarat value: aces count one, tens and pictures nothing
+func Value(c deck.Card) int {
+	if f := c.Face(); f < int(deck.TEN) {
+		return f + 1
+	}
+	return 0
+}
+
+// Total returns the hand's total, the last digit of the sum of its values
+func Total(cards []deck.Card) int {
+	total := 0
+	for _, c := range cards {
+		total += Value(c)
+	}
+	return total % 10
+}
+
+// IsNatural tells you if a two card total of 8 or 9 ends the coup
+func IsNatural(total int) bool {
+	return total >= 8
+}
+
+// PlayerDraws tells you if the player takes a third card: on 0 to 5, unless either hand is a natural
+func PlayerDraws(player, banker int) bool {
+	return !IsNatural(player) && !IsNatural(banker) && player <= 5
+}
+
+// BankerDraws tells you if the banker takes a third card, from the banker's two card total and the player's total.
+// When the player stood the banker draws on 0 to 5, like the player. Otherwise it follows the tableau,
+// by the value of the player's third card:
+//
+//	0-2: draws
+//	3:   draws unless the player's third card is an 8
+//	4:   draws on 2 to 7
+//	5:   draws on 4 to 7
+//	6:   draws on 6 or 7
+//	7:   stands
+func BankerDraws(banker, player int, playerDrew bool, playerThird int) bool {
+	if !playerDrew {
+		return !IsNatural(player) && !IsNatural(banker) && banker <= 5
+	}
+	switch banker {
+	case 0, 1, 2:
+		return true
+	case 3:
+		return playerThird != 8
+	case 4:
+		return playerThird >= 2 && playerThird <= 7
+	case 5:
+		return playerThird >= 4 && playerThird <= 7
+	case 6:
+		return playerThird == 6 || playerThird == 7
+	}
+	return false
+}
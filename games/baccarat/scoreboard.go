@@ -0,0 +1,143 @@
+package baccarat
+
+// Rows is the height of the scoreboard grids
+const Rows = 6
+
+// Bead is one coup on the scoreboard
+type Bead struct {
+	Coup       int // the coup's number
+	Outcome    Outcome
+	Total      int // the winning total, or the tied one
+	Natural    bool
+	PlayerPair bool
+	BankerPair bool
+}
+
+// RoadMark is a cell of the big road: a player or banker win, with the ties that followed it
+type RoadMark struct {
+	Coup       int
+	Outcome    Outcome
+	Ties       int
+	PlayerPair bool
+	BankerPair bool
+}
+
+// Tally counts the results of a shoe
+type Tally struct {
+	Player, Banker, Tie      int
+	PlayerPairs, BankerPairs int
+	Naturals                 int
+}
+
+// Scoreboard is the history of a shoe, the way a table's display shows it
+type Scoreboard struct {
+	beads []Bead
+}
+
+// Add puts a coup on the scoreboard
+func (s *Scoreboard) Add(c *Coup) {
+	total := c.BankerTotal
+	if c.Outcome == PlayerWins {
+		total = c.PlayerTotal
+	}
+	s.beads = append(s.beads, Bead{
+		Coup:       c.Number,
+		Outcome:    c.Outcome,
+		Total:      total,
+		Natural:    c.Natural,
+		PlayerPair: c.PlayerPair,
+		BankerPair: c.BankerPair,
+	})
+}
+
+// Beads returns the coups in the order they were dealt
+func (s *Scoreboard) Beads() []Bead {
+	return s.beads
+}
+
+// Tally counts the outcomes, pairs and naturals
+func (s *Scoreboard) Tally() Tally {
+	t := Tally{}
+	for _, b := range s.beads {
+		switch b.Outcome {
+		case PlayerWins:
+			t.Player++
+		case BankerWins:
+			t.Banker++
+		default:
+			t.Tie++
+		}
+		if b.PlayerPair {
+			t.PlayerPairs++
+		}
+		if b.BankerPair {
+			t.BankerPairs++
+		}
+		if b.Natural {
+			t.Naturals++
+		}
+	}
+	return t
+}
+
+// BeadPlate returns every coup, ties included, in columns of six filled from the top
+func (s *Scoreboard) BeadPlate() [][Rows]*Bead {
+	plate := make([][Rows]*Bead, (len(s.beads)+Rows-1)/Rows)
+	for i := range s.beads {
+		plate[i/Rows][i%Rows] = &s.beads[i]
+	}
+	return plate
+}
+
+// BigRoad returns the player and banker wins, a column for each streak. A tie is marked on the
+// win before it; ties at the start of the shoe go on the first win. A streak longer than the column
+// turns right along the bottom row, or under the tail of the streak before, into a dragon tail.
+func (s *Scoreboard) BigRoad() [][Rows]*RoadMark {
+	road := [][Rows]*RoadMark{}
+	at := func(col, row int) *RoadMark {
+		if col >= len(road) {
+			return nil
+		}
+		return road[col][row]
+	}
+	put := func(col, row int, m *RoadMark) {
+		for col >= len(road) {
+			road = append(road, [Rows]*RoadMark{})
+		}
+		road[col][row] = m
+	}
+
+	var last *RoadMark
+	leading := 0
+	start, col, row := -1, 0, 0
+	turned := false
+	for _, b := range s.beads {
+		if b.Outcome == Tie {
+			if last == nil {
+				leading++
+			} else {
+				last.Ties++
+			}
+			continue
+		}
+		m := &RoadMark{Coup: b.Coup, Outcome: b.Outcome, PlayerPair: b.PlayerPair, BankerPair: b.BankerPair}
+		switch {
+		case last == nil || last.Outcome != b.Outcome:
+			start++
+			col, row, turned = start, 0, false
+			for at(col, row) != nil {
+				col++
+			}
+		case !turned && row+1 < Rows && at(col, row+1) == nil:
+			row++
+		default:
+			col, turned = col+1, true
+		}
+		if last == nil {
+			m.Ties = leading
+		}
+		put(col, row, m)
+		last = m
+	}
+	return road
+}
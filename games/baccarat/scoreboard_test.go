@@ -0,0 +1,120 @@
+package baccarat
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// shoe puts coups on a scoreboard from a string of outcomes: P, B or T. Lower case adds a player pair.
+func shoe(outcomes string) *Scoreboard {
+	s := &Scoreboard{}
+	for i, o := range outcomes {
+		c := &Coup{Number: i + 1, PlayerTotal: 5, BankerTotal: 5, Outcome: Tie}
+		switch strings.ToUpper(string(o)) {
+		case "P":
+			c.PlayerTotal, c.Outcome = 8, PlayerWins
+			c.Natural = true
+		case "B":
+			c.BankerTotal, c.Outcome = 7, BankerWins
+		}
+		c.PlayerPair = o >= 'a'
+		s.Add(c)
+	}
+	return s
+}
+
+// road draws the big road, a line a row
+func road(s *Scoreboard) string {
+	grid := s.BigRoad()
+	rows := make([]string, Rows)
+	for r := range rows {
+		for _, col := range grid {
+			switch m := col[r]; {
+			case m == nil:
+				rows[r] += "."
+			case m.Ties > 0:
+				rows[r] += strings.ToLower(m.Outcome.String()[:1])
+			default:
+				rows[r] += m.Outcome.String()[:1]
+			}
+		}
+	}
+	return strings.Join(rows, "\n")
+}
+
+func TestBeadPlate(t *testing.T) {
+	s := shoe("BPpTBBTP")
+	plate := s.BeadPlate()
+	assert.Len(t, plate, 2)
+	assert.Equal(t, Bead{Coup: 1, Outcome: BankerWins, Total: 7}, *plate[0][0])
+	assert.Equal(t, Bead{Coup: 3, Outcome: PlayerWins, Total: 8, Natural: true, PlayerPair: true}, *plate[0][2])
+	assert.Equal(t, Bead{Coup: 4, Outcome: Tie, Total: 5}, *plate[0][3])
+	assert.Equal(t, 8, plate[1][1].Coup)
+	assert.Nil(t, plate[1][2])
+	assert.Equal(t, Tally{Player: 3, Banker: 3, Tie: 2, PlayerPairs: 1, Naturals: 3}, s.Tally())
+	assert.Len(t, (&Scoreboard{}).BeadPlate(), 0)
+}
+
+func TestBigRoad(t *testing.T) {
+	s := shoe("TBBPTTpBBBBBBBBP")
+	grid := s.BigRoad()
+	assert.Equal(t, 1, grid[0][0].Ties)
+	assert.Equal(t, 2, grid[0][0].Coup)
+	assert.Equal(t, 2, grid[1][0].Ties)
+	assert.Equal(t, 7, grid[1][1].Coup)
+	assert.True(t, grid[1][1].PlayerPair)
+	assert.Equal(t, strings.Join([]string{
+		"bpBP.",
+		"BPB..",
+		"..B..",
+		"..B..",
+		"..B..",
+		"..BBB",
+	}, "\n"), road(s))
+
+	// a streak turns under the tail of the one before
+	assert.Equal(t, strings.Join([]string{
+		"BP..",
+		"BP..",
+		"BP..",
+		"BP..",
+		"BPPP",
+		"BB..",
+	}, "\n"), road(shoe("BBBBBBBPPPPPPP")))
+
+	// a shoe of ties has no road yet
+	assert.Len(t, shoe("TT").BigRoad(), 0)
+	assert.Len(t, (&Scoreboard{}).BigRoad(), 0)
+}
+
+func TestScoreboardFollowsTheGame(t *testing.T) {
+	g, _ := New()
+	var coups []*Coup
+	for i := 0; i < 40; i++ {
+		c, err := g.Deal()
+		if !assert.Nil(t, err) {
+			return
+		}
+		coups = append(coups, c)
+	}
+	beads := g.Scoreboard().Beads()
+	assert.Len(t, beads, 40)
+	marks, ties := 0, 0
+	for _, col := range g.Scoreboard().BigRoad() {
+		for _, m := range col {
+			if m != nil {
+				marks++
+				ties += m.Ties
+			}
+		}
+	}
+	tally := g.Scoreboard().Tally()
+	assert.Equal(t, tally.Player+tally.Banker, marks)
+	assert.Equal(t, tally.Tie, ties)
+	for i, b := range beads {
+		assert.Equal(t, coups[i].Outcome, b.Outcome)
+		assert.Equal(t, coups[i].Number, b.Coup)
+	}
+}
@@ -0,0 +1,131 @@
+package blackjack
+
+import (
+	"fmt"
+
+	"adamclerk/deck"
+)
+
+// Action is what a player does with a hand
+type Action int
+
+// Constants for Action
+const (
+	Hit Action = iota
+	Stand
+	Double
+	Split
+	Surrender
+)
+
+func (a Action) String() string {
+	names := []string{"Hit", "Stand", "Double", "Split", "Surrender"}
+	if a < 0 || int(a) >= len(names) {
+		return fmt.Sprintf("Action(%d)", int(a))
+	}
+	return names[a]
+}
+
+// Phase is the decision a round is waiting for
+type Phase int
+
+// Constants for Phase
+const (
+	// InsuranceDecision is taken once per seat when the dealer shows an ace
+	InsuranceDecision Phase = iota
+	// SurrenderDecision is taken once per hand under early surrender, before the dealer peeks.
+	// Surrender gives the hand up, any other action keeps it.
+	SurrenderDecision
+	// PlayDecision is an action for the hand being played
+	PlayDecision
+	// RoundOver means every bet is settled
+	RoundOver
+)
+
+func (p Phase) String() string {
+	names := []string{"InsuranceDecision", "SurrenderDecision", "PlayDecision", "RoundOver"}
+	if p < 0 || int(p) >= len(names) {
+		return fmt.Sprintf("Phase(%d)", int(p))
+	}
+	return names[p]
+}
+
+// Decider makes a player's decisions. Bots and humans plug in by implementing it.
+type Decider interface {
+	Act(state State) Action
+	Insurance(state State) bool
+}
+
+type deciderFuncs struct {
+	act       func(state State) Action
+	insurance func(state State) bool
+}
+
+func (d deciderFuncs) Act(state State) Action {
+	return d.act(state)
+}
+
+func (d deciderFuncs) Insurance(state State) bool {
+	if d.insurance == nil {
+		return false
+	}
+	return d.insurance(state)
+}
+
+// DeciderFuncs adapts a pair of functions to the Decider interface. A nil insurance never insures.
+func DeciderFuncs(act func(state State) Action, insurance func(state State) bool) Decider {
+	return deciderFuncs{act: act, insurance: insurance}
+}
+
+// State is what a player sees when a decision is theirs
+type State struct {
+	Phase        Phase
+	Seat         int
+	Hand         int // the seat's hand being played, splits add hands
+	Hands        int // the seat's hands
+	Cards        []deck.Card
+	Total        int
+	Soft         bool
+	Upcard       deck.Card
+	Bet          float64 // the bet on the hand
+	Bankroll     float64 // the player's chips off the table
+	Split        bool    // the hand came from a split
+	CanHit       bool
+	CanDouble    bool
+	CanSplit     bool
+	CanSurrender bool
+	Rules        Rules
+	unseen       func() []deck.Card
+}
+
+// Unseen returns the cards the player hasn't seen: the shoe and the dealer's hole card
+func (s State) Unseen() []deck.Card {
+	if s.unseen == nil {
+		return nil
+	}
+	return s.unseen()
+}
+
+// Can tells you if an action is allowed. Stand always is.
+func (s State) Can(a Action) bool {
+	switch a {
+	case Hit:
+		return s.CanHit
+	case Stand:
+		return true
+	case Double:
+		return s.CanDouble
+	case Split:
+		return s.CanSplit
+	case Surrender:
+		return s.CanSurrender
+	}
+	return false
+}
+
+// ActionRecord is a decision as it happened
+type ActionRecord struct {
+	Seat   int
+	Hand   int
+	Action Action
+}
@@ -0,0 +1,203 @@
+package blackjack
+
+import (
+	"strings"
+)
+
+// The built in charts assume late surrender; the R plays fall back when it isn't offered,
+// as the Ph plays do when doubling after a split isn't.
+
+const singleDeckS17 = `"Single deck, dealer stands on soft 17",2,3,4,5,6,7,8,9,10,A
+5-7,H,H,H,H,H,H,H,H,H,H
+8,H,H,H,Dh,Dh,H,H,H,H,H
+9,Dh,Dh,Dh,Dh,Dh,H,H,H,H,H
+10,Dh,Dh,Dh,Dh,Dh,Dh,Dh,Dh,H,H
+11,Dh,Dh,Dh,Dh,Dh,Dh,Dh,Dh,Dh,Dh
+12,H,H,S,S,S,H,H,H,H,H
+13-15,S,S,S,S,S,H,H,H,H,H
+16,S,S,S,S,S,H,H,H,Rh,Rh
+17+,S,S,S,S,S,S,S,S,S,S
+A2,H,H,Dh,Dh,Dh,H,H,H,H,H
+A3,H,H,Dh,Dh,Dh,H,H,H,H,H
+A4,H,H,Dh,Dh,Dh,H,H,H,H,H
+A5,H,H,Dh,Dh,Dh,H,H,H,H,H
+A6,Dh,Dh,Dh,Dh,Dh,H,H,H,H,H
+A7,S,Ds,Ds,Ds,Ds,S,S,H,H,S
+A8,S,S,S,S,Ds,S,S,S,S,S
+A9,S,S,S,S,S,S,S,S,S,S
+22,Ph,P,P,P,P,P,-,-,-,-
+33,Ph,Ph,P,P,P,P,Ph,-,-,-
+44,-,-,Ph,Ph,Ph,-,-,-,-,-
+66,P,P,P,P,P,Ph,-,-,-,-
+77,P,P,P,P,P,P,Ph,-,Rs,-
+88,P,P,P,P,P,P,P,P,P,P
+99,P,P,P,P,P,-,P,P,-,-
+AA,P,P,P,P,P,P,P,P,P,P
+`
+
+const singleDeckH17 = `"Single deck, dealer hits soft 17",2,3,4,5,6,7,8,9,10,A
+5-7,H,H,H,H,H,H,H,H,H,H
+8,H,H,H,Dh,Dh,H,H,H,H,H
+9,Dh,Dh,Dh,Dh,Dh,H,H,H,H,H
+10,Dh,Dh,Dh,Dh,Dh,Dh,Dh,Dh,H,H
+11,Dh,Dh,Dh,Dh,Dh,Dh,Dh,Dh,Dh,Dh
+12,H,H,S,S,S,H,H,H,H,H
+13-14,S,S,S,S,S,H,H,H,H,H
+15,S,S,S,S,S,H,H,H,H,Rh
+16,S,S,S,S,S,H,H,H,Rh,Rh
+17,S,S,S,S,S,S,S,S,S,Rs
+18+,S,S,S,S,S,S,S,S,S,S
+A2,H,H,Dh,Dh,Dh,H,H,H,H,H
+A3,H,H,Dh,Dh,Dh,H,H,H,H,H
+A4,H,H,Dh,Dh,Dh,H,H,H,H,H
+A5,H,H,Dh,Dh,Dh,H,H,H,H,H
+A6,Dh,Dh,Dh,Dh,Dh,H,H,H,H,H
+A7,S,Ds,Ds,Ds,Ds,S,S,H,H,H
+A8,S,S,S,S,Ds,S,S,S,S,S
+A9,S,S,S,S,S,S,S,S,S,S
+22,Ph,P,P,P,P,P,-,-,-,-
+33,Ph,Ph,P,P,P,P,Ph,-,-,-
+44,-,-,Ph,Ph,Ph,-,-,-,-,-
+66,P,P,P,P,P,Ph,-,-,-,-
+77,P,P,P,P,P,P,Ph,-,Rs,-
+88,P,P,P,P,P,P,P,P,P,P
+99,P,P,P,P,P,-,P,P,-,P
+AA,P,P,P,P,P,P,P,P,P,P
+`
+
+const doubleDeckS17 = `"Double deck, dealer stands on soft 17",2,3,4,5,6,7,8,9,10,A
+5-8,H,H,H,H,H,H,H,H,H,H
+9,Dh,Dh,Dh,Dh,Dh,H,H,H,H,H
+10,Dh,Dh,Dh,Dh,Dh,Dh,Dh,Dh,H,H
+11,Dh,Dh,Dh,Dh,Dh,Dh,Dh,Dh,Dh,Dh
+12,H,H,S,S,S,H,H,H,H,H
+13-14,S,S,S,S,S,H,H,H,H,H
+15,S,S,S,S,S,H,H,H,Rh,H
+16,S,S,S,S,S,H,H,H,Rh,Rh
+17+,S,S,S,S,S,S,S,S,S,S
+A2,H,H,H,Dh,Dh,H,H,H,H,H
+A3,H,H,H,Dh,Dh,H,H,H,H,H
+A4,H,H,Dh,Dh,Dh,H,H,H,H,H
+A5,H,H,Dh,Dh,Dh,H,H,H,H,H
+A6,H,Dh,Dh,Dh,Dh,H,H,H,H,H
+A7,S,Ds,Ds,Ds,Ds,S,S,H,H,H
+A8,S,S,S,S,S,S,S,S,S,S
+A9,S,S,S,S,S,S,S,S,S,S
+22,Ph,P,P,P,P,P,-,-,-,-
+33,Ph,Ph,P,P,P,P,-,-,-,-
+44,-,-,-,Ph,Ph,-,-,-,-,-
+66,P,P,P,P,P,Ph,-,-,-,-
+77,P,P,P,P,P,P,Ph,-,-,-
+88,P,P,P,P,P,P,P,P,P,P
+99,P,P,P,P,P,-,P,P,-,-
+AA,P,P,P,P,P,P,P,P,P,P
+`
+
+const doubleDeckH17 = `"Double deck, dealer hits soft 17",2,3,4,5,6,7,8,9,10,A
+5-8,H,H,H,H,H,H,H,H,H,H
+9,Dh,Dh,Dh,Dh,Dh,H,H,H,H,H
+10,Dh,Dh,Dh,Dh,Dh,Dh,Dh,Dh,H,H
+11,Dh,Dh,Dh,Dh,Dh,Dh,Dh,Dh,Dh,Dh
+12,H,H,S,S,S,H,H,H,H,H
+13-14,S,S,S,S,S,H,H,H,H,H
+15,S,S,S,S,S,H,H,H,Rh,Rh
+16,S,S,S,S,S,H,H,H,Rh,Rh
+17,S,S,S,S,S,S,S,S,S,Rs
+18+,S,S,S,S,S,S,S,S,S,S
+A2,H,H,H,Dh,Dh,H,H,H,H,H
+A3,H,H,Dh,Dh,Dh,H,H,H,H,H
+A4,H,H,Dh,Dh,Dh,H,H,H,H,H
+A5,H,H,Dh,Dh,Dh,H,H,H,H,H
+A6,H,Dh,Dh,Dh,Dh,H,H,H,H,H
+A7,Ds,Ds,Ds,Ds,Ds,S,S,H,H,H
+A8,S,S,S,S,Ds,S,S,S,S,S
+A9,S,S,S,S,S,S,S,S,S,S
+22,Ph,P,P,P,P,P,-,-,-,-
+33,Ph,Ph,P,P,P,P,-,-,-,-
+44,-,-,-,Ph,Ph,-,-,-,-,-
+66,P,P,P,P,P,Ph,-,-,-,-
+77,P,P,P,P,P,P,Ph,-,-,-
+88,P,P,P,P,P,P,P,P,P,P
+99,P,P,P,P,P,-,P,P,-,-
+AA,P,P,P,P,P,P,P,P,P,P
+`
+
+const multiDeckS17 = `"Four to eight decks, dealer stands on soft 17",2,3,4,5,6,7,8,9,10,A
+5-8,H,H,H,H,H,H,H,H,H,H
+9,H,Dh,Dh,Dh,Dh,H,H,H,H,H
+10,Dh,Dh,Dh,Dh,Dh,Dh,Dh,Dh,H,H
+11,Dh,Dh,Dh,Dh,Dh,Dh,Dh,Dh,Dh,H
+12,H,H,S,S,S,H,H,H,H,H
+13-14,S,S,S,S,S,H,H,H,H,H
+15,S,S,S,S,S,H,H,H,Rh,H
+16,S,S,S,S,S,H,H,Rh,Rh,Rh
+17+,S,S,S,S,S,S,S,S,S,S
+A2,H,H,H,Dh,Dh,H,H,H,H,H
+A3,H,H,H,Dh,Dh,H,H,H,H,H
+A4,H,H,Dh,Dh,Dh,H,H,H,H,H
+A5,H,H,Dh,Dh,Dh,H,H,H,H,H
+A6,H,Dh,Dh,Dh,Dh,H,H,H,H,H
+A7,S,Ds,Ds,Ds,Ds,S,S,H,H,H
+A8,S,S,S,S,S,S,S,S,S,S
+A9,S,S,S,S,S,S,S,S,S,S
+22,Ph,Ph,P,P,P,P,-,-,-,-
+33,Ph,Ph,P,P,P,P,-,-,-,-
+44,-,-,-,Ph,Ph,-,-,-,-,-
+66,Ph,P,P,P,P,-,-,-,-,-
+77,P,P,P,P,P,P,-,-,-,-
+88,P,P,P,P,P,P,P,P,P,P
+99,P,P,P,P,P,-,P,P,-,-
+AA,P,P,P,P,P,P,P,P,P,P
+`
+
+const multiDeckH17 = `"Four to eight decks, dealer hits soft 17",2,3,4,5,6,7,8,9,10,A
+5-8,H,H,H,H,H,H,H,H,H,H
+9,H,Dh,Dh,Dh,Dh,H,H,H,H,H
+10,Dh,Dh,Dh,Dh,Dh,Dh,Dh,Dh,H,H
+11,Dh,Dh,Dh,Dh,Dh,Dh,Dh,Dh,Dh,Dh
+12,H,H,S,S,S,H,H,H,H,H
+13-14,S,S,S,S,S,H,H,H,H,H
+15,S,S,S,S,S,H,H,H,Rh,Rh
+16,S,S,S,S,S,H,H,Rh,Rh,Rh
+17,S,S,S,S,S,S,S,S,S,Rs
+18+,S,S,S,S,S,S,S,S,S,S
+A2,H,H,H,Dh,Dh,H,H,H,H,H
+A3,H,H,H,Dh,Dh,H,H,H,H,H
+A4,H,H,Dh,Dh,Dh,H,H,H,H,H
+A5,H,H,Dh,Dh,Dh,H,H,H,H,H
+A6,H,Dh,Dh,Dh,Dh,H,H,H,H,H
+A7,Ds,Ds,Ds,Ds,Ds,S,S,H,H,H
+A8,S,S,S,S,Ds,S,S,S,S,S
+A9,S,S,S,S,S,S,S,S,S,S
+22,Ph,Ph,P,P,P,P,-,-,-,-
+33,Ph,Ph,P,P,P,P,-,-,-,-
+44,-,-,-,Ph,Ph,-,-,-,-,-
+66,Ph,P,P,P,P,-,-,-,-,-
+77,P,P,P,P,P,P,-,-,-,-
+88,P,P,P,P,P,P,P,P,P,Rp
+99,P,P,P,P,P,-,P,P,-,-
+AA,P,P,P,P,P,P,P,P,P,P
+`
+
+// BasicStrategy returns the built in chart closest to the rules: for one deck (or none given), two decks
+// or more, with the dealer standing or hitting soft 17. There are no built in charts for
+// games without a hole card; DeriveChart works one out.
+func BasicStrategy(rules Rules) *Chart {
+	charts := [][2]string{{singleDeckS17, singleDeckH17}, {doubleDeckS17, doubleDeckH17}, {multiDeckS17, multiDeckH17}}
+	i := rules.Decks - 1
+	if i < 0 {
+		i = 0
+	}
+	if i > 2 {
+		i = 2
+	}
+	h17 := 0
+	if rules.HitSoft17 {
+		h17 = 1
+	}
+	chart, err := ReadChartCSV(strings.NewReader(charts[i][h17]))
+	if err != nil {
+		panic(err)
+	}
+	return chart
+}
@@ -0,0 +1,452 @@
+package blackjack
+
+import (
+	"adamclerk/deck"
+)
+
+// Composition counts cards by blackjack value: index 1 for aces up to 10 for tens and pictures
+type Composition [11]int
+
+// NewComposition counts the cards
+func NewComposition(cards []deck.Card) Composition {
+	var c Composition
+	for _, card := range cards {
+		c[CardValue(card)]++
+	}
+	return c
+}
+
+// Cards returns how many cards there are
+func (c Composition) Cards() int {
+	n := 0
+	for _, count := range c[1:] {
+		n += count
+	}
+	return n
+}
+
+// Without returns the composition after the cards are removed
+func (c Composition) Without(cards ...deck.Card) Composition {
+	for _, card := range cards {
+		c[CardValue(card)]--
+	}
+	return c
+}
+
+// Advise returns the action with the best expected value when the cards the player hasn't seen
+// are exactly unseen: the shoe and the dealer's hole card. See State.Unseen.
+// Actions are valued as ExpectedValues values them. On the surrender decision, before the dealer peeks,
+// it returns Surrender or Stand, which keeps the hand.
+func Advise(state State, unseen []deck.Card) Action {
+	c := newCalculator(state.Rules, CardValue(state.Upcard))
+	comp := NewComposition(unseen)
+	if state.Phase != SurrenderDecision {
+		return best(c.actions(state, comp))
+	}
+	// the hand is played, as it could be after the peek, only when the dealer doesn't have blackjack
+	total, _ := Value(state.Cards)
+	state.CanHit = true
+	state.CanDouble = state.Rules.Double.allows(total) && state.Bankroll >= state.Bet
+	state.CanSplit = CardValue(state.Cards[0]) == CardValue(state.Cards[1]) && state.Rules.SplitHands > 1 && state.Bankroll >= state.Bet
+	state.CanSurrender = false
+	evs := c.actions(state, comp)
+	blackjack := float64(comp[c.blackjackValue()]) / float64(comp.Cards())
+	if -0.5 > (1-blackjack)*evs[best(evs)]-blackjack {
+		return Surrender
+	}
+	return Stand
+}
+
+// CompositionDependent plays every hand with Advise, from the cards the player hasn't seen. It never insures.
+var CompositionDependent = DeciderFuncs(func(s State) Action {
+	return Advise(s, s.Unseen())
+}, nil)
+
+// best returns the action with the highest expected value, Stand on a tie
+func best(evs map[Action]float64) Action {
+	result := Stand
+	for _, a := range []Action{Hit, Double, Split, Surrender} {
+		if ev, ok := evs[a]; ok && ev > evs[result] {
+			result = a
+		}
+	}
+	return result
+}
+
+// DealerOdds are the chances of each way the dealer's hand can end: on 17 to 21, bust or with blackjack
+type DealerOdds [7]float64
+
+const (
+	dealerBust = 5
+	dealerBJ   = 6
+)
+
+// Total returns the chance the dealer ends on a total of 17 to 21, blackjack apart
+func (d DealerOdds) Total(total int) float64 {
+	if total < 17 || total > 21 {
+		return 0
+	}
+	return d[total-17]
+}
+
+// Bust returns the chance the dealer busts
+func (d DealerOdds) Bust() float64 {
+	return d[dealerBust]
+}
+
+// Blackjack returns the chance the dealer has blackjack
+func (d DealerOdds) Blackjack() float64 {
+	return d[dealerBJ]
+}
+
+// NoBlackjack returns the odds once the dealer has peeked and doesn't have blackjack
+func (d DealerOdds) NoBlackjack() DealerOdds {
+	rest := 1 - d[dealerBJ]
+	if rest == 0 {
+		return DealerOdds{}
+	}
+	var odds DealerOdds
+	for i := 0; i < dealerBJ; i++ {
+		odds[i] = d[i] / rest
+	}
+	return odds
+}
+
+// DealerProbabilities returns the exact chances of the dealer's final hands behind the upcard,
+// the hole card and every draw coming from the cards in comp. The upcard isn't in comp.
+func DealerProbabilities(upcard deck.Card, comp Composition, rules Rules) DealerOdds {
+	c := newCalculator(rules, CardValue(upcard))
+	c.peeked = false
+	return c.dealerOdds(comp)
+}
+
+// ExpectedValues returns the expected value of every action open to the hand in state, per unit of its bet,
+// when the cards the player hasn't seen are comp: the shoe and the dealer's hole card. When the dealer peeks
+// the values are once the dealer is known not to have blackjack. Standing, hitting, doubling and surrendering
+// are valued exactly. Splitting is approximate: each split hand is played as well as possible on its own,
+// resplits are valued as if the chance of drawing another card of the pair didn't change, and with
+// OriginalBetsOnly a dealer blackjack takes the bet evenly from the hands there are right after this split.
+func ExpectedValues(state State, comp Composition) map[Action]float64 {
+	return newCalculator(state.Rules, CardValue(state.Upcard)).actions(state, comp)
+}
+
+// Analyze returns the expected values of a hand just dealt against the upcard,
+// comp being every card but the hand and the upcard. Split values are approximate, see ExpectedValues.
+func Analyze(cards []deck.Card, upcard deck.Card, comp Composition, rules Rules) map[Action]float64 {
+	return ExpectedValues(dealtState(cards, upcard, rules), comp)
+}
+
+type handKey struct {
+	hard int
+	ace  bool
+	loss float64
+	comp Composition
+}
+
+// calculator works out expected values against one upcard, per unit bet.
+// When the dealer peeks the player only acts if the dealer doesn't have blackjack,
+// so the hole card is never the one making it.
+type calculator struct {
+	rules  Rules
+	upcard int
+	peeked bool
+	dealer map[Composition]DealerOdds
+	best   map[handKey]float64
+}
+
+func newCalculator(rules Rules, upcard int) *calculator {
+	return &calculator{
+		rules:  rules,
+		upcard: upcard,
+		peeked: !rules.NoHoleCard && (upcard == 1 || upcard == 10),
+		dealer: map[Composition]DealerOdds{},
+		best:   map[handKey]float64{},
+	}
+}
+
+// blackjackValue is the hole card giving the dealer blackjack, 0 when the upcard can't
+func (c *calculator) blackjackValue() int {
+	switch c.upcard {
+	case 1:
+		return 10
+	case 10:
+		return 1
+	}
+	return 0
+}
+
+// odds returns the chance the next card the player draws has each value. When the dealer has peeked
+// the hole card is among the unseen cards but isn't the one making blackjack, which shifts the odds a little.
+func (c *calculator) odds(comp Composition) [11]float64 {
+	var p [11]float64
+	n := float64(comp.Cards())
+	if !c.peeked {
+		for v := 1; v <= 10; v++ {
+			p[v] = float64(comp[v]) / n
+		}
+		return p
+	}
+	rest := n - float64(comp[c.blackjackValue()])
+	for v := 1; v <= 10; v++ {
+		hole := 0.0
+		if v != c.blackjackValue() {
+			hole = float64(comp[v]) / rest
+		}
+		p[v] = (float64(comp[v]) - hole) / (n - 1)
+	}
+	return p
+}
+
+// dealerOdds plays out the dealer's hand from the cards in comp
+func (c *calculator) dealerOdds(comp Composition) DealerOdds {
+	if d, ok := c.dealer[comp]; ok {
+		return d
+	}
+	var d DealerOdds
+	n := comp.Cards()
+	if c.peeked {
+		n -= comp[c.blackjackValue()]
+	}
+	for v := 1; v <= 10; v++ {
+		if comp[v] == 0 || c.peeked && v == c.blackjackValue() {
+			continue
+		}
+		next := comp
+		next[v]--
+		c.dealerDraw(c.upcard+v, c.upcard == 1 || v == 1, 2, next, float64(comp[v])/float64(n), &d)
+	}
+	c.dealer[comp] = d
+	return d
+}
+
+func (c *calculator) dealerDraw(hard int, ace bool, cards int, comp Composition, p float64, d *DealerOdds) {
+	total, soft := hard, false
+	if ace && hard+10 <= 21 {
+		total, soft = hard+10, true
+	}
+	switch {
+	case total > 21:
+		d[dealerBust] += p
+		return
+	case cards == 2 && total == 21:
+		d[dealerBJ] += p
+		return
+	case total > 17 || total == 17 && !(soft && c.rules.HitSoft17):
+		d[total-17] += p
+		return
+	}
+	n := comp.Cards()
+	if n == 0 {
+		d[dealerBust] += p
+		return
+	}
+	for v := 1; v <= 10; v++ {
+		if comp[v] == 0 {
+			continue
+		}
+		next := comp
+		next[v]--
+		c.dealerDraw(hard+v, ace || v == 1, cards+1, next, p*float64(comp[v])/float64(n), d)
+	}
+}
+
+// stand is the expected value of standing on total, apart from a dealer blackjack, and the chance of one
+func (c *calculator) stand(total int, comp Composition) (ev, blackjack float64) {
+	d := c.dealerOdds(comp)
+	ev = d[dealerBust]
+	for k := 17; k <= 21; k++ {
+		if total > k {
+			ev += d[k-17]
+		} else if total < k {
+			ev -= d[k-17]
+		}
+	}
+	return ev, d[dealerBJ]
+}
+
+// bestPlay is the expected value of standing or hitting, whichever is better.
+// loss is what a dealer blackjack takes, without a hole card.
+func (c *calculator) bestPlay(hard int, ace bool, loss float64, comp Composition) float64 {
+	key := handKey{hard, ace, loss, comp}
+	if ev, ok := c.best[key]; ok {
+		return ev
+	}
+	total := softTotal(hard, ace)
+	ev, blackjack := c.stand(total, comp)
+	ev -= blackjack * loss
+	if total < 21 {
+		if hit := c.hit(hard, ace, loss, comp); hit > ev {
+			ev = hit
+		}
+	}
+	c.best[key] = ev
+	return ev
+}
+
+// hit is the expected value of taking a card and playing on as well as possible
+func (c *calculator) hit(hard int, ace bool, loss float64, comp Composition) float64 {
+	odds := c.odds(comp)
+	ev := 0.0
+	for v := 1; v <= 10; v++ {
+		if comp[v] == 0 {
+			continue
+		}
+		p := odds[v]
+		next := comp
+		next[v]--
+		if softTotal(hard+v, ace || v == 1) > 21 {
+			ev -= p
+			continue
+		}
+		ev += p * c.bestPlay(hard+v, ace || v == 1, loss, next)
+	}
+	return ev
+}
+
+// double is the expected value of doubling; loss is what a dealer blackjack takes
+func (c *calculator) double(hard int, ace bool, loss float64, comp Composition) float64 {
+	odds := c.odds(comp)
+	ev := 0.0
+	for v := 1; v <= 10; v++ {
+		if comp[v] == 0 {
+			continue
+		}
+		p := odds[v]
+		next := comp
+		next[v]--
+		total := softTotal(hard+v, ace || v == 1)
+		if total > 21 {
+			ev -= 2 * p
+			continue
+		}
+		stand, blackjack := c.stand(total, next)
+		ev += p * (2*stand - blackjack*loss)
+	}
+	return ev
+}
+
+// split is the expected value of splitting a pair of value card when the player has hands hands.
+// Every split hand is played alike. A hand drawing another card of the pair is resplit while the rules allow,
+// as if the chance of that stayed the same; the other hands are valued exactly.
+// With OriginalBetsOnly a dealer blackjack takes the bet evenly from the hands after this split, resplits aside.
+func (c *calculator) split(card int, comp Composition, hands int) float64 {
+	rules := c.rules
+	// what a dealer blackjack takes from each hand, and from a doubled one
+	loss, doubledLoss := 1.0, 2.0
+	if rules.OriginalBetsOnly {
+		loss = 1 / float64(hands+1)
+		doubledLoss = loss
+	}
+	odds := c.odds(comp)
+	// the value of a split hand drawing v
+	hand := func(v int) float64 {
+		next := comp
+		next[v]--
+		hard, ace := card+v, card == 1 || v == 1
+		if card == 1 && !rules.HitSplitAces {
+			stand, blackjack := c.stand(softTotal(hard, ace), next)
+			return stand - blackjack*loss
+		}
+		ev := c.bestPlay(hard, ace, loss, next)
+		if rules.DoubleAfterSplit && rules.Double.allows(softTotal(hard, ace)) {
+			if double := c.double(hard, ace, doubledLoss, next); double > ev {
+				ev = double
+			}
+		}
+		return ev
+	}
+	other, pair := 0.0, 0.0
+	for v := 1; v <= 10; v++ {
+		if comp[v] == 0 {
+			continue
+		}
+		if v == card {
+			pair = hand(v)
+		} else {
+			other += odds[v] * hand(v)
+		}
+	}
+	p := odds[card]
+	if p < 1 {
+		other /= 1 - p
+	}
+	resplit := card != 1 || rules.ResplitAces
+	others, pairs := splitHands(2, hands+1, rules.SplitHands, p, resplit)
+	return others*other + pairs*pair
+}
+
+// splitHands returns how many hands are expected to end up without and with another card of the pair, with open
+// hands still to draw a second card out of total, when p is the chance of drawing the pair card
+func splitHands(open, total, max int, p float64, resplit bool) (others, pairs float64) {
+	type key struct{ open, total int }
+	memo := map[key][2]float64{}
+	var expect func(open, total int) (float64, float64)
+	expect = func(open, total int) (float64, float64) {
+		if open == 0 {
+			return 0, 0
+		}
+		if e, ok := memo[key{open, total}]; ok {
+			return e[0], e[1]
+		}
+		// the next hand draws another card, or the pair card
+		o, q := expect(open-1, total)
+		others, pairs := (1-p)*(o+1), (1-p)*q
+		if resplit && total < max {
+			o, q = expect(open+1, total+1)
+			others, pairs = others+p*o, pairs+p*q
+		} else {
+			others, pairs = others+p*o, pairs+p*(q+1)
+		}
+		memo[key{open, total}] = [2]float64{others, pairs}
+		return others, pairs
+	}
+	return expect(open, total)
+}
+
+// actions values every action open to the hand in state, comp being the cards the player hasn't seen
+func (c *calculator) actions(state State, comp Composition) map[Action]float64 {
+	rules := c.rules
+	hard, ace := hardTotal(state.Cards)
+	loss, doubledLoss := 1.0, 2.0
+	if rules.OriginalBetsOnly {
+		loss, doubledLoss = 1, 1
+		if state.Split {
+			loss, doubledLoss = 1/float64(state.Hands), 1/float64(state.Hands)
+		}
+	}
+	stand, blackjack := c.stand(softTotal(hard, ace), comp)
+	evs := map[Action]float64{Stand: stand - blackjack*loss}
+	if state.CanHit {
+		evs[Hit] = c.hit(hard, ace, loss, comp)
+	}
+	if state.CanDouble {
+		evs[Double] = c.double(hard, ace, doubledLoss, comp)
+	}
+	if state.CanSplit {
+		evs[Split] = c.split(CardValue(state.Cards[0]), comp, state.Hands)
+	}
+	if state.CanSurrender {
+		evs[Surrender] = -0.5
+		if rules.NoHoleCard && rules.Surrender == LateSurrender {
+			evs[Surrender] = -0.5*(1-blackjack) - blackjack
+		}
+	}
+	return evs
+}
+
+// hardTotal counts aces as one and tells you if there is one
+func hardTotal(cards []deck.Card) (hard int, ace bool) {
+	for _, card := range cards {
+		hard += CardValue(card)
+		ace = ace || CardValue(card) == 1
+	}
+	return hard, ace
+}
+
+// softTotal counts an ace as 11 when that doesn't bust the hand
+func softTotal(hard int, ace bool) int {
+	if ace && hard+10 <= 21 {
+		return hard + 10
+	}
+	return hard
+}
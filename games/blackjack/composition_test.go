@@ -0,0 +1,183 @@
+package blackjack
+
+import (
+	"testing"
+
+	"adamclerk/deck"
+	"adamclerk/deck/internal/decktest"
+	"github.com/stretchr/testify/assert"
+)
+
+// shoe returns the composition of a full shoe
+func shoe(decks int) Composition {
+	var comp Composition
+	for v := 1; v < 10; v++ {
+		comp[v] = 4 * decks
+	}
+	comp[10] = 16 * decks
+	return comp
+}
+
+// infinite is close enough to an infinite deck for four decimals
+var infinite = shoe(2000)
+
+func TestDealerProbabilities(t *testing.T) {
+	// published infinite deck tables: 17, 18, 19, 20, 21, bust and blackjack
+	tables := []struct {
+		upcard string
+		h17    bool
+		odds   DealerOdds
+	}{
+		{"2c", false, DealerOdds{0.1398, 0.1349, 0.1297, 0.1240, 0.1180, 0.3536, 0}},
+		{"6c", false, DealerOdds{0.1654, 0.1063, 0.1063, 0.1017, 0.0972, 0.4232, 0}},
+		{"7c", false, DealerOdds{0.3686, 0.1378, 0.0786, 0.0786, 0.0741, 0.2623, 0}},
+		{"Tc", false, DealerOdds{0.1114, 0.1114, 0.1114, 0.3422, 0.0345, 0.2121, 0.0769}},
+		{"Ac", false, DealerOdds{0.1308, 0.1308, 0.1308, 0.1308, 0.0539, 0.1153, 0.3077}},
+		{"6c", true, DealerOdds{0.1148, 0.1148, 0.1148, 0.1103, 0.1057, 0.4395, 0}},
+		{"Ac", true, DealerOdds{0.0575, 0.1432, 0.1432, 0.1432, 0.0663, 0.1389, 0.3077}},
+	}
+	for _, table := range tables {
+		rules := DefaultRules()
+		rules.HitSoft17 = table.h17
+		up := decktest.Cards(table.upcard)[0]
+		odds := DealerProbabilities(up, infinite.Without(up), rules)
+		for i := range odds {
+			assert.InDelta(t, table.odds[i], odds[i], 0.0001, "%s, H17 %t", table.upcard, table.h17)
+		}
+	}
+
+	up := decktest.Cards("Ac")[0]
+	odds := DealerProbabilities(up, shoe(1).Without(up), DefaultRules())
+	assert.InDelta(t, 16.0/51, odds.Blackjack(), 1e-12)
+	assert.Equal(t, odds[4], odds.Total(21))
+	assert.Equal(t, 0.0, odds.Total(16))
+	peeked := odds.NoBlackjack()
+	sum := 0.0
+	for _, p := range peeked {
+		sum += p
+	}
+	assert.InDelta(t, 1, sum, 1e-12)
+	assert.Equal(t, 0.0, peeked.Blackjack())
+	assert.InDelta(t, odds.Bust()/(1-odds.Blackjack()), peeked.Bust(), 1e-12)
+}
+
+func TestAnalyze(t *testing.T) {
+	// published infinite deck values
+	hand := decktest.Cards("Tc", "6d")
+	up := decktest.Cards("Ts")[0]
+	evs := Analyze(hand, up, infinite.Without(append(hand, up)...), DefaultRules())
+	assert.InDelta(t, -0.5404, evs[Stand], 0.0001)
+	assert.InDelta(t, -0.5398, evs[Hit], 0.0001)
+	assert.InDelta(t, 2*evs[Hit], evs[Double], 0.002, "Doubling 16 takes a card, like hitting once")
+	_, ok := evs[Split]
+	assert.False(t, ok)
+
+	hand = decktest.Cards("6c", "5d")
+	up = decktest.Cards("6s")[0]
+	evs = Analyze(hand, up, infinite.Without(append(hand, up)...), DefaultRules())
+	assert.InDelta(t, 0.6674, evs[Double], 0.0001)
+	assert.Equal(t, Double, best(evs))
+
+	// splitting 8s against a ten beats the rest, more so with resplits
+	hand = decktest.Cards("8c", "8d")
+	up = decktest.Cards("Ts")[0]
+	comp := shoe(6).Without(append(hand, up)...)
+	evs = Analyze(hand, up, comp, DefaultRules())
+	assert.InDelta(t, -0.475, evs[Split], 0.001)
+	assert.Equal(t, Split, best(evs))
+	rules := DefaultRules()
+	rules.SplitHands = 2
+	assert.True(t, Analyze(hand, up, comp, rules)[Split] < evs[Split])
+
+	// surrender is worth half the bet
+	rules = DefaultRules()
+	rules.Surrender = LateSurrender
+	assert.Equal(t, -0.5, Analyze(hand, up, comp, rules)[Surrender])
+}
+
+func TestSplitHands(t *testing.T) {
+	others, pairs := splitHands(2, 2, 4, 0, true)
+	assert.Equal(t, 2.0, others)
+	assert.Equal(t, 0.0, pairs)
+	others, pairs = splitHands(2, 2, 4, 1, true)
+	assert.Equal(t, 0.0, others)
+	assert.Equal(t, 4.0, pairs)
+	others, pairs = splitHands(2, 2, 4, 0.25, false)
+	assert.Equal(t, 1.5, others)
+	assert.Equal(t, 0.5, pairs)
+	// one resplit, to three hands, unless neither hand draws the pair card
+	others, pairs = splitHands(2, 2, 3, 0.5, true)
+	assert.Equal(t, 1.75, others)
+	assert.Equal(t, 1.0, pairs)
+	assert.Equal(t, 2+1-0.25, others+pairs)
+}
+
+// TestExactAgainstTheEngine deals every order of a small shoe and checks the calculator against the engine
+func TestExactAgainstTheEngine(t *testing.T) {
+	// the player holds T 7 against a 6, the dealer draws from the rest
+	player, up := decktest.Cards("Tc", "7d"), decktest.Cards("6h")[0]
+	rest := decktest.Cards("Ts", "Jh", "Qd", "9c", "8s", "5c", "4d", "3h")
+	comp := NewComposition(rest)
+	odds := DealerProbabilities(up, comp, DefaultRules())
+	evs := Analyze(player, up, comp, DefaultRules())
+
+	var totals DealerOdds
+	stand, double, orders := 0.0, 0.0, 0
+	permute(rest, 0, func(order []deck.Card) {
+		cards := append([]deck.Card{player[0], up, player[1]}, order...)
+		for _, a := range []Action{Stand, Double} {
+			g, _ := New(WithDeck(deck.Unshuffled, deck.WithCards(cards...)), WithSeat("Alice", 100, script(a)))
+			result, err := g.PlayRound(10)
+			if !assert.Nil(t, err) {
+				return
+			}
+			if a == Stand {
+				stand += result.Net[0] / 10
+				if result.DealerTotal > 21 {
+					totals[dealerBust]++
+				} else {
+					totals[result.DealerTotal-17]++
+				}
+			} else {
+				double += result.Net[0] / 10
+			}
+		}
+		orders++
+	})
+	for i := range totals {
+		assert.InDelta(t, totals[i]/float64(orders), odds[i], 1e-12)
+	}
+	assert.InDelta(t, stand/float64(orders), evs[Stand], 1e-12)
+	assert.InDelta(t, double/float64(orders), evs[Double], 1e-12)
+}
+
+// permute calls f with every order of cards
+func permute(cards []deck.Card, from int, f func([]deck.Card)) {
+	if from == len(cards) {
+		f(cards)
+		return
+	}
+	for i := from; i < len(cards); i++ {
+		cards[from], cards[i] = cards[i], cards[from]
+		permute(cards, from+1, f)
+		cards[from], cards[i] = cards[i], cards[from]
+	}
+}
+
+func TestSplitOriginalBetsOnly(t *testing.T) {
+	// the dealer surely has blackjack, which only takes the original bet
+	rules := DefaultRules()
+	rules.NoHoleCard = true
+	rules.OriginalBetsOnly = true
+	hand := decktest.Cards("8c", "8d")
+	up := decktest.Cards("As")[0]
+	comp := Composition{10: 20}
+	state := dealtState(hand, up, rules)
+	assert.InDelta(t, -1.0, ExpectedValues(state, comp)[Split], 1e-12)
+
+	// half the bet is riding on this hand, and two of the three hands after the split
+	state.Split, state.Hands = true, 2
+	evs := ExpectedValues(state, comp)
+	assert.InDelta(t, -0.5, evs[Stand], 1e-12)
+	assert.InDelta(t, -2.0/3, evs[Split], 1e-12)
+}
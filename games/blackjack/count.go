@@ -0,0 +1,195 @@
+package blackjack
+
+import (
+	"fmt"
+	"strings"
+
+	"adamclerk/deck"
+)
+
+// System is a card counting system: the tag added to the running count for each card that's seen
+type System struct {
+	Name string
+	Tags [11]float64 // by blackjack value, 1 for aces
+}
+
+// Counting systems
+var (
+	HiLo    = System{Name: "Hi-Lo", Tags: [11]float64{0, -1, 1, 1, 1, 1, 1, 0, 0, 0, -1}}
+	KO      = System{Name: "KO", Tags: [11]float64{0, -1, 1, 1, 1, 1, 1, 1, 0, 0, -1}}
+	HiOptI  = System{Name: "Hi-Opt I", Tags: [11]float64{0, 0, 0, 1, 1, 1, 1, 0, 0, 0, -1}}
+	HiOptII = System{Name: "Hi-Opt II", Tags: [11]float64{0, 0, 1, 1, 2, 2, 1, 1, 0, 0, -2}}
+	OmegaII = System{Name: "Omega II", Tags: [11]float64{0, 0, 1, 1, 2, 2, 2, 1, 0, -1, -2}}
+	Zen     = System{Name: "Zen Count", Tags: [11]float64{0, -1, 1, 1, 2, 2, 2, 1, 0, 0, -2}}
+)
+
+// NewSystem builds a counting system from a tag table keyed by card: A, 2 to 9, and T or 10.
+// Every card needs a tag.
+func NewSystem(name string, tags map[string]float64) (System, error) {
+	s := System{Name: name}
+	set := map[int]bool{}
+	for card, tag := range tags {
+		name := strings.ToUpper(strings.TrimSpace(card))
+		if name == "10" {
+			name = "T"
+		}
+		v := strings.Index("A23456789T", name) + 1
+		if len(name) != 1 || v == 0 {
+			return System{}, fmt.Errorf("Unknown card %s", card)
+		}
+		if set[v] {
+			return System{}, fmt.Errorf("Card %s is tagged twice", card)
+		}
+		set[v] = true
+		s.Tags[v] = tag
+	}
+	for v := 1; v <= 10; v++ {
+		if !set[v] {
+			return System{}, fmt.Errorf("No tag for %s", string("A23456789T"[v-1]))
+		}
+	}
+	return s, nil
+}
+
+// Tag returns the card's tag
+func (s System) Tag(card deck.Card) float64 {
+	return s.Tags[CardValue(card)]
+}
+
+// deckSum is what the tags of a full deck add up to
+func (s System) deckSum() float64 {
+	sum := 16 * s.Tags[10]
+	for v := 1; v < 10; v++ {
+		sum += 4 * s.Tags[v]
+	}
+	return sum
+}
+
+// Balanced tells you if the tags of a full deck add up to zero
+func (s System) Balanced() bool {
+	return s.deckSum() == 0
+}
+
+// Start returns the running count at the top of a shoe. Balanced systems start at zero.
+// Unbalanced ones start below zero by the deck sum for every deck but one, the way KO starts
+// at 4 - 4 x decks, so the key counts are the same whatever the number of decks.
+func (s System) Start(decks int) float64 {
+	if decks < 1 {
+		decks = 1
+	}
+	return -s.deckSum() * float64(decks-1)
+}
+
+// Counter keeps the count of a shoe. Once it watches a shoe, every card dealt from it with Deal
+// is counted as it leaves. A game counting with it holds the dealer's hole card back
+// until the dealer turns it over, as a player only sees it then.
+type Counter struct {
+	system  System
+	shoe    *deck.Deck
+	perDeck float64
+	watched map[*deck.Deck]bool
+	running float64
+	seen    int
+	holding bool        // the next card dealt is face down
+	held    []deck.Card // the face down cards, counted once turned over
+}
+
+// NewCounter returns a counter for the system watching the shoe, which should be full.
+// With a nil shoe it waits for Watch, or for a game counting with it to shuffle up.
+func NewCounter(system System, shoe *deck.Deck) *Counter {
+	c := &Counter{system: system, watched: map[*deck.Deck]bool{}}
+	if shoe != nil {
+		c.Watch(shoe)
+	}
+	return c
+}
+
+// System returns the counting system
+func (c *Counter) System() System {
+	return c.system
+}
+
+// Watch starts the count again on a freshly shuffled shoe. Cards dealt from a shoe
+// watched before aren't counted anymore.
+func (c *Counter) Watch(shoe *deck.Deck) {
+	if shoe != c.shoe {
+		c.shoe = shoe
+		decks := shoe.NumberOfDecks
+		if decks < 1 {
+			decks = 1
+		}
+		c.perDeck = float64(shoe.NumberOfCards()) / float64(decks)
+	}
+	if !c.watched[shoe] {
+		c.watched[shoe] = true
+		shoe.OnDeal(func(card deck.Card) {
+			if c.shoe != shoe {
+				return
+			}
+			if c.holding {
+				c.holding = false
+				c.held = append(c.held, card)
+				return
+			}
+			c.Count(card)
+		})
+	}
+	c.Reset()
+}
+
+// Reset starts the count again, as when the shoe is shuffled
+func (c *Counter) Reset() {
+	decks := 1
+	if c.shoe != nil {
+		decks = c.shoe.NumberOfDecks
+	}
+	c.running = c.system.Start(decks)
+	c.seen = 0
+	c.held = nil
+}
+
+// faceDown holds the next card dealt back from the count, until it's turned over
+func (c *Counter) faceDown() {
+	c.holding = true
+}
+
+// turnOver counts the cards held back
+func (c *Counter) turnOver() {
+	c.Count(c.held...)
+	c.held = nil
+}
+
+// Count adds cards seen some other way than leaving the shoe, a burn card shown for example
+func (c *Counter) Count(cards ...deck.Card) {
+	for _, card := range cards {
+		c.running += c.system.Tag(card)
+		c.seen++
+	}
+}
+
+// RunningCount returns the sum of the tags of the cards seen, from the start count
+func (c *Counter) RunningCount() float64 {
+	return c.running
+}
+
+// Seen returns how many cards have been counted since the count started
+func (c *Counter) Seen() int {
+	return c.seen
+}
+
+// DecksRemaining returns how many decks are left in the shoe: the cards left over the cards in one deck
+func (c *Counter) DecksRemaining() float64 {
+	if c.shoe == nil || c.perDeck == 0 {
+		return 0
+	}
+	return float64(c.shoe.NumberOfCards()) / c.perDeck
+}
+
+// TrueCount returns the running count per deck remaining. Once the shoe is empty it's the running count.
+func (c *Counter) TrueCount() float64 {
+	decks := c.DecksRemaining()
+	if decks == 0 {
+		return c.running
+	}
+	return c.running / decks
+}
@@ -0,0 +1,148 @@
+package blackjack
+
+import (
+	"testing"
+
+	"adamclerk/deck"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSystems(t *testing.T) {
+	for _, s := range []System{HiLo, HiOptI, HiOptII, OmegaII, Zen} {
+		assert.True(t, s.Balanced(), s.Name)
+		assert.Equal(t, 0.0, s.Start(6), s.Name)
+	}
+	assert.False(t, KO.Balanced())
+	assert.Equal(t, 0.0, KO.Start(1))
+	assert.Equal(t, -20.0, KO.Start(6))
+
+	// dealing a whole shoe brings a balanced count back to zero, and KO to +4
+	for _, s := range []System{HiLo, KO, HiOptI, HiOptII, OmegaII, Zen} {
+		shoe, _ := deck.New(deck.Decks(2))
+		c := NewCounter(s, shoe)
+		hand, _ := deck.New(deck.Empty)
+		shoe.Deal(shoe.NumberOfCards(), hand)
+		assert.Equal(t, 104, c.Seen(), s.Name)
+		if s.Balanced() {
+			assert.Equal(t, 0.0, c.RunningCount(), s.Name)
+		} else {
+			assert.Equal(t, 4.0, c.RunningCount(), s.Name)
+		}
+	}
+}
+
+func TestCounter(t *testing.T) {
+	shoe, _ := deck.New(deck.Decks(2), deck.Unshuffled)
+	c := NewCounter(HiLo, shoe)
+	assert.Equal(t, 2.0, c.DecksRemaining())
+	player, _ := deck.New(deck.Empty)
+	dealer, _ := deck.New(deck.Empty)
+
+	// the ace to the ten of clubs make +3
+	shoe.Deal(1, player, dealer)
+	shoe.Deal(4, player, dealer)
+	assert.Equal(t, 10, c.Seen())
+	assert.Equal(t, 3.0, c.RunningCount())
+	assert.InDelta(t, 94.0/52, c.DecksRemaining(), 1e-9)
+	assert.InDelta(t, 3/(94.0/52), c.TrueCount(), 1e-9)
+
+	// a shown burn card is counted by hand
+	c.Count(deck.NewCard(deck.KING, deck.HEART))
+	assert.Equal(t, 2.0, c.RunningCount())
+
+	// dealing between hands isn't counted
+	player.Deal(2, dealer)
+	assert.Equal(t, 11, c.Seen())
+
+	// a new shoe starts again, and the old one is forgotten
+	next, _ := deck.New(deck.Decks(6))
+	c.Watch(next)
+	shoe.Deal(1, player)
+	assert.Equal(t, 0, c.Seen())
+	assert.Equal(t, 0.0, c.RunningCount())
+	assert.Equal(t, 6.0, c.DecksRemaining())
+
+	ko := NewCounter(KO, next)
+	assert.Equal(t, -20.0, ko.RunningCount())
+	next.Deal(3, player)
+	assert.Equal(t, 3, ko.Seen())
+	assert.Equal(t, c.Seen(), ko.Seen())
+}
+
+func TestNewSystem(t *testing.T) {
+	tags := map[string]float64{"A": -1, "2": 0.5, "3": 1, "4": 1, "5": 1.5, "6": 1, "7": 0.5, "8": 0, "9": -0.5, "10": -1}
+	halves, err := NewSystem("Halves", tags)
+	if assert.Nil(t, err) {
+		assert.True(t, halves.Balanced())
+		assert.Equal(t, -1.0, halves.Tag(deck.NewCard(deck.QUEEN, deck.CLUB)))
+		assert.Equal(t, 1.5, halves.Tag(deck.NewCard(deck.FIVE, deck.CLUB)))
+	}
+
+	tags["t"] = -1
+	_, err = NewSystem("Twice", tags)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "is tagged twice")
+	}
+	delete(tags, "t")
+	delete(tags, "9")
+	_, err = NewSystem("Short", tags)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "No tag for 9", err.Error())
+	}
+	tags["J"] = -1
+	_, err = NewSystem("Jacks", tags)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "Unknown card J", err.Error())
+	}
+}
+
+func TestCountWith(t *testing.T) {
+	hilo := NewCounter(HiLo, nil)
+	ko := NewCounter(KO, nil)
+	g, _ := New(WithSeat("Alice", 1000, BasicStrategy(DefaultRules())), Decks(2), CountWith(hilo, ko))
+	shuffles := 0
+	for round := 0; round < 60; round++ {
+		result, err := g.PlayRound(10)
+		if !assert.Nil(t, err) {
+			return
+		}
+		if result.Shuffled {
+			shuffles++
+		}
+		// the cards still in the shoe add up to minus the count
+		rest := 0.0
+		for _, c := range g.Shoe().Cards {
+			rest += HiLo.Tag(c)
+		}
+		assert.Equal(t, -rest, hilo.RunningCount())
+		assert.Equal(t, g.Dealt(), hilo.Seen())
+		assert.Equal(t, g.Dealt(), ko.Seen())
+		assert.Equal(t, float64(g.Shoe().NumberOfCards())/52, hilo.DecksRemaining())
+	}
+	assert.True(t, shuffles > 1)
+}
+
+func TestHoleCardCountedWhenTurnedOver(t *testing.T) {
+	for _, rules := range []func(*Options){Decks(2), NoHoleCard} {
+		hilo := NewCounter(HiLo, nil)
+		g, _ := New(WithSeat("Alice", 1000, BasicStrategy(DefaultRules())), rules, CountWith(hilo))
+		r, err := g.Deal(10)
+		if !assert.Nil(t, err) {
+			return
+		}
+		if r.Phase() == RoundOver {
+			continue
+		}
+		// the player sees their two cards and the upcard, nothing else
+		assert.Equal(t, 3, hilo.Seen())
+		unseen := 0.0
+		for _, c := range r.State().Unseen() {
+			unseen += HiLo.Tag(c)
+		}
+		assert.Equal(t, -unseen, hilo.RunningCount())
+
+		_, err = r.Play()
+		assert.Nil(t, err)
+		assert.Equal(t, g.Dealt(), hilo.Seen())
+	}
+}
@@ -0,0 +1,445 @@
+// Package blackjack is a blackjack engine dealing from a multi deck shoe.
+package blackjack
+
+import (
+	"errors"
+	"fmt"
+
+	"adamclerk/deck"
+)
+
+// Blackjack is a table of players playing against the dealer, round after round.
+// Rules can be found here: https://bicyclecards.com/how-to-play/blackjack/
+type Blackjack struct {
+	debug       bool
+	deckOptions []func(*deck.Options)
+	shuffle     func(*deck.Deck)
+	rules       Rules
+	rounds      int
+	shoe        *deck.Deck
+	size        int        // cards in a full shoe
+	discards    *deck.Deck // cards played since the shoe was shuffled
+	players     []*Player
+	round       *Round
+	counters    []*Counter
+}
+
+// Seat describes a player joining the table
+type Seat struct {
+	Name     string
+	Bankroll float64
+	Decider  Decider
+}
+
+// Options how to configure a game of blackjack
+type Options struct {
+	DeckOptions []func(*deck.Options)
+	Shuffle     func(*deck.Deck)
+	Seats       []Seat
+	Rules       Rules
+	Counters    []*Counter
+	Debug       bool
+}
+
+// Player is a player at the table
+type Player struct {
+	name     string
+	bankroll float64
+	decider  Decider
+}
+
+// Name returns the players name for verification and announcement
+func (p Player) Name() string {
+	return p.name
+}
+
+// Bankroll returns the chips the player has off the table
+func (p Player) Bankroll() float64 {
+	return p.bankroll
+}
+
+// WithDeck allows the a game to be configured with a specific shoe.
+// A new shoe is created with these options, after the Decks rule, every time it is shuffled.
+func WithDeck(deckOptions ...func(*deck.Options)) func(*Options) {
+	return func(o *Options) {
+		o.DeckOptions = deckOptions
+	}
+}
+
+// WithShuffle shuffles the shoe with the given function instead of the deck's own shuffle.
+// Simulations use it to shuffle from their own random source.
+func WithShuffle(shuffle func(*deck.Deck)) func(*Options) {
+	return func(o *Options) {
+		o.Shuffle = shuffle
+	}
+}
+
+// WithSeat adds a player to the table. Players sit, and are dealt, in the order they are added.
+func WithSeat(name string, bankroll float64, decider Decider) func(*Options) {
+	return func(o *Options) {
+		o.Seats = append(o.Seats, Seat{Name: name, Bankroll: bankroll, Decider: decider})
+	}
+}
+
+// WithRules replaces every rule. Options after it change single rules.
+func WithRules(rules Rules) func(*Options) {
+	return func(o *Options) {
+		o.Rules = rules
+	}
+}
+
+// Decks sets the number of decks in the shoe
+func Decks(count int) func(*Options) {
+	return func(o *Options) {
+		o.Rules.Decks = count
+	}
+}
+
+// HitSoft17 makes the dealer hit soft 17 (H17). By default the dealer stands (S17).
+func HitSoft17(o *Options) {
+	o.Rules.HitSoft17 = true
+}
+
+// NoHoleCard deals the European way: the dealer takes a single card until the players are done
+// and a dealer blackjack takes every bet, doubles and splits included.
+func NoHoleCard(o *Options) {
+	o.Rules.NoHoleCard = true
+}
+
+// OriginalBetsOnly limits a dealer blackjack without a hole card to the original bets
+func OriginalBetsOnly(o *Options) {
+	o.Rules.OriginalBetsOnly = true
+}
+
+// DoubleOn sets which two card hands can be doubled
+func DoubleOn(rule DoubleRule) func(*Options) {
+	return func(o *Options) {
+		o.Rules.Double = rule
+	}
+}
+
+// NoDoubleAfterSplit stops split hands being doubled
+func NoDoubleAfterSplit(o *Options) {
+	o.Rules.DoubleAfterSplit = false
+}
+
+// SplitHands sets how many hands a player can split to. 1 forbids splitting.
+func SplitHands(hands int) func(*Options) {
+	return func(o *Options) {
+		o.Rules.SplitHands = hands
+	}
+}
+
+// ResplitAces allows split aces dealt another ace to be split again
+func ResplitAces(o *Options) {
+	o.Rules.ResplitAces = true
+}
+
+// HitSplitAces allows split aces to take more than one card
+func HitSplitAces(o *Options) {
+	o.Rules.HitSplitAces = true
+}
+
+// AllowSurrender sets when a hand can be surrendered
+func AllowSurrender(rule SurrenderRule) func(*Options) {
+	return func(o *Options) {
+		o.Rules.Surrender = rule
+	}
+}
+
+// NoInsurance stops insurance being offered
+func NoInsurance(o *Options) {
+	o.Rules.Insurance = false
+}
+
+// BlackjackPays sets the payout for a blackjack, 3:2 by default
+func BlackjackPays(payout Payout) func(*Options) {
+	return func(o *Options) {
+		o.Rules.Blackjack = payout
+	}
+}
+
+// Penetration sets the share of the shoe dealt before it is shuffled, 0.75 by default
+func Penetration(share float64) func(*Options) {
+	return func(o *Options) {
+		o.Rules.Penetration = share
+	}
+}
+
+// CountWith keeps the counters on the game's shoe: they watch every new shoe
+// and start again when the discards are shuffled back in.
+func CountWith(counters ...*Counter) func(*Options) {
+	return func(o *Options) {
+		o.Counters = append(o.Counters, counters...)
+	}
+}
+
+// Debug sets the debug param for the game
+func Debug(o *Options) {
+	o.Debug = true
+}
+
+// New function creates a new game of blackjack
+func New(options ...func(*Options)) (*Blackjack, error) {
+	opt := Options{DeckOptions: []func(*deck.Options){}, Rules: DefaultRules()}
+	for _, option := range options {
+		option(&opt)
+	}
+	if len(opt.Seats) < 1 {
+		return nil, errors.New("At least one seat is needed")
+	}
+	if len(opt.Seats) > 7 {
+		return nil, errors.New("At most 7 seats can play at one table")
+	}
+	if err := opt.Rules.validate(); err != nil {
+		return nil, err
+	}
+
+	g := &Blackjack{
+		debug:       opt.Debug,
+		deckOptions: opt.DeckOptions,
+		shuffle:     opt.Shuffle,
+		rules:       opt.Rules,
+		counters:    opt.Counters,
+	}
+	for _, s := range opt.Seats {
+		if s.Decider == nil {
+			return nil, errors.New("Seat " + s.Name + " has no decider")
+		}
+		if s.Bankroll < 0 {
+			return nil, errors.New("Seat " + s.Name + " has a negative bankroll")
+		}
+		g.players = append(g.players, &Player{name: s.Name, bankroll: s.Bankroll, decider: s.Decider})
+	}
+	return g, nil
+}
+
+// Players returns the players in seat order
+func (g *Blackjack) Players() []*Player {
+	return g.players
+}
+
+// Rules returns the table rules
+func (g *Blackjack) Rules() Rules {
+	return g.rules
+}
+
+// Shoe returns the cards left to deal, nil before the first round.
+// Counting and composition dependent strategies read it; dealing from it breaks the game.
+func (g *Blackjack) Shoe() *deck.Deck {
+	return g.shoe
+}
+
+// Dealt returns how many cards have left the shoe since it was shuffled
+func (g *Blackjack) Dealt() int {
+	if g.shoe == nil {
+		return 0
+	}
+	return g.size - len(g.shoe.Cards)
+}
+
+// ShuffleDue tells you if the next round is dealt from a new shoe: before the first round,
+// and once the penetration is reached
+func (g *Blackjack) ShuffleDue() bool {
+	return g.shoe == nil || float64(g.Dealt()) >= g.rules.Penetration*float64(g.size)
+}
+
+// Shuffle starts a new shoe between rounds. Deal shuffles when it's due; counting players shuffle
+// first so they bet on the new shoe.
+func (g *Blackjack) Shuffle() error {
+	if g.round != nil && g.round.phase != RoundOver {
+		return errors.New("The round isn't over")
+	}
+	return g.newShoe()
+}
+
+func (g *Blackjack) debugf(format string, a ...interface{}) {
+	deck.Debugf(g.debug, format, a...)
+}
+
+// newShoe shuffles every card back into the shoe
+func (g *Blackjack) newShoe() error {
+	options := append([]func(*deck.Options){deck.Decks(g.rules.Decks)}, g.deckOptions...)
+	if g.shuffle != nil {
+		options = append(options, deck.Unshuffled)
+	}
+	shoe, err := deck.New(options...)
+	if err != nil {
+		return err
+	}
+	if g.shuffle != nil {
+		g.shuffle(shoe)
+	}
+	discards, _ := deck.New(deck.Empty)
+	g.shoe, g.size, g.discards = shoe, len(shoe.Cards), discards
+	for _, c := range g.counters {
+		c.Watch(shoe)
+	}
+	g.debugf("Shoe shuffled, %d cards\n", g.size)
+	return nil
+}
+
+// draw deals a card from the shoe. If the shoe runs out mid round the discards are shuffled into it.
+// With no discards either every card is on the table, and the round can't go on.
+func (g *Blackjack) draw(to *deck.Deck) error {
+	if len(g.shoe.Cards) == 0 {
+		if len(g.discards.Cards) == 0 {
+			return errors.New("Every card in the shoe is on the table")
+		}
+		g.shoe.Cards, g.discards.Cards = g.discards.Cards, nil
+		if g.shuffle != nil {
+			g.shuffle(g.shoe)
+		} else {
+			g.shoe.Shuffle()
+		}
+		for _, c := range g.counters {
+			c.Reset()
+		}
+		g.debugf("Discards shuffled into the shoe, %d cards\n", len(g.shoe.Cards))
+	}
+	g.shoe.Deal(1, to)
+	return nil
+}
+
+// Outcome is how a hand ended
+type Outcome int
+
+// Constants for Outcome
+const (
+	Lost Outcome = iota
+	Pushed
+	Won
+	WonBlackjack
+	Surrendered
+)
+
+func (o Outcome) String() string {
+	names := []string{"Lost", "Pushed", "Won", "WonBlackjack", "Surrendered"}
+	if o < 0 || int(o) >= len(names) {
+		return fmt.Sprintf("Outcome(%d)", int(o))
+	}
+	return names[o]
+}
+
+// HandResult is how a hand was settled
+type HandResult struct {
+	Seat    int
+	Hand    int // the seat's hand, in the order they were played
+	Cards   []deck.Card
+	Total   int
+	Bet     float64 // the bet on the hand, doubles included
+	Split   bool
+	Doubled bool
+	Busted  bool
+	Outcome Outcome
+	Net     float64 // chips won or lost
+}
+
+// RoundResult is the record of a round
+type RoundResult struct {
+	Number          int
+	Shuffled        bool // the shoe was shuffled before the round
+	Bets            []float64
+	Dealer          []deck.Card
+	DealerTotal     int
+	DealerBlackjack bool
+	Hands           []HandResult // in the order they were played
+	Actions         []ActionRecord
+	Insurance       []float64 // insurance bets by seat
+	Net             []float64 // chips won or lost by seat, insurance included
+}
+
+// Deal starts a round with one bet per seat, 0 sits the seat out.
+// The shoe is shuffled first when the penetration has been reached.
+// Bets leave the players' bankrolls until the round is settled.
+func (g *Blackjack) Deal(bets ...float64) (*Round, error) {
+	if g.round != nil && g.round.phase != RoundOver {
+		return nil, errors.New("The round isn't over")
+	}
+	if len(bets) != len(g.players) {
+		return nil, fmt.Errorf("Expected %d bets, one per seat", len(g.players))
+	}
+	placed := false
+	for i, bet := range bets {
+		p := g.players[i]
+		if bet < 0 {
+			return nil, fmt.Errorf("Invalid bet %v by %s", bet, p.name)
+		}
+		if bet > p.bankroll {
+			return nil, fmt.Errorf("%s can't cover a bet of %v", p.name, bet)
+		}
+		placed = placed || bet > 0
+	}
+	if !placed {
+		return nil, errors.New("No bets were placed")
+	}
+
+	if g.ShuffleDue() {
+		if err := g.newShoe(); err != nil {
+			return nil, err
+		}
+	}
+	shuffled := g.Dealt() == 0
+	g.rounds++
+	g.debugf("Round %d\n", g.rounds)
+
+	r := &Round{
+		game:      g,
+		insurance: make([]float64, len(g.players)),
+		result: &RoundResult{
+			Number:    g.rounds,
+			Shuffled:  shuffled,
+			Bets:      append([]float64{}, bets...),
+			Insurance: make([]float64, len(g.players)),
+			Net:       make([]float64, len(g.players)),
+		},
+	}
+	for i, bet := range bets {
+		if bet > 0 {
+			g.players[i].bankroll -= bet
+			r.hands = append(r.hands, &hand{seat: i, bet: bet, original: true})
+		}
+	}
+	// one card at a time, the dealer last; without a hole card the dealer's second card waits
+	for i := 0; i < 2; i++ {
+		for _, h := range r.hands {
+			if err := g.draw(&h.cards); err != nil {
+				return nil, r.abandon(err)
+			}
+		}
+		if i == 0 || !g.rules.NoHoleCard {
+			if i == 1 {
+				for _, c := range g.counters {
+					c.faceDown()
+				}
+			}
+			if err := g.draw(&r.dealer); err != nil {
+				return nil, r.abandon(err)
+			}
+		}
+	}
+	g.debugf("  Dealer shows %s\n", r.Upcard())
+	g.round = r
+	if err := r.advance(); err != nil {
+		return nil, r.abandon(err)
+	}
+	return r, nil
+}
+
+// PlayRound deals a round and plays it through with the players' deciders.
+// If a decider returns an illegal action, or every card is on the table, the round
+// is abandoned, every bankroll is restored and an error is returned.
+func (g *Blackjack) PlayRound(bets ...float64) (*RoundResult, error) {
+	r, err := g.Deal(bets...)
+	if err != nil {
+		return nil, err
+	}
+	result, err := r.Play()
+	if err != nil {
+		if r.phase != RoundOver {
+			r.abandon(err)
+		}
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,126 @@
+package blackjack
+
+import (
+	"fmt"
+	"strings"
+
+	"adamclerk/deck"
+)
+
+// dealerStyle plays like the dealer: it hits below 17
+var dealerStyle = DeciderFuncs(func(s State) Action {
+	if s.Total < 17 {
+		return Hit
+	}
+	return Stand
+}, nil)
+
+// This example plays a round from an unshuffled shoe
+func Example() {
+	game, err := New(
+		WithDeck(deck.Unshuffled),
+		WithSeat("Alice", 100, dealerStyle),
+		WithSeat("Bob", 100, dealerStyle),
+	)
+	if err != nil {
+		panic(err)
+	}
+	result, err := game.PlayRound(10, 20)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("Dealer: %v %d\n", result.Dealer, result.DealerTotal)
+	for _, h := range result.Hands {
+		p := game.Players()[h.Seat]
+		fmt.Printf("%s: %v %d, %s %v, %v chips\n", p.Name(), h.Cards, h.Total, h.Outcome, h.Net, p.Bankroll())
+	}
+	// Output:
+	// Dealer: [3♣ 6♣ J♣] 19
+	// Alice: [A♣ 4♣ 7♣ 8♣] 20, Won 10, 110 chips
+	// Bob: [2♣ 5♣ 9♣ T♣] 26, Lost -20, 80 chips
+}
+
+// This example drives a round one decision at a time
+func ExampleRound() {
+	game, _ := New(WithDeck(deck.Unshuffled), WithSeat("Alice", 100, dealerStyle))
+	round, _ := game.Deal(10)
+	for round.Phase() != RoundOver {
+		s := round.State()
+		fmt.Printf("%v against %s, %d, can double: %t\n", s.Cards, s.Upcard, s.Total, s.CanDouble)
+		if s.Total < 17 {
+			round.Act(Hit)
+		} else {
+			round.Act(Stand)
+		}
+	}
+	result := round.Result()
+	fmt.Printf("Dealer: %v %d, %s\n", result.Dealer, result.DealerTotal, result.Hands[0].Outcome)
+	// Output:
+	// [A♣ 3♣] against 2♣, 14, can double: true
+	// [A♣ 3♣ 5♣] against 2♣, 19, can double: false
+	// Dealer: [2♣ 4♣ 6♣ 7♣] 19, Pushed
+}
+
+// This example asks basic strategy what to do with a hand
+func ExampleRecommend() {
+	cards := []deck.Card{deck.NewCard(deck.ACE, deck.HEART), deck.NewCard(deck.SEVEN, deck.CLUB)}
+	rules := DefaultRules()
+	for _, upcard := range []deck.Card{deck.NewCard(deck.TWO, deck.SPADE), deck.NewCard(deck.SIX, deck.SPADE), deck.NewCard(deck.NINE, deck.SPADE)} {
+		fmt.Printf("%v against %s: %s\n", cards, upcard, Recommend(cards, upcard, rules))
+	}
+	rules.HitSoft17 = true
+	fmt.Printf("%v against %s when the dealer hits soft 17: %s\n", cards, deck.NewCard(deck.TWO, deck.SPADE), Recommend(cards, deck.NewCard(deck.TWO, deck.SPADE), rules))
+	// Output:
+	// [A♥ 7♣] against 2♠: Stand
+	// [A♥ 7♣] against 6♠: Double
+	// [A♥ 7♣] against 9♠: Hit
+	// [A♥ 7♣] against 2♠ when the dealer hits soft 17: Double
+}
+
+// This example loads a chart that stands on soft 14 and plays it
+func ExampleReadChartCSV() {
+	chart, err := ReadChartCSV(strings.NewReader(`Timid,2,3,4,5,6,7,8,9,10,A
+A3,S,S,S,S,S,S,S,S,S,S
+`))
+	if err != nil {
+		panic(err)
+	}
+	game, _ := New(WithDeck(deck.Unshuffled), WithSeat("Alice", 100, chart))
+	result, _ := game.PlayRound(10)
+	fmt.Printf("%s: %v %d, %s\n", chart.Name, result.Hands[0].Cards, result.Hands[0].Total, result.Hands[0].Outcome)
+	// Output:
+	// Timid: [A♣ 3♣] 14, Lost
+}
+
+// This example keeps a Hi-Lo count while cards are dealt from a shoe
+func ExampleCounter() {
+	shoe, _ := deck.New(deck.Decks(6), deck.Unshuffled)
+	counter := NewCounter(HiLo, shoe)
+	hand, _ := deck.New(deck.Empty)
+	shoe.Deal(20, hand)
+	fmt.Printf("%d cards seen, running count %v, %.1f decks left, true count %.2f\n",
+		counter.Seen(), counter.RunningCount(), counter.DecksRemaining(), counter.TrueCount())
+	// Output:
+	// 20 cards seen, running count 4, 5.6 decks left, true count 0.71
+}
+
+// This example simulates a Hi-Lo counter spreading 1 to 8 units against six decks
+func ExampleSimulate() {
+	result, err := Simulate(Simulation{
+		Rules:    DefaultRules(),
+		Rounds:   50000,
+		Seed:     2024,
+		Bet:      BetRamp(1, 2, 4, 6, 8),
+		Bankroll: 1000,
+	})
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%d rounds, %.0f units wagered\n", result.Rounds, result.Wagered)
+	fmt.Printf("House edge %.2f%%, from %.2f%% to %.2f%%\n", 100*result.HouseEdge.Value, 100*result.HouseEdge.Low, 100*result.HouseEdge.High)
+	fmt.Printf("Standard deviation %.2f units a round\n", result.SD.Value)
+	// Output:
+	// 50000 rounds, 73290 units wagered
+	// House edge 0.36%, from -1.02% to 1.74%
+	// Standard deviation 2.31 units a round
+}
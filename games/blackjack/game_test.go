@@ -0,0 +1,433 @@
+package blackjack
+
+import (
+	"math/rand"
+	"testing"
+
+	"adamclerk/deck"
+	"adamclerk/deck/internal/decktest"
+	"github.com/stretchr/testify/assert"
+)
+
+// standing never takes a card
+var standing = DeciderFuncs(func(s State) Action { return Stand }, nil)
+
+// insuring stands and always takes insurance
+var insuring = DeciderFuncs(func(s State) Action { return Stand }, func(s State) bool { return true })
+
+// script plays the given actions in order, then stands
+func script(actions ...Action) Decider {
+	return DeciderFuncs(func(s State) Action {
+		if len(actions) == 0 {
+			return Stand
+		}
+		a := actions[0]
+		actions = actions[1:]
+		return a
+	}, nil)
+}
+
+// stacked returns a deck option dealing the given cards first; the rest of the deck follows in order.
+// Players are dealt a card each, then the dealer's upcard, then a second card each and the hole card.
+func stacked(cards ...string) func(*Options) {
+	return WithDeck(decktest.Stacked(cards...)...)
+}
+
+func TestDealerSoft17(t *testing.T) {
+	cards := []string{"Tc", "6c", "Kc", "Ah", "3d"}
+	game, _ := New(stacked(cards...), WithSeat("Alice", 100, standing))
+	result, err := game.PlayRound(10)
+	assert.Nil(t, err)
+	assert.Equal(t, 17, result.DealerTotal)
+	assert.Equal(t, []float64{10}, result.Net)
+	assert.Equal(t, 110.0, game.Players()[0].Bankroll())
+
+	game, _ = New(stacked(cards...), WithSeat("Alice", 100, standing), HitSoft17)
+	result, _ = game.PlayRound(10)
+	assert.Equal(t, decktest.Cards("6c", "Ah", "3d"), result.Dealer)
+	assert.Equal(t, Pushed, result.Hands[0].Outcome)
+	assert.Equal(t, 100.0, game.Players()[0].Bankroll())
+}
+
+func TestBlackjackPayouts(t *testing.T) {
+	for _, test := range []struct {
+		payout Payout
+		net    float64
+	}{
+		{ThreeToTwo, 15},
+		{SixToFive, 12},
+		{EvenMoney, 10},
+	} {
+		game, _ := New(stacked("Ac", "9c", "Kc", "7h"), WithSeat("Alice", 100, standing), BlackjackPays(test.payout))
+		result, err := game.PlayRound(10)
+		assert.Nil(t, err)
+		assert.Equal(t, WonBlackjack, result.Hands[0].Outcome)
+		assert.Equal(t, test.net, result.Net[0], test.payout.String())
+	}
+}
+
+func TestDealerPeeks(t *testing.T) {
+	// the dealer peeks under the ten and nobody gets to double
+	game, _ := New(stacked("5c", "Tc", "6c", "Ah", "9d"), WithSeat("Alice", 100, script(Double)))
+	result, err := game.PlayRound(10)
+	assert.Nil(t, err)
+	assert.True(t, result.DealerBlackjack)
+	assert.Nil(t, result.Actions)
+	assert.Equal(t, []float64{-10}, result.Net)
+}
+
+func TestNoHoleCard(t *testing.T) {
+	// without a hole card the dealer's ace comes after the double: Alice loses both bets
+	cards := []string{"5c", "Tc", "6c", "9d", "Ah"}
+	game, _ := New(stacked(cards...), WithSeat("Alice", 100, script(Double)), NoHoleCard)
+	result, err := game.PlayRound(10)
+	assert.Nil(t, err)
+	assert.Equal(t, decktest.Cards("5c", "6c", "9d"), result.Hands[0].Cards)
+	assert.True(t, result.DealerBlackjack)
+	assert.Equal(t, []float64{-20}, result.Net)
+
+	game, _ = New(stacked(cards...), WithSeat("Alice", 100, script(Double)), NoHoleCard, OriginalBetsOnly)
+	result, _ = game.PlayRound(10)
+	assert.Equal(t, []float64{-10}, result.Net)
+	assert.Equal(t, 90.0, game.Players()[0].Bankroll())
+}
+
+func TestSplitAndResplit(t *testing.T) {
+	// Alice splits eights, splits again, doubles the first hand and splits the second
+	cards := []string{"8c", "7c", "8d", "Tc", "8h", "3c", "Kc", "8s", "Qd", "Kh", "2c"}
+	game, _ := New(stacked(cards...), WithSeat("Alice", 1000, script(Split, Split, Double, Split, Stand, Stand, Stand)))
+	r, err := game.Deal(10)
+	assert.Nil(t, err)
+	for r.Phase() != RoundOver {
+		if s := r.State(); s.Hand == 1 && len(s.Cards) == 2 && s.Cards[1] == decktest.Cards("8s")[0] {
+			assert.Equal(t, 3, s.Hands)
+			assert.True(t, s.CanSplit)
+			assert.True(t, s.Split)
+		}
+		assert.Nil(t, r.Step())
+	}
+	result := r.Result()
+	assert.Equal(t, []ActionRecord{
+		{Seat: 0, Hand: 0, Action: Split},
+		{Seat: 0, Hand: 0, Action: Split},
+		{Seat: 0, Hand: 0, Action: Double},
+		{Seat: 0, Hand: 1, Action: Split},
+		{Seat: 0, Hand: 1, Action: Stand},
+		{Seat: 0, Hand: 2, Action: Stand},
+		{Seat: 0, Hand: 3, Action: Stand},
+	}, result.Actions)
+	var hands [][]deck.Card
+	var bets []float64
+	for _, h := range result.Hands {
+		hands = append(hands, h.Cards)
+		bets = append(bets, h.Bet)
+		assert.True(t, h.Split)
+	}
+	assert.Equal(t, [][]deck.Card{decktest.Cards("8c", "3c", "Kc"), decktest.Cards("8h", "Qd"), decktest.Cards("8s", "Kh"), decktest.Cards("8d", "2c")}, hands)
+	assert.Equal(t, []float64{20, 10, 10, 10}, bets)
+	// the dealer's 17 loses to 21 and 18 twice and beats 10
+	assert.Equal(t, 17, result.DealerTotal)
+	assert.Equal(t, []float64{20 + 10 + 10 - 10}, result.Net)
+	assert.Equal(t, 1030.0, game.Players()[0].Bankroll())
+}
+
+func TestSplitLimits(t *testing.T) {
+	split := DeciderFuncs(func(s State) Action {
+		if s.CanSplit {
+			return Split
+		}
+		return Stand
+	}, nil)
+	for _, test := range []struct {
+		options []func(*Options)
+		hands   int
+	}{
+		{nil, 2},
+		{[]func(*Options){ResplitAces}, 4},
+		{[]func(*Options){ResplitAces, SplitHands(3)}, 3},
+		{[]func(*Options){SplitHands(1)}, 1},
+	} {
+		options := append([]func(*Options){stacked("Ac", "7c", "Ad", "Tc", "Ah", "As", "9c", "9d", "9h")}, test.options...)
+		game, _ := New(append(options, WithSeat("Alice", 1000, split))...)
+		result, err := game.PlayRound(10)
+		assert.Nil(t, err)
+		assert.Equal(t, test.hands, len(result.Hands))
+		for _, h := range result.Hands {
+			if test.hands > 1 {
+				// split aces take one card
+				assert.Equal(t, 2, len(h.Cards))
+			}
+		}
+	}
+}
+
+func TestIllegalActions(t *testing.T) {
+	for _, test := range []struct {
+		options []func(*Options)
+		action  Action
+		message string
+	}{
+		{[]func(*Options){DoubleOn(DoubleNineToEleven)}, Double, "Invalid action Double by Alice: the hand can't be doubled"},
+		{nil, Split, "Invalid action Split by Alice: the hand can't be split"},
+		{nil, Surrender, "Invalid action Surrender by Alice: the hand can't be surrendered"},
+		{nil, Action(9), "Invalid action Action(9) by Alice: unknown action"},
+	} {
+		options := append([]func(*Options){stacked("7c", "Tc", "5c", "8h"), WithSeat("Alice", 100, script(test.action))}, test.options...)
+		game, _ := New(options...)
+		_, err := game.PlayRound(10)
+		assert.Equal(t, test.message, err.Error())
+		assert.Equal(t, 100.0, game.Players()[0].Bankroll())
+	}
+
+	// split aces dealt another ace can be split again, not hit
+	game, _ := New(stacked("Ac", "7c", "Ad", "Tc", "Ah"), WithSeat("Alice", 100, standing), ResplitAces)
+	r, _ := game.Deal(10)
+	assert.Nil(t, r.Act(Split))
+	assert.Equal(t, "Invalid action Hit by Alice: the hand can't take another card", r.Act(Hit).Error())
+}
+
+func TestDoubleAfterSplit(t *testing.T) {
+	cards := []string{"5c", "7c", "5d", "Tc", "6h", "4s"}
+	game, _ := New(stacked(cards...), WithSeat("Alice", 100, standing))
+	r, _ := game.Deal(10)
+	assert.Nil(t, r.Act(Split))
+	assert.True(t, r.State().CanDouble)
+
+	game, _ = New(stacked(cards...), WithSeat("Alice", 100, standing), NoDoubleAfterSplit)
+	r, _ = game.Deal(10)
+	assert.Nil(t, r.Act(Split))
+	assert.False(t, r.State().CanDouble)
+	assert.True(t, r.State().CanHit)
+}
+
+func TestInsurance(t *testing.T) {
+	// the dealer has blackjack: insurance pays 2:1 and covers the bet
+	game, _ := New(stacked("7c", "Ac", "Tc", "Kh"), WithSeat("Alice", 100, insuring))
+	result, err := game.PlayRound(10)
+	assert.Nil(t, err)
+	assert.Equal(t, []float64{5}, result.Insurance)
+	assert.Equal(t, []float64{0}, result.Net)
+
+	// insuring a blackjack is even money
+	game, _ = New(stacked("Ad", "Ac", "Td", "Kh"), WithSeat("Alice", 100, insuring))
+	result, _ = game.PlayRound(10)
+	assert.Equal(t, Pushed, result.Hands[0].Outcome)
+	assert.Equal(t, []float64{10}, result.Net)
+
+	// no blackjack: the insurance is lost and the hand played
+	game, _ = New(stacked("Td", "Ac", "Tc", "6h"), WithSeat("Alice", 100, insuring))
+	result, _ = game.PlayRound(10)
+	assert.Equal(t, 17, result.DealerTotal)
+	assert.Equal(t, []float64{5}, result.Net)
+	assert.Equal(t, 105.0, game.Players()[0].Bankroll())
+
+	game, _ = New(stacked("7c", "Ac", "Tc", "Kh"), WithSeat("Alice", 100, insuring), NoInsurance)
+	result, _ = game.PlayRound(10)
+	assert.Equal(t, []float64{0}, result.Insurance)
+	assert.Equal(t, []float64{-10}, result.Net)
+}
+
+func TestSurrender(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		cards   []string
+		options []func(*Options)
+		net     float64
+	}{
+		{"late", []string{"Tc", "Td", "6c", "9h"}, []func(*Options){AllowSurrender(LateSurrender)}, -5},
+		{"early against a blackjack", []string{"Tc", "Ad", "6c", "Kh"}, []func(*Options){AllowSurrender(EarlySurrender)}, -5},
+		{"late without a hole card against a blackjack", []string{"Tc", "Ad", "6c", "Kh"}, []func(*Options){AllowSurrender(LateSurrender), NoHoleCard}, -10},
+		{"early without a hole card against a blackjack", []string{"Tc", "Ad", "6c", "Kh"}, []func(*Options){AllowSurrender(EarlySurrender), NoHoleCard}, -5},
+	} {
+		options := append([]func(*Options){stacked(test.cards...), WithSeat("Alice", 100, script(Surrender)), NoInsurance}, test.options...)
+		game, _ := New(options...)
+		result, err := game.PlayRound(10)
+		assert.Nil(t, err, test.name)
+		assert.Equal(t, []float64{test.net}, result.Net, test.name)
+	}
+
+	// late surrender comes after the peek, so a dealer blackjack takes the whole bet
+	game, _ := New(stacked("Tc", "Ad", "6c", "Kh"), WithSeat("Alice", 100, script(Surrender)), NoInsurance, AllowSurrender(LateSurrender))
+	result, _ := game.PlayRound(10)
+	assert.Nil(t, result.Actions)
+	assert.Equal(t, []float64{-10}, result.Net)
+
+	// early surrender is decided before the peek, declining keeps the hand
+	game, _ = New(stacked("Tc", "Ad", "6c", "7h", "5s"), WithSeat("Alice", 100, standing), NoInsurance, AllowSurrender(EarlySurrender))
+	r, _ := game.Deal(10)
+	assert.Equal(t, SurrenderDecision, r.Phase())
+	assert.True(t, r.State().CanSurrender)
+	assert.Nil(t, r.Act(Hit))
+	assert.Equal(t, PlayDecision, r.Phase())
+	assert.Equal(t, 2, len(r.State().Cards))
+}
+
+func TestRoundSteps(t *testing.T) {
+	game, _ := New(stacked("9c", "Tc", "Ac", "8d", "8c", "6d", "7h"), WithSeat("Alice", 100, standing), WithSeat("Bob", 100, standing))
+	r, err := game.Deal(10, 20)
+	assert.Nil(t, err)
+	assert.Equal(t, InsuranceDecision, r.Phase())
+	assert.Equal(t, "Insurance must be decided first", r.Act(Stand).Error())
+	_, err = game.Deal(10, 20)
+	assert.Equal(t, "The round isn't over", err.Error())
+	assert.Nil(t, r.Insure(false))
+	assert.Nil(t, r.Insure(true))
+	assert.Equal(t, "Insurance isn't offered", r.Insure(true).Error())
+
+	s := r.State()
+	assert.Equal(t, PlayDecision, s.Phase)
+	assert.Equal(t, 0, s.Seat)
+	assert.Equal(t, 17, s.Total)
+	assert.Equal(t, decktest.Cards("Ac")[0], s.Upcard)
+	assert.Nil(t, r.Result())
+	// Alice busts and the decision moves to Bob
+	assert.Nil(t, r.Act(Hit))
+	s = r.State()
+	assert.Equal(t, 1, s.Seat)
+	assert.Equal(t, 18, s.Total)
+	assert.Equal(t, 70.0, s.Bankroll)
+	assert.Nil(t, r.Act(Stand))
+	assert.Equal(t, RoundOver, r.Phase())
+	assert.Equal(t, "The round is over", r.Step().Error())
+
+	// Bob's 18 beats the dealer's soft 17 but his insurance is lost
+	result := r.Result()
+	assert.Equal(t, 24, result.Hands[0].Total)
+	assert.True(t, result.Hands[0].Busted)
+	assert.Equal(t, []float64{0, 10}, result.Insurance)
+	assert.Equal(t, []float64{-10, 20 - 10}, result.Net)
+	assert.Equal(t, []float64{90, 110}, []float64{game.Players()[0].Bankroll(), game.Players()[1].Bankroll()})
+}
+
+func TestDealErrors(t *testing.T) {
+	game, _ := New(WithSeat("Alice", 100, standing), WithSeat("Bob", 100, standing))
+	_, err := game.Deal(10)
+	assert.Equal(t, "Expected 2 bets, one per seat", err.Error())
+	_, err = game.Deal(10, -1)
+	assert.Equal(t, "Invalid bet -1 by Bob", err.Error())
+	_, err = game.Deal(10, 101)
+	assert.Equal(t, "Bob can't cover a bet of 101", err.Error())
+	_, err = game.Deal(0, 0)
+	assert.Equal(t, "No bets were placed", err.Error())
+}
+
+func TestNewErrors(t *testing.T) {
+	_, err := New()
+	assert.Equal(t, "At least one seat is needed", err.Error())
+	_, err = New(WithSeat("Alice", 100, nil))
+	assert.Equal(t, "Seat Alice has no decider", err.Error())
+	_, err = New(WithSeat("Alice", 100, standing), Decks(0))
+	assert.Equal(t, "At least one deck is needed", err.Error())
+	_, err = New(WithSeat("Alice", 100, standing), Penetration(1.5))
+	assert.Equal(t, "Invalid penetration", err.Error())
+	_, err = New(WithSeat("Alice", 100, standing), SplitHands(0))
+	assert.Equal(t, "Invalid split hands", err.Error())
+}
+
+func TestValue(t *testing.T) {
+	for _, test := range []struct {
+		cards []string
+		total int
+		soft  bool
+	}{
+		{[]string{"Ac", "6d"}, 17, true},
+		{[]string{"Ac", "6d", "Kh"}, 17, false},
+		{[]string{"Ac", "Ad"}, 12, true},
+		{[]string{"Jc", "Qd", "2h"}, 22, false},
+		{[]string{"Ac", "Kd"}, 21, true},
+	} {
+		total, soft := Value(decktest.Cards(test.cards...))
+		assert.Equal(t, test.total, total, "%v", test.cards)
+		assert.Equal(t, test.soft, soft, "%v", test.cards)
+	}
+	assert.True(t, IsBlackjack(decktest.Cards("Qc", "Ad")))
+	assert.False(t, IsBlackjack(decktest.Cards("5c", "6d", "Kh")))
+}
+
+func TestRandomPlayKeepsEveryChip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	random := DeciderFuncs(func(s State) Action {
+		for {
+			if a := Action(r.Intn(5)); s.Can(a) || s.Phase == SurrenderDecision {
+				return a
+			}
+		}
+	}, func(s State) bool { return r.Intn(2) == 0 })
+	shuffle := func(d *deck.Deck) {
+		r.Shuffle(len(d.Cards), func(i, j int) { d.Cards[i], d.Cards[j] = d.Cards[j], d.Cards[i] })
+	}
+	for game := 0; game < 20; game++ {
+		var options []func(*Options)
+		for seat := 0; seat < 1+game%7; seat++ {
+			options = append(options, WithSeat(string(rune('A'+seat)), 1000, random))
+		}
+		options = append(options, WithShuffle(shuffle), Decks(1+game%2), AllowSurrender(SurrenderRule(game%3)), SplitHands(4), ResplitAces)
+		if game%2 == 0 {
+			options = append(options, NoHoleCard, HitSoft17)
+		}
+		g, _ := New(options...)
+		for round := 0; round < 100; round++ {
+			before := make([]float64, len(g.Players()))
+			bets := make([]float64, len(g.Players()))
+			for i, p := range g.Players() {
+				before[i] = p.Bankroll()
+				bets[i] = float64(r.Intn(3) * 10)
+			}
+			bets[0] = 10
+			result, err := g.PlayRound(bets...)
+			if !assert.Nil(t, err) {
+				return
+			}
+			sum := make([]float64, len(bets))
+			for _, h := range result.Hands {
+				sum[h.Seat] += h.Net
+			}
+			for i, p := range g.Players() {
+				if result.DealerBlackjack {
+					sum[i] += 2 * result.Insurance[i]
+				} else {
+					sum[i] -= result.Insurance[i]
+				}
+				assert.Equal(t, sum[i], result.Net[i])
+				assert.Equal(t, before[i]+result.Net[i], p.Bankroll())
+			}
+			assert.Equal(t, g.size, len(g.Shoe().Cards)+len(g.discards.Cards))
+		}
+	}
+}
+
+func TestOutOfCards(t *testing.T) {
+	// six cards: Alice keeps hitting until every card is on the table
+	g, _ := New(
+		WithDeck(deck.Unshuffled, deck.WithCards(decktest.Cards("2c", "2d", "2h", "2s", "3c", "3d")...)),
+		WithSeat("Alice", 100, DeciderFuncs(func(s State) Action { return Hit }, nil)),
+		Decks(1),
+	)
+	_, err := g.PlayRound(10)
+	assert.Equal(t, "Every card in the shoe is on the table", err.Error())
+	assert.Equal(t, 100.0, g.Players()[0].Bankroll())
+	assert.Equal(t, RoundOver, g.round.Phase())
+	// no card was made up: the six are back in the shoe and the discards
+	assert.Equal(t, 6, len(g.Shoe().Cards)+len(g.discards.Cards))
+}
+
+func TestShuffleBetweenRounds(t *testing.T) {
+	// the shoe is never shuffled, so nobody is dealt a blackjack that would end the round at once
+	g, _ := New(WithSeat("Alice", 100, standing), Decks(1), Penetration(0.05), WithDeck(deck.Unshuffled), WithShuffle(func(*deck.Deck) {}))
+	assert.True(t, g.ShuffleDue())
+	assert.Nil(t, g.Shuffle())
+	assert.False(t, g.ShuffleDue())
+	round, _ := g.Deal(10)
+	assert.True(t, g.ShuffleDue(), "Four cards are past the cut card")
+	if err := g.Shuffle(); assert.NotNil(t, err) {
+		assert.Equal(t, "The round isn't over", err.Error())
+	}
+	result, _ := round.Play()
+	assert.True(t, result.Shuffled, "The shoe was new")
+	assert.Nil(t, g.Shuffle())
+	assert.Equal(t, 0, g.Dealt())
+	result, _ = g.PlayRound(10)
+	assert.True(t, result.Shuffled)
+	result, _ = g.PlayRound(10)
+	assert.True(t, result.Shuffled)
+}
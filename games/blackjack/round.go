@@ -0,0 +1,458 @@
+package blackjack
+
+import (
+	"errors"
+	"fmt"
+
+	"adamclerk/deck"
+)
+
+// Round is a round being played. It waits for one decision at a time:
+// State describes it, Act and Insure take it, Step asks the player's decider.
+type Round struct {
+	game      *Blackjack
+	dealer    deck.Deck
+	hands     []*hand // in the order they are played, split hands follow the hand they came from
+	insurance []float64
+	phase     Phase
+	current   int // the hand the decision is for
+	result    *RoundResult
+}
+
+// hand is a player's hand in a round
+type hand struct {
+	seat        int
+	cards       deck.Deck
+	bet         float64
+	original    bool // the hand dealt to the seat, not one split from it
+	split       bool
+	splitAces   bool
+	doubled     bool
+	stood       bool
+	surrendered bool
+}
+
+// Phase returns the decision the round is waiting for
+func (r *Round) Phase() Phase {
+	return r.phase
+}
+
+// Upcard returns the dealer's face up card
+func (r *Round) Upcard() deck.Card {
+	return r.dealer.Cards[0]
+}
+
+// Result returns the settled round, nil until the round is over
+func (r *Round) Result() *RoundResult {
+	if r.phase != RoundOver {
+		return nil
+	}
+	return r.result
+}
+
+// State describes the decision the round is waiting for
+func (r *Round) State() State {
+	g := r.game
+	s := State{Phase: r.phase, Upcard: r.Upcard(), Rules: g.rules}
+	if r.phase == RoundOver {
+		return s
+	}
+	h := r.hands[r.current]
+	s.Seat = h.seat
+	s.Hand = r.seatHand(r.current)
+	for _, other := range r.hands {
+		if other.seat == h.seat {
+			s.Hands++
+		}
+	}
+	s.Cards = append([]deck.Card{}, h.cards.Cards...)
+	s.Total, s.Soft = Value(h.cards.Cards)
+	s.Bet = h.bet
+	s.Bankroll = g.players[h.seat].bankroll
+	s.Split = h.split
+	s.unseen = func() []deck.Card {
+		return append(append([]deck.Card{}, g.shoe.Cards...), r.dealer.Cards[1:]...)
+	}
+	switch r.phase {
+	case SurrenderDecision:
+		s.CanSurrender = true
+	case PlayDecision:
+		s.CanHit = r.canHit(h)
+		s.CanDouble = r.canDouble(h)
+		s.CanSplit = r.canSplit(h)
+		s.CanSurrender = g.rules.Surrender != NoSurrender && len(h.cards.Cards) == 2 && !h.split
+	}
+	return s
+}
+
+// Act takes a surrender or play decision for the current hand.
+// On the surrender decision any action but Surrender keeps the hand.
+// If every card is on the table and another is needed, the round is abandoned
+// with every bet returned, and an error is returned.
+func (r *Round) Act(a Action) error {
+	switch r.phase {
+	case InsuranceDecision:
+		return errors.New("Insurance must be decided first")
+	case RoundOver:
+		return errors.New("The round is over")
+	}
+	g := r.game
+	h := r.hands[r.current]
+	p := g.players[h.seat]
+	if r.phase == SurrenderDecision {
+		if a == Surrender {
+			r.record(a)
+			h.surrendered = true
+		}
+		r.current++
+		return r.advance()
+	}
+
+	if reason := r.illegal(a); reason != "" {
+		return fmt.Errorf("Invalid action %s by %s: %s", a, p.name, reason)
+	}
+	r.record(a)
+	var err error
+	switch a {
+	case Hit:
+		err = g.draw(&h.cards)
+	case Stand:
+		h.stood = true
+	case Double:
+		p.bankroll -= h.bet
+		h.bet *= 2
+		h.doubled = true
+		err = g.draw(&h.cards)
+	case Split:
+		p.bankroll -= h.bet
+		aces := h.cards.Cards[0].Face() == int(deck.ACE)
+		second := &hand{seat: h.seat, bet: h.bet, split: true, splitAces: aces}
+		second.cards.Cards = []deck.Card{h.cards.Cards[1]}
+		h.cards.Cards = []deck.Card{h.cards.Cards[0]}
+		h.split, h.splitAces = true, aces
+		r.hands = append(r.hands[:r.current+1], append([]*hand{second}, r.hands[r.current+1:]...)...)
+		err = g.draw(&h.cards)
+	case Surrender:
+		h.surrendered = true
+	}
+	if err != nil {
+		return r.abandon(err)
+	}
+	g.debugf("  %s: %s %v\n", p.name, a, h.cards.Cards)
+	return r.advance()
+}
+
+// Insure takes the insurance decision for the current seat, half its bet
+func (r *Round) Insure(take bool) error {
+	if r.phase != InsuranceDecision {
+		return errors.New("Insurance isn't offered")
+	}
+	h := r.hands[r.current]
+	if take {
+		p := r.game.players[h.seat]
+		p.bankroll -= h.bet / 2
+		r.insurance[h.seat] = h.bet / 2
+		r.result.Insurance[h.seat] = h.bet / 2
+		r.game.debugf("  %s: insures %v\n", p.name, h.bet/2)
+	}
+	r.current++
+	return r.advance()
+}
+
+// Step asks the decider of the player the round is waiting for and takes their decision
+func (r *Round) Step() error {
+	if r.phase == RoundOver {
+		return errors.New("The round is over")
+	}
+	decider := r.game.players[r.hands[r.current].seat].decider
+	if r.phase == InsuranceDecision {
+		return r.Insure(decider.Insurance(r.State()))
+	}
+	return r.Act(decider.Act(r.State()))
+}
+
+// Play steps through the rest of the round and returns it settled
+func (r *Round) Play() (*RoundResult, error) {
+	for r.phase != RoundOver {
+		if err := r.Step(); err != nil {
+			return nil, err
+		}
+	}
+	return r.result, nil
+}
+
+// seatHand returns the position of a hand among its seat's hands
+func (r *Round) seatHand(index int) int {
+	n := 0
+	for _, h := range r.hands[:index] {
+		if h.seat == r.hands[index].seat {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *Round) record(a Action) {
+	h := r.hands[r.current]
+	r.result.Actions = append(r.result.Actions, ActionRecord{Seat: h.seat, Hand: r.seatHand(r.current), Action: a})
+}
+
+func (r *Round) illegal(a Action) string {
+	h := r.hands[r.current]
+	switch a {
+	case Hit:
+		if !r.canHit(h) {
+			return "the hand can't take another card"
+		}
+	case Stand:
+	case Double:
+		if !r.canDouble(h) {
+			return "the hand can't be doubled"
+		}
+	case Split:
+		if !r.canSplit(h) {
+			return "the hand can't be split"
+		}
+	case Surrender:
+		if !r.State().CanSurrender {
+			return "the hand can't be surrendered"
+		}
+	default:
+		return "unknown action"
+	}
+	return ""
+}
+
+func (r *Round) canHit(h *hand) bool {
+	total, _ := Value(h.cards.Cards)
+	return total < 21 && (!h.splitAces || r.game.rules.HitSplitAces)
+}
+
+func (r *Round) canDouble(h *hand) bool {
+	rules := r.game.rules
+	total, _ := Value(h.cards.Cards)
+	return len(h.cards.Cards) == 2 && r.canHit(h) &&
+		(!h.split || rules.DoubleAfterSplit) &&
+		rules.Double.allows(total) &&
+		r.game.players[h.seat].bankroll >= h.bet
+}
+
+func (r *Round) canSplit(h *hand) bool {
+	rules := r.game.rules
+	if len(h.cards.Cards) != 2 || CardValue(h.cards.Cards[0]) != CardValue(h.cards.Cards[1]) {
+		return false
+	}
+	if h.splitAces && !rules.ResplitAces {
+		return false
+	}
+	hands := 0
+	for _, other := range r.hands {
+		if other.seat == h.seat {
+			hands++
+		}
+	}
+	return hands < rules.SplitHands && r.game.players[h.seat].bankroll >= h.bet
+}
+
+func (r *Round) canInsure(h *hand) bool {
+	return r.game.rules.Insurance && r.Upcard().Face() == int(deck.ACE) &&
+		r.game.players[h.seat].bankroll >= h.bet/2
+}
+
+func (r *Round) natural(h *hand) bool {
+	return !h.split && IsBlackjack(h.cards.Cards)
+}
+
+// done tells you if a hand needs no more decisions
+func (r *Round) done(h *hand) bool {
+	if h.stood || h.doubled || h.surrendered || r.natural(h) {
+		return true
+	}
+	if total, _ := Value(h.cards.Cards); total >= 21 {
+		return true
+	}
+	// split aces take one card, unless they can be split again
+	return h.splitAces && !r.game.rules.HitSplitAces && !r.canSplit(h)
+}
+
+// dealerMayHaveBlackjack tells you if the upcard is an ace or a ten
+func (r *Round) dealerMayHaveBlackjack() bool {
+	v := CardValue(r.Upcard())
+	return v == 1 || v == 10
+}
+
+// advance takes the round to its next decision, dealing and settling along the way.
+// If the cards run out the round is abandoned.
+func (r *Round) advance() error {
+	rules := r.game.rules
+	for {
+		switch r.phase {
+		case InsuranceDecision:
+			for r.current < len(r.hands) && !r.canInsure(r.hands[r.current]) {
+				r.current++
+			}
+			if r.current < len(r.hands) {
+				return nil
+			}
+			r.phase, r.current = SurrenderDecision, 0
+		case SurrenderDecision:
+			peeks := !rules.NoHoleCard && r.dealerMayHaveBlackjack()
+			if peeks && rules.Surrender == EarlySurrender {
+				for r.current < len(r.hands) && r.natural(r.hands[r.current]) {
+					r.current++
+				}
+				if r.current < len(r.hands) {
+					return nil
+				}
+			}
+			if peeks && IsBlackjack(r.dealer.Cards) {
+				r.game.debugf("  Dealer has blackjack\n")
+				return r.finish()
+			}
+			r.phase, r.current = PlayDecision, 0
+		case PlayDecision:
+			for ; r.current < len(r.hands); r.current++ {
+				h := r.hands[r.current]
+				if len(h.cards.Cards) < 2 {
+					if err := r.game.draw(&h.cards); err != nil {
+						return r.abandon(err)
+					}
+				}
+				if !r.done(h) {
+					return nil
+				}
+			}
+			return r.finish()
+		default:
+			return nil
+		}
+	}
+}
+
+// finish plays the dealer's hand and settles every bet
+func (r *Round) finish() error {
+	g := r.game
+	rules := g.rules
+	holeCard, live := false, false
+	for _, h := range r.hands {
+		total, _ := Value(h.cards.Cards)
+		if total > 21 || h.surrendered && rules.Surrender == EarlySurrender {
+			continue
+		}
+		holeCard = true
+		live = live || !h.surrendered && !r.natural(h)
+	}
+	for _, ins := range r.insurance {
+		holeCard = holeCard || ins > 0
+	}
+	if len(r.dealer.Cards) < 2 && holeCard {
+		if err := g.draw(&r.dealer); err != nil {
+			return r.abandon(err)
+		}
+	}
+	dealerBlackjack := IsBlackjack(r.dealer.Cards)
+	if live && !dealerBlackjack {
+		for {
+			total, soft := Value(r.dealer.Cards)
+			if total > 17 || total == 17 && !(soft && rules.HitSoft17) {
+				break
+			}
+			if err := g.draw(&r.dealer); err != nil {
+				return r.abandon(err)
+			}
+		}
+	}
+	dealerTotal, _ := Value(r.dealer.Cards)
+	g.debugf("  Dealer has %v, %d\n", r.dealer.Cards, dealerTotal)
+
+	result := r.result
+	result.Dealer = append([]deck.Card{}, r.dealer.Cards...)
+	result.DealerTotal = dealerTotal
+	result.DealerBlackjack = dealerBlackjack
+	for i, h := range r.hands {
+		total, _ := Value(h.cards.Cards)
+		hr := HandResult{
+			Seat:    h.seat,
+			Hand:    r.seatHand(i),
+			Cards:   append([]deck.Card{}, h.cards.Cards...),
+			Total:   total,
+			Bet:     h.bet,
+			Split:   h.split,
+			Doubled: h.doubled,
+			Busted:  total > 21,
+		}
+		switch {
+		case h.surrendered && dealerBlackjack && rules.NoHoleCard && rules.Surrender == LateSurrender:
+			hr.Outcome, hr.Net = Lost, -h.bet
+		case h.surrendered:
+			hr.Outcome, hr.Net = Surrendered, -h.bet/2
+		case r.natural(h) && dealerBlackjack:
+			hr.Outcome = Pushed
+		case r.natural(h):
+			hr.Outcome, hr.Net = WonBlackjack, h.bet*rules.Blackjack.Ratio()
+		case total > 21:
+			hr.Outcome, hr.Net = Lost, -h.bet
+		case dealerBlackjack:
+			lost := h.bet
+			if rules.OriginalBetsOnly {
+				lost = 0
+				if h.original {
+					lost = result.Bets[h.seat]
+				}
+			}
+			hr.Outcome, hr.Net = Lost, -lost
+			if lost == 0 {
+				hr.Outcome = Pushed
+			}
+		case dealerTotal > 21 || total > dealerTotal:
+			hr.Outcome, hr.Net = Won, h.bet
+		case total == dealerTotal:
+			hr.Outcome = Pushed
+		default:
+			hr.Outcome, hr.Net = Lost, -h.bet
+		}
+		g.players[h.seat].bankroll += h.bet + hr.Net
+		result.Net[h.seat] += hr.Net
+		result.Hands = append(result.Hands, hr)
+	}
+	for seat, ins := range r.insurance {
+		if ins == 0 {
+			continue
+		}
+		if dealerBlackjack {
+			g.players[seat].bankroll += 3 * ins
+			result.Net[seat] += 2 * ins
+		} else {
+			result.Net[seat] -= ins
+		}
+	}
+	r.discard()
+	r.phase = RoundOver
+	return nil
+}
+
+// abandon ends a round that can't be played out: every bet and insurance goes back
+// to its player, the cards on the table go to the discards, and err is returned
+func (r *Round) abandon(err error) error {
+	for _, h := range r.hands {
+		r.game.players[h.seat].bankroll += h.bet
+	}
+	for seat, ins := range r.insurance {
+		r.game.players[seat].bankroll += ins
+	}
+	r.discard()
+	r.phase = RoundOver
+	return err
+}
+
+// discard moves the cards on the table to the discards, the dealer's hole card turned over
+func (r *Round) discard() {
+	for _, c := range r.game.counters {
+		c.turnOver()
+	}
+	discards := r.game.discards
+	for _, h := range r.hands {
+		discards.Cards = append(discards.Cards, h.cards.Cards...)
+	}
+	discards.Cards = append(discards.Cards, r.dealer.Cards...)
+}
@@ -0,0 +1,154 @@
+package blackjack
+
+import (
+	"errors"
+	"fmt"
+
+	"adamclerk/deck"
+)
+
+// DoubleRule is which two card hands may be doubled
+type DoubleRule int
+
+// Constants for DoubleRule
+const (
+	DoubleAnyTwo DoubleRule = iota
+	DoubleNineToEleven
+	DoubleTenToEleven
+)
+
+func (d DoubleRule) String() string {
+	names := []string{"DoubleAnyTwo", "DoubleNineToEleven", "DoubleTenToEleven"}
+	if d < 0 || int(d) >= len(names) {
+		return fmt.Sprintf("DoubleRule(%d)", int(d))
+	}
+	return names[d]
+}
+
+// allows tells you if a two card hand can be doubled
+func (d DoubleRule) allows(total int) bool {
+	switch d {
+	case DoubleNineToEleven:
+		return total >= 9 && total <= 11
+	case DoubleTenToEleven:
+		return total >= 10 && total <= 11
+	}
+	return true
+}
+
+// SurrenderRule is when a player may give up half their bet
+type SurrenderRule int
+
+// Constants for SurrenderRule
+const (
+	NoSurrender SurrenderRule = iota
+	// LateSurrender is offered after the dealer checks for blackjack.
+	// Without a hole card a surrendered hand still loses its whole bet to a dealer blackjack.
+	LateSurrender
+	// EarlySurrender is offered before the dealer checks for blackjack
+	EarlySurrender
+)
+
+func (s SurrenderRule) String() string {
+	names := []string{"NoSurrender", "LateSurrender", "EarlySurrender"}
+	if s < 0 || int(s) >= len(names) {
+		return fmt.Sprintf("SurrenderRule(%d)", int(s))
+	}
+	return names[s]
+}
+
+// Payout is what a blackjack pays, Win for every Stake bet
+type Payout struct {
+	Win   int
+	Stake int
+}
+
+// Common blackjack payouts
+var (
+	ThreeToTwo = Payout{3, 2}
+	SixToFive  = Payout{6, 5}
+	EvenMoney  = Payout{1, 1}
+)
+
+// Ratio is the payout as a multiple of the bet
+func (p Payout) Ratio() float64 {
+	return float64(p.Win) / float64(p.Stake)
+}
+
+func (p Payout) String() string {
+	return fmt.Sprintf("%d:%d", p.Win, p.Stake)
+}
+
+// Rules are the table rules
+type Rules struct {
+	Decks            int
+	HitSoft17        bool // H17, the dealer stands on soft 17 when false
+	NoHoleCard       bool // European: the dealer takes a second card after the players and doesn't peek
+	OriginalBetsOnly bool // without a hole card, a dealer blackjack only takes the original bets
+	Double           DoubleRule
+	DoubleAfterSplit bool
+	SplitHands       int // hands a player can split to, 1 forbids splitting
+	ResplitAces      bool
+	HitSplitAces     bool
+	Surrender        SurrenderRule
+	Insurance        bool
+	Blackjack        Payout
+	Penetration      float64 // the share of the shoe dealt before it is shuffled
+}
+
+// DefaultRules are the rules of a common six deck shoe game: the dealer stands on soft 17 and peeks,
+// double on any two cards and after splits, split to four hands, insurance and blackjack pays 3:2.
+func DefaultRules() Rules {
+	return Rules{
+		Decks:            6,
+		DoubleAfterSplit: true,
+		SplitHands:       4,
+		Insurance:        true,
+		Blackjack:        ThreeToTwo,
+		Penetration:      0.75,
+	}
+}
+
+func (r Rules) validate() error {
+	if r.Decks < 1 {
+		return errors.New("At least one deck is needed")
+	}
+	if r.SplitHands < 1 {
+		return errors.New("Invalid split hands")
+	}
+	if r.Blackjack.Win < 0 || r.Blackjack.Stake <= 0 {
+		return errors.New("Invalid blackjack payout")
+	}
+	if r.Penetration <= 0 || r.Penetration > 1 {
+		return errors.New("Invalid penetration")
+	}
+	return nil
+}
+
+// CardValue is the blackjack value of a card: aces count 1, tens and pictures 10
+func CardValue(c deck.Card) int {
+	if c.Face() >= int(deck.TEN) {
+		return 10
+	}
+	return c.Face() + 1
+}
+
+// Value totals a hand. An ace counts 11 when that doesn't bust the hand, the hand is then soft.
+func Value(cards []deck.Card) (total int, soft bool) {
+	ace := false
+	for _, c := range cards {
+		total += CardValue(c)
+		ace = ace || c.Face() == int(deck.ACE)
+	}
+	if ace && total+10 <= 21 {
+		return total + 10, true
+	}
+	return total, false
+}
+
+// IsBlackjack tells you if the cards are a natural: an ace and a ten valued card.
+// A split hand making 21 with two cards isn't a blackjack.
+func IsBlackjack(cards []deck.Card) bool {
+	total, _ := Value(cards)
+	return len(cards) == 2 && total == 21
+}
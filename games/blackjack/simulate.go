@@ -0,0 +1,221 @@
+package blackjack
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sync"
+
+	"adamclerk/deck"
+	"adamclerk/deck/internal/splitmix"
+)
+
+// blockRounds is how many rounds a worker plays from one random source
+const blockRounds = 10000
+
+// z95 is the normal quantile for 95% confidence intervals
+const z95 = 1.959964
+
+// Simulation describes a simulator run: one player at the table, betting on the count.
+// Rounds are played in blocks of 10,000, each from a new shoe shuffled by its own random source
+// seeded from Seed and the block number. Workers take the blocks in turn and the results are added up
+// in block order, so a seed gives the same result whatever the number of workers.
+// The workers call Bet and Strategy at the same time, so both must be safe for concurrent use;
+// each Decider returned by Strategy is only used by the worker it was made for.
+type Simulation struct {
+	Rules    Rules
+	Rounds   int
+	Workers  int   // the number of CPUs when 0
+	Seed     int64 // a run is reproducible from its seed
+	System   System
+	Bet      func(c *Counter) float64 // the bet, in units, before each round, above 0; a flat bet of 1 when nil
+	Strategy func(c *Counter) Decider // called for each block; basic strategy for the rules when nil
+	Bankroll float64                  // in units, for the risk of ruin
+}
+
+// Estimate is a simulated figure with its 95% confidence interval
+type Estimate struct {
+	Value, Low, High float64
+}
+
+// SimulationResult is what a simulation found. Money is in units.
+type SimulationResult struct {
+	Rounds     int
+	Wagered    float64  // the initial bets, without doubles and splits
+	Net        float64  // what the player won, less what they lost
+	EV         Estimate // the player's expected win per round
+	SD         Estimate // the standard deviation of a round
+	HouseEdge  Estimate // what the house keeps of the initial bets; below zero the player has the edge
+	RiskOfRuin Estimate // the chance of losing the bankroll for good, 1 without an edge
+	N0         Estimate // the rounds it takes for the expected win to equal one standard deviation
+}
+
+// BetRamp returns a bet spread keyed to the true count, rounded down: the first bet is for a true count
+// of 1 or less, the next for 2, and so on, the last for any count above. Unbalanced systems, such as KO,
+// are keyed to the running count instead.
+func BetRamp(bets ...float64) func(*Counter) float64 {
+	return func(c *Counter) float64 {
+		count := c.TrueCount()
+		if !c.System().Balanced() {
+			count = c.RunningCount()
+		}
+		i := int(math.Floor(count)) - 1
+		if i < 0 {
+			i = 0
+		}
+		if i >= len(bets) {
+			i = len(bets) - 1
+		}
+		return bets[i]
+	}
+}
+
+// block is the sums kept for a block of rounds
+type block struct {
+	rounds              int
+	wagered, net, netSq float64
+	err                 error
+}
+
+// Simulate plays the simulation
+func Simulate(sim Simulation) (*SimulationResult, error) {
+	if sim.Rounds < 1 {
+		return nil, errors.New("At least one round is needed")
+	}
+	if err := sim.Rules.validate(); err != nil {
+		return nil, err
+	}
+	if sim.Bankroll < 0 {
+		return nil, errors.New("Invalid bankroll")
+	}
+	if sim.System.Name == "" {
+		sim.System = HiLo
+	}
+	if sim.Bet == nil {
+		sim.Bet = func(*Counter) float64 { return 1 }
+	}
+	if sim.Strategy == nil {
+		chart := BasicStrategy(sim.Rules)
+		sim.Strategy = func(*Counter) Decider { return chart }
+	}
+	// the bet off the top of a new shoe
+	shoe, err := deck.New(deck.Decks(sim.Rules.Decks), deck.Unshuffled)
+	if err != nil {
+		return nil, err
+	}
+	if bet := sim.Bet(NewCounter(sim.System, shoe)); !(bet > 0) {
+		return nil, fmt.Errorf("Invalid bet %v", bet)
+	}
+	workers := sim.Workers
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+
+	blocks := make([]block, (sim.Rounds+blockRounds-1)/blockRounds)
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				rounds := blockRounds
+				if i == len(blocks)-1 {
+					rounds = sim.Rounds - i*blockRounds
+				}
+				blocks[i] = sim.play(i, rounds)
+			}
+		}()
+	}
+	for i := range blocks {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	result := &SimulationResult{}
+	netSq := 0.0
+	for _, b := range blocks {
+		if b.err != nil {
+			return nil, b.err
+		}
+		result.Rounds += b.rounds
+		result.Wagered += b.wagered
+		result.Net += b.net
+		netSq += b.netSq
+	}
+	n := float64(result.Rounds)
+	mean := result.Net / n
+	variance := netSq/n - mean*mean
+	if variance < 0 {
+		variance = 0
+	}
+	sd := math.Sqrt(variance)
+	margin := z95 * sd / math.Sqrt(n)
+	result.EV = Estimate{mean, mean - margin, mean + margin}
+	result.SD = Estimate{sd, sd * (1 - z95/math.Sqrt(2*n)), sd * (1 + z95/math.Sqrt(2*n))}
+	bet := result.Wagered / n
+	result.HouseEdge = Estimate{-mean / bet, -(mean + margin) / bet, -(mean - margin) / bet}
+	ruin := func(ev float64) float64 {
+		if ev <= 0 {
+			return 1
+		}
+		return math.Exp(-2 * ev * sim.Bankroll / variance)
+	}
+	result.RiskOfRuin = Estimate{ruin(mean), ruin(mean + margin), ruin(mean - margin)}
+	n0 := func(ev float64) float64 {
+		if ev == 0 {
+			return math.Inf(1)
+		}
+		return variance / (ev * ev)
+	}
+	low, high := n0(mean+margin), n0(mean-margin)
+	if low > high {
+		low, high = high, low
+	}
+	if mean-margin <= 0 && mean+margin >= 0 {
+		high = math.Inf(1)
+	}
+	result.N0 = Estimate{n0(mean), low, high}
+	return result, nil
+}
+
+// play plays a block of rounds from a new table
+func (sim Simulation) play(number, rounds int) block {
+	r := rand.New(rand.NewSource(splitmix.Mix(sim.Seed, number)))
+	shuffle := func(d *deck.Deck) {
+		r.Shuffle(len(d.Cards), func(i, j int) { d.Cards[i], d.Cards[j] = d.Cards[j], d.Cards[i] })
+	}
+	counter := NewCounter(sim.System, nil)
+	g, err := New(
+		WithRules(sim.Rules),
+		WithShuffle(shuffle),
+		WithSeat("Player", math.Inf(1), sim.Strategy(counter)),
+		CountWith(counter),
+	)
+	if err != nil {
+		return block{err: err}
+	}
+	b := block{rounds: rounds}
+	for i := 0; i < rounds; i++ {
+		if g.ShuffleDue() {
+			if err := g.Shuffle(); err != nil {
+				return block{err: err}
+			}
+		}
+		bet := sim.Bet(counter)
+		if !(bet > 0) {
+			return block{err: fmt.Errorf("Invalid bet %v", bet)}
+		}
+		result, err := g.PlayRound(bet)
+		if err != nil {
+			return block{err: err}
+		}
+		b.wagered += bet
+		b.net += result.Net[0]
+		b.netSq += result.Net[0] * result.Net[0]
+	}
+	return b
+}
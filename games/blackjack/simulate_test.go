@@ -0,0 +1,125 @@
+package blackjack
+
+import (
+	"math"
+	"testing"
+
+	"adamclerk/deck"
+	"adamclerk/deck/internal/decktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimulateIsReproducible(t *testing.T) {
+	sim := Simulation{Rules: DefaultRules(), Rounds: 25000, Seed: 42, Bankroll: 500, Bet: BetRamp(1, 2, 4, 8), Workers: 1}
+	one, err := Simulate(sim)
+	if !assert.Nil(t, err) {
+		return
+	}
+	sim.Workers = 3
+	three, err := Simulate(sim)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, one, three)
+	assert.Equal(t, 25000, one.Rounds)
+	assert.True(t, one.Wagered > 25000, "The spread raises some bets")
+
+	sim.Seed = 43
+	other, _ := Simulate(sim)
+	assert.NotEqual(t, one.Net, other.Net)
+}
+
+func TestSimulateEstimates(t *testing.T) {
+	result, err := Simulate(Simulation{Rules: DefaultRules(), Rounds: 20000, Seed: 1, Bankroll: 1000})
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, 20000.0, result.Wagered)
+	for _, e := range []Estimate{result.EV, result.SD, result.HouseEdge, result.N0} {
+		assert.True(t, e.Low <= e.Value && e.Value <= e.High, "%+v", e)
+	}
+	assert.Equal(t, -result.EV.Value, result.HouseEdge.Value)
+	assert.InDelta(t, 1.15, result.SD.Value, 0.05)
+	assert.InDelta(t, 2*1.96*1.15/math.Sqrt(20000), result.HouseEdge.High-result.HouseEdge.Low, 0.002)
+	if result.EV.Value < 0 {
+		assert.Equal(t, 1.0, result.RiskOfRuin.Value)
+	}
+	assert.InEpsilon(t, result.SD.Value*result.SD.Value/(result.EV.Value*result.EV.Value), result.N0.Value, 1e-9)
+}
+
+func TestSimulateWithStrategy(t *testing.T) {
+	counters := 0
+	result, err := Simulate(Simulation{
+		Rules:  DefaultRules(),
+		Rounds: 20000,
+		System: KO,
+		Strategy: func(c *Counter) Decider {
+			counters++
+			assert.Equal(t, KO, c.System())
+			return standing
+		},
+		Workers: 1,
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, 2, counters, "One strategy per block")
+	assert.True(t, result.HouseEdge.Value > 0.1, "Never drawing gives the house a big edge")
+}
+
+func TestSimulateErrors(t *testing.T) {
+	_, err := Simulate(Simulation{Rules: DefaultRules()})
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "At least one round is needed", err.Error())
+	}
+	rules := DefaultRules()
+	rules.Decks = 0
+	_, err = Simulate(Simulation{Rules: rules, Rounds: 10})
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "At least one deck is needed", err.Error())
+	}
+	_, err = Simulate(Simulation{Rules: DefaultRules(), Rounds: 10, Bankroll: -1})
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "Invalid bankroll", err.Error())
+	}
+	_, err = Simulate(Simulation{Rules: DefaultRules(), Rounds: 10, Bet: BetRamp(0)})
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "Invalid bet 0", err.Error())
+	}
+	_, err = Simulate(Simulation{Rules: DefaultRules(), Rounds: 10, Bet: BetRamp(-1)})
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "Invalid bet -1", err.Error())
+	}
+	// a bet that goes wrong later in the shoe
+	_, err = Simulate(Simulation{Rules: DefaultRules(), Rounds: 100, Bet: func(c *Counter) float64 {
+		if c.Seen() > 0 {
+			return -2
+		}
+		return 1
+	}})
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "Invalid bet -2", err.Error())
+	}
+}
+
+func TestBetRamp(t *testing.T) {
+	ramp := BetRamp(1, 2, 4, 8)
+	shoe, _ := deck.New(deck.Unshuffled)
+	c := NewCounter(HiLo, shoe)
+	assert.Equal(t, 1.0, ramp(c))
+	// the ace to six of clubs count +4 with 46 cards left, a true count of 4.5
+	hand, _ := deck.New(deck.Empty)
+	shoe.Deal(6, hand)
+	assert.Equal(t, 8.0, ramp(c))
+	// the seven to king of clubs bring it down to 0
+	shoe.Deal(7, hand)
+	assert.Equal(t, 1.0, ramp(c))
+	c.Count(decktest.Cards("2c", "3c")...)
+	assert.InDelta(t, 2/(39.0/52), c.TrueCount(), 1e-9)
+	assert.Equal(t, 2.0, ramp(c))
+
+	// KO is keyed to the running count
+	ko := NewCounter(KO, shoe)
+	ko.Count(decktest.Cards("2c", "3c")...)
+	assert.Equal(t, 2.0, ramp(ko))
+}
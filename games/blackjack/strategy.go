@@ -0,0 +1,480 @@
+package blackjack
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"adamclerk/deck"
+)
+
+// Play is an entry in a strategy chart
+type Play int
+
+// Constants for Play, with their usual chart codes
+const (
+	NoPlay           Play = iota // a pair that isn't split is played by its total
+	PlayHit                      // H
+	PlayStand                    // S
+	DoubleOrHit                  // Dh
+	DoubleOrStand                // Ds
+	PlaySplit                    // P
+	SplitIfDAS                   // Ph: split when doubling after a split is allowed, hit otherwise
+	SurrenderOrHit               // Rh
+	SurrenderOrStand             // Rs
+	SurrenderOrSplit             // Rp
+)
+
+var playCodes = []string{"-", "H", "S", "Dh", "Ds", "P", "Ph", "Rh", "Rs", "Rp"}
+
+func (p Play) String() string {
+	if p < 0 || int(p) >= len(playCodes) {
+		return fmt.Sprintf("Play(%d)", int(p))
+	}
+	return playCodes[p]
+}
+
+// ParsePlay reads a chart code. D is read as Dh, and - or nothing as NoPlay.
+func ParsePlay(code string) (Play, error) {
+	switch strings.ToUpper(strings.TrimSpace(code)) {
+	case "", "-":
+		return NoPlay, nil
+	case "D":
+		return DoubleOrHit, nil
+	}
+	for i, c := range playCodes {
+		if strings.EqualFold(c, strings.TrimSpace(code)) {
+			return Play(i), nil
+		}
+	}
+	return NoPlay, fmt.Errorf("Unknown play %s", code)
+}
+
+// splits tells you if the play belongs in the pairs table
+func (p Play) splits() bool {
+	return p == PlaySplit || p == SplitIfDAS || p == SurrenderOrSplit
+}
+
+// Chart is a basic strategy chart: what to do with each hand against each upcard.
+// Columns are the upcards 2 to 10, then the ace.
+type Chart struct {
+	Name  string
+	Hard  [22][10]Play // by total, 4 to 21
+	Soft  [22][10]Play // by total, 12 to 21
+	Pairs [11][10]Play // by the value of the pair, 1 for aces
+}
+
+// column returns the chart column for an upcard
+func column(upcard deck.Card) int {
+	if v := CardValue(upcard); v > 1 {
+		return v - 2
+	}
+	return 9
+}
+
+// NewChart returns a chart that hits up to hard 16 and soft 17, stands on the rest and never splits
+func NewChart(name string) *Chart {
+	c := &Chart{Name: name}
+	for col := 0; col < 10; col++ {
+		for total := 0; total < 22; total++ {
+			c.Hard[total][col], c.Soft[total][col] = PlayHit, PlayHit
+			if total >= 17 {
+				c.Hard[total][col] = PlayStand
+			}
+			if total >= 18 {
+				c.Soft[total][col] = PlayStand
+			}
+		}
+	}
+	return c
+}
+
+// Lookup returns the chart entry for a hand. Pairs that can't be split are looked up by their total.
+func (c *Chart) Lookup(cards []deck.Card, upcard deck.Card, canSplit bool) Play {
+	col := column(upcard)
+	if canSplit && len(cards) == 2 && CardValue(cards[0]) == CardValue(cards[1]) {
+		if p := c.Pairs[CardValue(cards[0])][col]; p != NoPlay {
+			return p
+		}
+	}
+	total, soft := Value(cards)
+	if total > 21 {
+		return PlayStand
+	}
+	if soft {
+		return c.Soft[total][col]
+	}
+	return c.Hard[total][col]
+}
+
+// Act plays the chart: entries that can't be taken fall back to the second action of their code.
+// On the surrender decision it returns Surrender or Stand, which keeps the hand.
+func (c *Chart) Act(state State) Action {
+	canSplit := state.CanSplit || state.Phase == SurrenderDecision
+	play := c.Lookup(state.Cards, state.Upcard, canSplit)
+	if play == SplitIfDAS {
+		play = PlaySplit
+		if !state.Rules.DoubleAfterSplit {
+			play = c.Lookup(state.Cards, state.Upcard, false)
+		}
+	}
+	if state.Phase == SurrenderDecision {
+		if play == SurrenderOrHit || play == SurrenderOrStand || play == SurrenderOrSplit {
+			return Surrender
+		}
+		return Stand
+	}
+	hit := Stand
+	if state.CanHit {
+		hit = Hit
+	}
+	switch play {
+	case PlayHit:
+		return hit
+	case DoubleOrHit, DoubleOrStand:
+		if state.CanDouble {
+			return Double
+		}
+		if play == DoubleOrHit {
+			return hit
+		}
+	case PlaySplit:
+		return Split
+	case SurrenderOrHit, SurrenderOrStand, SurrenderOrSplit:
+		if state.CanSurrender {
+			return Surrender
+		}
+		if play == SurrenderOrHit {
+			return hit
+		}
+		if play == SurrenderOrSplit {
+			return Split
+		}
+	}
+	return Stand
+}
+
+// Insurance is never taken by basic strategy
+func (c *Chart) Insurance(state State) bool {
+	return false
+}
+
+// Recommend returns the basic strategy action for a hand just dealt, from the built in chart for the rules
+func Recommend(cards []deck.Card, upcard deck.Card, rules Rules) Action {
+	return BasicStrategy(rules).Act(dealtState(cards, upcard, rules))
+}
+
+// dealtState is the state of a hand just dealt, before any decision
+func dealtState(cards []deck.Card, upcard deck.Card, rules Rules) State {
+	total, soft := Value(cards)
+	return State{
+		Phase:        PlayDecision,
+		Hands:        1,
+		Cards:        cards,
+		Total:        total,
+		Soft:         soft,
+		Upcard:       upcard,
+		Bet:          1,
+		Bankroll:     1,
+		CanHit:       total < 21,
+		CanDouble:    len(cards) == 2 && total < 21 && rules.Double.allows(total),
+		CanSplit:     len(cards) == 2 && CardValue(cards[0]) == CardValue(cards[1]) && rules.SplitHands > 1,
+		CanSurrender: len(cards) == 2 && rules.Surrender != NoSurrender,
+		Rules:        rules,
+	}
+}
+
+// setRow sets a row of a chart from its label: a hard total (9), a range of them (5-8, 17+),
+// a soft hand (A7 or A,7) or a pair (88, TT, 10,10, AA).
+func (c *Chart) setRow(label string, plays []Play, set map[string]bool) error {
+	name := strings.ToUpper(strings.Replace(strings.Replace(label, ",", "", -1), " ", "", -1))
+	if name == "1010" {
+		name = "TT"
+	}
+	values := "A23456789T"
+	var table *[22][10]Play
+	var rows []int
+	kind := "Hard"
+	switch {
+	case len(name) == 2 && name[0] == name[1] && strings.IndexByte(values, name[0]) >= 0:
+		kind = "Pair"
+		rows = []int{strings.IndexByte(values, name[0]) + 1}
+	case len(name) == 2 && name[0] == 'A' && name[1] >= '2' && name[1] <= '9':
+		kind, table = "Soft", &c.Soft
+		rows = []int{int(name[1]-'0') + 11}
+	default:
+		table = &c.Hard
+		from, to := name, name
+		if strings.HasSuffix(name, "+") {
+			from, to = strings.TrimSuffix(name, "+"), "21"
+		} else if i := strings.Index(name, "-"); i > 0 {
+			from, to = name[:i], name[i+1:]
+		}
+		low, err := strconv.Atoi(from)
+		high, err2 := strconv.Atoi(to)
+		if err != nil || err2 != nil || low < 4 || high > 21 || low > high {
+			return fmt.Errorf("Unknown row %s", label)
+		}
+		for total := low; total <= high; total++ {
+			rows = append(rows, total)
+		}
+	}
+	for _, row := range rows {
+		key := fmt.Sprintf("%s %d", kind, row)
+		if set[key] {
+			return fmt.Errorf("Row %s is given twice", label)
+		}
+		set[key] = true
+		for col, p := range plays {
+			if kind == "Pair" {
+				c.Pairs[row][col] = p
+				continue
+			}
+			if p == NoPlay || p.splits() {
+				return fmt.Errorf("Invalid play %s in row %s", p, label)
+			}
+			table[row][col] = p
+		}
+	}
+	return nil
+}
+
+// parseUpcards reads the column headings, in any order
+func parseUpcards(headings []string) ([]int, error) {
+	if len(headings) != 10 {
+		return nil, fmt.Errorf("Expected 10 upcards, got %d", len(headings))
+	}
+	var columns []int
+	seen := map[int]bool{}
+	for _, h := range headings {
+		col := -1
+		switch strings.ToUpper(strings.TrimSpace(h)) {
+		case "A", "1", "11":
+			col = 9
+		case "T", "10":
+			col = 8
+		default:
+			if v, err := strconv.Atoi(strings.TrimSpace(h)); err == nil && v >= 2 && v <= 9 {
+				col = v - 2
+			}
+		}
+		if col < 0 || seen[col] {
+			return nil, fmt.Errorf("Invalid upcard %s", h)
+		}
+		seen[col] = true
+		columns = append(columns, col)
+	}
+	return columns, nil
+}
+
+// fill reads rows of plays into a chart, starting from NewChart
+func fill(name string, columns []int, rows [][]string) (*Chart, error) {
+	c := NewChart(name)
+	set := map[string]bool{}
+	for _, row := range rows {
+		if len(row) != len(columns)+1 {
+			return nil, fmt.Errorf("Row %s has %d plays, expected %d", row[0], len(row)-1, len(columns))
+		}
+		plays := make([]Play, 10)
+		for i, code := range row[1:] {
+			p, err := ParsePlay(code)
+			if err != nil {
+				return nil, err
+			}
+			plays[columns[i]] = p
+		}
+		if err := c.setRow(row[0], plays, set); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// ReadChartCSV reads a chart. The first row heads the upcard columns (2 to 10 and A, in any order)
+// after a cell naming the chart; every other row is a hand and its plays. Hands left out hit up to
+// hard 16 and soft 17 and stand on the rest; pairs left out are played by their total.
+func ReadChartCSV(r io.Reader) (*Chart, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("The chart is empty")
+	}
+	columns, err := parseUpcards(records[0][1:])
+	if err != nil {
+		return nil, err
+	}
+	return fill(records[0][0], columns, records[1:])
+}
+
+// chartJSON is a chart as JSON: rows are keyed by hand, as in a CSV chart.
+// Upcards default to 2 to 10 then A.
+type chartJSON struct {
+	Name    string              `json:"name"`
+	Upcards []string            `json:"upcards,omitempty"`
+	Rows    map[string][]string `json:"rows"`
+}
+
+// ReadChartJSON reads a chart such as {"name": "...", "rows": {"16": ["S", "S", "S", "S", "S", "H", "H", "Rh", "Rh", "Rh"], ...}}.
+// An "upcards" list can change the column order.
+func ReadChartJSON(r io.Reader) (*Chart, error) {
+	var j chartJSON
+	if err := json.NewDecoder(r).Decode(&j); err != nil {
+		return nil, err
+	}
+	if j.Upcards == nil {
+		j.Upcards = []string{"2", "3", "4", "5", "6", "7", "8", "9", "10", "A"}
+	}
+	columns, err := parseUpcards(j.Upcards)
+	if err != nil {
+		return nil, err
+	}
+	var rows [][]string
+	for label, plays := range j.Rows {
+		rows = append(rows, append([]string{label}, plays...))
+	}
+	return fill(j.Name, columns, rows)
+}
+
+// WriteCSV writes the chart in the form ReadChartCSV reads: hard 5 to 20, soft 13 to 20 and the pairs
+func (c *Chart) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{c.Name, "2", "3", "4", "5", "6", "7", "8", "9", "10", "A"})
+	row := func(label string, plays [10]Play) {
+		record := []string{label}
+		for _, p := range plays {
+			record = append(record, p.String())
+		}
+		writer.Write(record)
+	}
+	for total := 5; total <= 20; total++ {
+		row(strconv.Itoa(total), c.Hard[total])
+	}
+	for total := 13; total <= 20; total++ {
+		row("A"+strconv.Itoa(total-11), c.Soft[total])
+	}
+	for v := 2; v <= 10; v++ {
+		row(strings.Repeat(string("A23456789T"[v-1]), 2), c.Pairs[v])
+	}
+	row("AA", c.Pairs[1])
+	writer.Flush()
+	return writer.Error()
+}
+
+// DeriveChart works out a chart for the rules from the cards left in a shoe. Each total is played
+// the way that's best on average over the two card hands making it, weighted by how likely they are,
+// leaving pairs to the pairs table. It's a composition dependent strategy for the shoe as it stands.
+func DeriveChart(shoe *deck.Deck, rules Rules) *Chart {
+	c := NewChart("Derived")
+	full := NewComposition(shoe.Cards)
+	for up := 1; up <= 10; up++ {
+		if full[up] == 0 {
+			continue
+		}
+		col := column(valueCard(up))
+		calc := newCalculator(rules, up)
+		comp := full
+		comp[up]--
+		n := float64(comp.Cards())
+		hard := map[int]map[Action]float64{}
+		soft := map[int]map[Action]float64{}
+		for a := 1; a <= 10; a++ {
+			for b := a; b <= 10; b++ {
+				p := float64(comp[a]) / n * float64(comp[b]) / (n - 1)
+				if a == b {
+					p = float64(comp[a]) / n * float64(comp[b]-1) / (n - 1)
+				} else {
+					p *= 2
+				}
+				cards := []deck.Card{valueCard(a), valueCard(b)}
+				if p <= 0 || IsBlackjack(cards) {
+					continue
+				}
+				state := dealtState(cards, valueCard(up), rules)
+				evs := calc.actions(state, comp.Without(cards...))
+				if a == b {
+					c.Pairs[a][col] = pairPlay(evs)
+					if a != 2 && a != 10 && a != 1 {
+						continue
+					}
+					// hard 4, hard 20 and soft 12 are only ever pairs
+					delete(evs, Split)
+				}
+				totals := hard
+				if state.Soft {
+					totals = soft
+				}
+				if totals[state.Total] == nil {
+					totals[state.Total] = map[Action]float64{}
+				}
+				for action, ev := range evs {
+					totals[state.Total][action] += p * ev
+				}
+			}
+		}
+		for total, evs := range hard {
+			c.Hard[total][col] = totalPlay(evs)
+		}
+		for total, evs := range soft {
+			c.Soft[total][col] = totalPlay(evs)
+		}
+	}
+	return c
+}
+
+// valueCard returns a card of the blackjack value
+func valueCard(v int) deck.Card {
+	if v == 10 {
+		return deck.NewCard(deck.TEN, deck.SPADE)
+	}
+	return deck.NewCard(deck.Face(v-1), deck.SPADE)
+}
+
+// totalPlay turns expected values into a chart entry with its fallback
+func totalPlay(evs map[Action]float64) Play {
+	fallback := Stand
+	if ev, ok := evs[Hit]; ok && ev > evs[Stand] {
+		fallback = Hit
+	}
+	switch best(evs) {
+	case Hit:
+		return PlayHit
+	case Double:
+		if fallback == Hit {
+			return DoubleOrHit
+		}
+		return DoubleOrStand
+	case Surrender:
+		if fallback == Hit {
+			return SurrenderOrHit
+		}
+		return SurrenderOrStand
+	}
+	return PlayStand
+}
+
+// pairPlay is the pairs table entry: split, or surrender else split, or play the total
+func pairPlay(evs map[Action]float64) Play {
+	switch best(evs) {
+	case Split:
+		return PlaySplit
+	case Surrender:
+		others := map[Action]float64{}
+		for a, ev := range evs {
+			if a != Surrender {
+				others[a] = ev
+			}
+		}
+		if best(others) == Split {
+			return SurrenderOrSplit
+		}
+	}
+	return NoPlay
+}
@@ -0,0 +1,270 @@
+package blackjack
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"adamclerk/deck"
+	"adamclerk/deck/internal/decktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func rules(decks int, options ...func(*Rules)) Rules {
+	r := DefaultRules()
+	r.Decks = decks
+	r.Surrender = LateSurrender
+	for _, option := range options {
+		option(&r)
+	}
+	return r
+}
+
+func h17(r *Rules)   { r.HitSoft17 = true }
+func noDAS(r *Rules) { r.DoubleAfterSplit = false }
+func noLS(r *Rules)  { r.Surrender = NoSurrender }
+
+func TestRecommend(t *testing.T) {
+	tests := []struct {
+		cards  []string
+		upcard string
+		rules  Rules
+		action Action
+	}{
+		{[]string{"5c", "6d"}, "As", rules(6), Hit},
+		{[]string{"5c", "6d"}, "As", rules(6, h17), Double},
+		{[]string{"5c", "6d"}, "As", rules(2), Double},
+		{[]string{"4c", "5d"}, "2s", rules(6), Hit},
+		{[]string{"4c", "5d"}, "2s", rules(2), Double},
+		{[]string{"9c", "7d"}, "Ts", rules(6), Surrender},
+		{[]string{"9c", "7d"}, "Ts", rules(6, noLS), Hit},
+		{[]string{"9c", "8d"}, "As", rules(6, h17), Surrender},
+		{[]string{"9c", "8d"}, "As", rules(6, h17, noLS), Stand},
+		{[]string{"Tc", "2d"}, "3s", rules(6), Hit},
+		{[]string{"Tc", "2d"}, "4s", rules(6), Stand},
+		{[]string{"Ac", "7d"}, "2s", rules(6), Stand},
+		{[]string{"Ac", "7d"}, "2s", rules(6, h17), Double},
+		{[]string{"Ac", "7d"}, "9s", rules(6), Hit},
+		{[]string{"Ac", "7d"}, "As", rules(1), Stand},
+		{[]string{"Ac", "6d"}, "2s", rules(1), Double},
+		{[]string{"2c", "2d"}, "2s", rules(6), Split},
+		{[]string{"2c", "2d"}, "2s", rules(6, noDAS), Hit},
+		{[]string{"8c", "8d"}, "As", rules(6), Split},
+		{[]string{"8c", "8d"}, "As", rules(6, h17), Surrender},
+		{[]string{"9c", "9d"}, "7s", rules(6), Stand},
+		{[]string{"7c", "7d"}, "Ts", rules(1), Surrender},
+		{[]string{"7c", "7d"}, "Ts", rules(1, noLS), Stand},
+		{[]string{"Tc", "Td"}, "6s", rules(6), Stand},
+		{[]string{"5c", "5d"}, "9s", rules(6), Double},
+		{[]string{"Ac", "Ad"}, "As", rules(6), Split},
+	}
+	for _, test := range tests {
+		cards := decktest.Cards(test.cards...)
+		assert.Equal(t, test.action, Recommend(cards, decktest.Cards(test.upcard)[0], test.rules), "%v vs %s, %d decks", cards, test.upcard, test.rules.Decks)
+	}
+}
+
+func TestChartFallbacks(t *testing.T) {
+	chart := BasicStrategy(rules(6))
+	state := dealtState(decktest.Cards("5c", "6d"), decktest.Cards("6s")[0], rules(6))
+	assert.Equal(t, Double, chart.Act(state))
+
+	// a third card can't be doubled
+	state = dealtState(decktest.Cards("2c", "3d", "6h"), decktest.Cards("6s")[0], rules(6))
+	assert.Equal(t, Hit, chart.Act(state))
+
+	// soft 18 doubles, or stands
+	state = dealtState(decktest.Cards("Ac", "3d", "4h"), decktest.Cards("4s")[0], rules(6))
+	assert.Equal(t, Stand, chart.Act(state))
+
+	// doubling only on 9 to 11
+	state = dealtState(decktest.Cards("Ac", "6d"), decktest.Cards("4s")[0], rules(6, func(r *Rules) { r.Double = DoubleNineToEleven }))
+	assert.Equal(t, Hit, chart.Act(state))
+
+	// a pair that can't be split again is played by its total
+	state = dealtState(decktest.Cards("8c", "8d"), decktest.Cards("Ts")[0], rules(6))
+	state.CanSplit = false
+	assert.Equal(t, Surrender, chart.Act(state))
+	state.CanSurrender = false
+	assert.Equal(t, Hit, chart.Act(state))
+
+	// surrender before the peek keeps the hands the chart would surrender, and stands on the rest
+	state = dealtState(decktest.Cards("9c", "7d"), decktest.Cards("As")[0], rules(6))
+	state.Phase = SurrenderDecision
+	assert.Equal(t, Surrender, chart.Act(state))
+	state.Cards = decktest.Cards("Tc", "7d")
+	assert.Equal(t, Stand, chart.Act(state))
+
+	assert.False(t, chart.Insurance(state))
+}
+
+func TestBasicStrategyCharts(t *testing.T) {
+	assert.Equal(t, "Single deck, dealer stands on soft 17", BasicStrategy(rules(1)).Name)
+	assert.Equal(t, "Double deck, dealer hits soft 17", BasicStrategy(rules(2, h17)).Name)
+	assert.Equal(t, "Four to eight decks, dealer stands on soft 17", BasicStrategy(rules(8)).Name)
+	assert.Equal(t, "Single deck, dealer stands on soft 17", BasicStrategy(Rules{}).Name)
+	assert.Equal(t, Stand, Recommend(decktest.Cards("Th", "9c"), decktest.Cards("6d")[0], Rules{}))
+}
+
+func TestChartCSVRoundTrip(t *testing.T) {
+	for _, r := range []Rules{rules(1), rules(2, h17), rules(6, h17)} {
+		chart := BasicStrategy(r)
+		var buf bytes.Buffer
+		assert.Nil(t, chart.WriteCSV(&buf))
+		read, err := ReadChartCSV(&buf)
+		assert.Nil(t, err)
+		assert.Equal(t, chart, read)
+	}
+}
+
+func TestReadChartCSV(t *testing.T) {
+	chart, err := ReadChartCSV(strings.NewReader(`Mine, A, 10, 9, 8, 7, 6, 5, 4, 3, 2
+12, H, H, H, H, H, S, S, S, H, H
+"A,7", H, H, H, S, S, Ds, Ds, Ds, Ds, S
+"10,10", -, -, -, -, -, P, P, -, -, -
+`))
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "Mine", chart.Name)
+	assert.Equal(t, [10]Play{PlayHit, PlayHit, PlayStand, PlayStand, PlayStand, PlayHit, PlayHit, PlayHit, PlayHit, PlayHit}, chart.Hard[12])
+	assert.Equal(t, PlayStand, chart.Soft[18][0])
+	assert.Equal(t, DoubleOrStand, chart.Soft[18][1])
+	assert.Equal(t, PlayHit, chart.Soft[18][9])
+	assert.Equal(t, PlaySplit, chart.Pairs[10][4])
+	// rows left out keep their defaults
+	assert.Equal(t, PlayHit, chart.Hard[16][0])
+	assert.Equal(t, PlayStand, chart.Hard[17][0])
+	assert.Equal(t, NoPlay, chart.Pairs[8][0])
+
+	errors := map[string]string{
+		"":                                                           "The chart is empty",
+		"x,2,3,4,5,6,7,8,9,10\n":                                     "Expected 10 upcards, got 9",
+		"x,2,3,4,5,6,7,8,9,10,10\n":                                  "Invalid upcard 10",
+		"x,2,3,4,5,6,7,8,9,10,A\n22,H\n":                             "Row 22 has 1 plays, expected 10",
+		"x,2,3,4,5,6,7,8,9,10,A\nXX" + row("H"):                      "Unknown row XX",
+		"x,2,3,4,5,6,7,8,9,10,A\n12" + row("X"):                      "Unknown play X",
+		"x,2,3,4,5,6,7,8,9,10,A\n12" + row("P"):                      "Invalid play P in row 12",
+		"x,2,3,4,5,6,7,8,9,10,A\n12" + row("-"):                      "Invalid play - in row 12",
+		"x,2,3,4,5,6,7,8,9,10,A\n12-16" + row("H") + "16" + row("S"): "Row 16 is given twice",
+	}
+	for csv, message := range errors {
+		_, err := ReadChartCSV(strings.NewReader(csv))
+		if assert.NotNil(t, err, csv) {
+			assert.Equal(t, message, err.Error())
+		}
+	}
+}
+
+// row is a CSV row of ten plays
+func row(play string) string {
+	return strings.Repeat(","+play, 10) + "\n"
+}
+
+func TestReadChartJSON(t *testing.T) {
+	chart, err := ReadChartJSON(strings.NewReader(`{
+		"name": "Mine",
+		"rows": {
+			"16": ["S", "S", "S", "S", "S", "H", "H", "Rh", "Rh", "Rh"],
+			"88": ["P", "P", "P", "P", "P", "P", "P", "P", "P", "Rp"]
+		}
+	}`))
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "Mine", chart.Name)
+	assert.Equal(t, SurrenderOrHit, chart.Hard[16][8])
+	assert.Equal(t, SurrenderOrSplit, chart.Pairs[8][9])
+
+	chart, err = ReadChartJSON(strings.NewReader(`{"name": "Reversed", "upcards": ["A", "T", "9", "8", "7", "6", "5", "4", "3", "2"], "rows": {"A8": ["S", "S", "S", "S", "S", "Ds", "S", "S", "S", "S"]}}`))
+	if assert.Nil(t, err) {
+		assert.Equal(t, DoubleOrStand, chart.Soft[19][4])
+	}
+
+	_, err = ReadChartJSON(strings.NewReader(`{"name": "Short", "rows": {"9": ["H"]}}`))
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "Row 9 has 1 plays, expected 10", err.Error())
+	}
+}
+
+func TestAdvise(t *testing.T) {
+	// with nothing but tens left, hitting 12 busts and 11 makes 21
+	var tens []string
+	for _, s := range "cdhs" {
+		for _, f := range "TJQK" {
+			tens = append(tens, string(f)+string(s))
+		}
+	}
+	unseen := decktest.Cards(tens...)
+	state := dealtState(decktest.Cards("7c", "5d"), decktest.Cards("6s")[0], rules(1))
+	assert.Equal(t, Stand, Advise(state, unseen))
+	state = dealtState(decktest.Cards("6c", "5d"), decktest.Cards("6s")[0], rules(1))
+	assert.Equal(t, Double, Advise(state, unseen))
+	state = dealtState(decktest.Cards("9c", "7d"), decktest.Cards("Ts")[0], rules(1))
+	assert.Equal(t, Surrender, Advise(state, unseen))
+
+	// a full shoe plays like basic strategy
+	shoe, _ := deck.New(deck.Decks(6))
+	up := decktest.Cards("Ts")[0]
+	full := NewComposition(shoe.Cards).Without(up)
+	assert.Equal(t, 312, full.Cards()+1)
+	for _, cards := range [][]string{{"Tc", "6d"}, {"8c", "8d"}, {"Ac", "7d"}, {"Tc", "3d"}} {
+		hand := decktest.Cards(cards...)
+		state := dealtState(hand, up, rules(6))
+		unseen := remove(shoe.Cards, append(hand, up)...)
+		assert.Equal(t, Recommend(hand, up, rules(6)), Advise(state, unseen), "%v", hand)
+	}
+
+	// 16 against a ten is surrendered before the peek, 18 isn't
+	state = dealtState(decktest.Cards("Tc", "6d"), up, rules(6, func(r *Rules) { r.Surrender = EarlySurrender }))
+	state.Phase = SurrenderDecision
+	assert.Equal(t, Surrender, Advise(state, remove(shoe.Cards, decktest.Cards("Tc", "6d", "Ts")...)))
+	state.Cards = decktest.Cards("Tc", "8d")
+	assert.Equal(t, Stand, Advise(state, remove(shoe.Cards, decktest.Cards("Tc", "8d", "Ts")...)))
+}
+
+// remove returns the cards without one of each given card
+func remove(cards []deck.Card, gone ...deck.Card) []deck.Card {
+	rest := append([]deck.Card{}, cards...)
+	for _, g := range gone {
+		for i, c := range rest {
+			if c == g {
+				rest = append(rest[:i], rest[i+1:]...)
+				break
+			}
+		}
+	}
+	return rest
+}
+
+func TestDeriveChart(t *testing.T) {
+	if testing.Short() {
+		t.Skip("deriving a chart from a full deck takes a few seconds")
+	}
+	shoe, _ := deck.New()
+	derived := DeriveChart(shoe, rules(1))
+	chart := BasicStrategy(rules(1))
+	for total := 5; total <= 21; total++ {
+		assert.Equal(t, chart.Hard[total], derived.Hard[total], "hard %d", total)
+	}
+	for total := 13; total <= 21; total++ {
+		assert.Equal(t, chart.Soft[total], derived.Soft[total], "soft %d", total)
+	}
+	assert.Equal(t, chart.Pairs[8], derived.Pairs[8])
+	assert.Equal(t, chart.Pairs[1], derived.Pairs[1])
+}
+
+func TestStrategiesPlayRounds(t *testing.T) {
+	for _, decider := range []Decider{BasicStrategy(DefaultRules()), CompositionDependent} {
+		g, err := New(WithSeat("Alice", 1000, decider), WithSeat("Bob", 1000, decider), Decks(2), AllowSurrender(LateSurrender))
+		if !assert.Nil(t, err) {
+			return
+		}
+		for round := 0; round < 20; round++ {
+			_, err := g.PlayRound(10, 10)
+			if !assert.Nil(t, err) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package draw
+
+import (
+	"fmt"
+
+	"adamclerk/deck"
+)
+
+// Street is a part of a hand: a betting round or the draw
+type Street int
+
+// Constants for Street
+const (
+	PreDraw Street = iota
+	Draw
+	PostDraw
+	Showdown
+)
+
+func (s Street) String() string {
+	names := []string{"Pre-Draw", "Draw", "Post-Draw", "Showdown"}
+	if s < 0 || int(s) >= len(names) {
+		return fmt.Sprintf("Street(%d)", int(s))
+	}
+	return names[s]
+}
+
+// ActionType is what a player does when it's their turn to bet, or a forced bet
+type ActionType int
+
+// Constants for ActionType
+const (
+	Fold ActionType = iota
+	Check
+	Call
+	Bet
+	Raise
+	PostAnte
+	PostSmallBlind
+	PostBigBlind
+)
+
+func (a ActionType) String() string {
+	names := []string{"Fold", "Check", "Call", "Bet", "Raise", "PostAnte", "PostSmallBlind", "PostBigBlind"}
+	if a < 0 || int(a) >= len(names) {
+		return fmt.Sprintf("ActionType(%d)", int(a))
+	}
+	return names[a]
+}
+
+// Decider chooses a player's actions. Bots and humans plug in by implementing it.
+//
+// The game is fixed limit, so every bet and raise is one bet of the street's size;
+// Bet and Raise are interchangeable, the hand records whichever applies.
+// Discard is called once, on the draw, with the cards the player throws away.
+// Returning none stands pat.
+type Decider interface {
+	Act(state State) ActionType
+	Discard(state State) []deck.Card
+}
+
+type deciderFuncs struct {
+	act     func(state State) ActionType
+	discard func(state State) []deck.Card
+}
+
+func (d deciderFuncs) Act(state State) ActionType {
+	return d.act(state)
+}
+
+func (d deciderFuncs) Discard(state State) []deck.Card {
+	if d.discard == nil {
+		return nil
+	}
+	return d.discard(state)
+}
+
+// DeciderFuncs adapts a pair of functions to the Decider interface. A nil discard always stands pat.
+func DeciderFuncs(act func(state State) ActionType, discard func(state State) []deck.Card) Decider {
+	return deciderFuncs{act: act, discard: discard}
+}
+
+// PlayerState is what everyone can see of a player
+type PlayerState struct {
+	Name   string
+	Stack  int  // chips behind
+	Bet    int  // chips bet on this street
+	Drew   int  // cards drawn, -1 before the player's draw
+	InHand bool // dealt in and not folded
+	AllIn  bool
+}
+
+// State is what a player sees when it's their turn to bet or draw
+type State struct {
+	Street     Street
+	Seat       int
+	Hand       []deck.Card
+	Button     int
+	Pot        int // every chip committed so far, including this street's bets
+	CurrentBet int // the highest bet on this street
+	ToCall     int // chips needed to call
+	BetSize    int // the size of a bet or raise on this street
+	Raises     int // bets and raises made on this street, counting a full big blind
+	CanRaise   bool
+	MaxDraw    int // the most cards that can be discarded on the draw
+	Players    []PlayerState
+	Actions    []ActionRecord // every bet made this hand so far
+}
+
+// CanCheck tells you if checking is allowed
+func (s State) CanCheck() bool {
+	return s.ToCall == 0
+}
+
+// ActionRecord is an action as it happened, with the chips it moved
+type ActionRecord struct {
+	Street Street
+	Seat   int
+	Type   ActionType
+	Amount int  // chips put in by the action
+	To     int  // the player's total bet for the street afterwards
+	AllIn  bool // the action put the player all in
+}
+
+// DrawRecord is a player's draw
+type DrawRecord struct {
+	Seat      int
+	Discarded []deck.Card
+	Drawn     []deck.Card
+}
@@ -0,0 +1,624 @@
+// Package draw is a fixed limit Five-Card Draw engine.
+package draw
+
+import (
+	"errors"
+	"fmt"
+
+	"adamclerk/deck"
+	"adamclerk/deck/games/internal/betting"
+	"adamclerk/deck/poker"
+	"adamclerk/deck/pot"
+)
+
+// FiveCardDraw is a table of players playing Five-Card Draw, hand after hand.
+// Rules can be found here: https://www.pokernews.com/poker-rules/5-card-draw.htm
+type FiveCardDraw struct {
+	debug       bool
+	deckOptions []func(*deck.Options)
+	smallBlind  int
+	bigBlind    int
+	ante        int
+	smallBet    int
+	bigBet      int
+	cap         int
+	maxDraw     int
+	maxHands    int
+	hands       int
+	button      int
+	players     []*Player
+}
+
+// Seat describes a player joining the table
+type Seat struct {
+	Name    string
+	Stack   int
+	Decider Decider
+}
+
+// Options how to configure a game of Five-Card Draw
+type Options struct {
+	DeckOptions []func(*deck.Options)
+	Seats       []Seat
+	SmallBlind  int
+	BigBlind    int
+	Ante        int
+	SmallBet    int // the bet before the draw, the big blind when not set
+	BigBet      int // the bet after the draw, twice the small bet when not set
+	Cap         int // bets allowed on a street, counting the bet, unless heads up
+	MaxDraw     int
+	Button      int
+	MaxHands    int
+	Debug       bool
+}
+
+// Player is a player at the table
+type Player struct {
+	name    string
+	stack   int
+	decider Decider
+}
+
+// Name returns the players name for verification and announcement
+func (p Player) Name() string {
+	return p.name
+}
+
+// Stack returns the chips the player has
+func (p Player) Stack() int {
+	return p.stack
+}
+
+// WithDeck allows the a game to be configured with a specific deck.
+// A new deck is created with these options for every hand, and the discards
+// are reshuffled with them when the stub runs out.
+func WithDeck(deckOptions ...func(*deck.Options)) func(*Options) {
+	return func(o *Options) {
+		o.DeckOptions = deckOptions
+	}
+}
+
+// WithSeat adds a player to the table. Players sit in the order they are added.
+func WithSeat(name string, stack int, decider Decider) func(*Options) {
+	return func(o *Options) {
+		o.Seats = append(o.Seats, Seat{Name: name, Stack: stack, Decider: decider})
+	}
+}
+
+// Blinds sets the small and big blinds
+func Blinds(small, big int) func(*Options) {
+	return func(o *Options) {
+		o.SmallBlind = small
+		o.BigBlind = big
+	}
+}
+
+// Ante sets the ante every player posts before each hand
+func Ante(amount int) func(*Options) {
+	return func(o *Options) {
+		o.Ante = amount
+	}
+}
+
+// Limits sets the small bet, used before the draw, and the big bet used after.
+// By default the small bet is the big blind and the big bet twice that.
+func Limits(small, big int) func(*Options) {
+	return func(o *Options) {
+		o.SmallBet = small
+		o.BigBet = big
+	}
+}
+
+// Cap sets how many bets can be made on a street: the bet, a full big blind included, and the raises.
+// The cap doesn't apply when only two players are left.
+func Cap(bets int) func(*Options) {
+	return func(o *Options) {
+		o.Cap = bets
+	}
+}
+
+// MaxDraw sets the most cards a player can discard on the draw, 5 by default.
+// Many clubs allow 3.
+func MaxDraw(cards int) func(*Options) {
+	return func(o *Options) {
+		o.MaxDraw = cards
+	}
+}
+
+// Button sets the seat of the dealer button for the first hand
+func Button(seat int) func(*Options) {
+	return func(o *Options) {
+		o.Button = seat
+	}
+}
+
+// MaxHands before Play returns an error.
+func MaxHands(hands int) func(*Options) {
+	return func(o *Options) {
+		o.MaxHands = hands
+	}
+}
+
+// Debug sets the debug param for the game
+func Debug(o *Options) {
+	o.Debug = true
+}
+
+// New function creates a new game of Five-Card Draw
+func New(options ...func(*Options)) (*FiveCardDraw, error) {
+	opt := Options{DeckOptions: []func(*deck.Options){}, SmallBlind: 1, BigBlind: 2, Cap: 4, MaxDraw: 5, MaxHands: 100000}
+	for _, option := range options {
+		option(&opt)
+	}
+	if opt.SmallBet == 0 {
+		opt.SmallBet = opt.BigBlind
+	}
+	if opt.BigBet == 0 {
+		opt.BigBet = 2 * opt.SmallBet
+	}
+	if err := betting.CheckSeats(len(opt.Seats), 8); err != nil {
+		return nil, err
+	}
+	if opt.BigBlind <= 0 || opt.SmallBlind < 0 || opt.SmallBlind > opt.BigBlind || opt.Ante < 0 {
+		return nil, errors.New("Invalid blinds")
+	}
+	if opt.SmallBet < opt.BigBlind || opt.BigBet < opt.SmallBet || opt.Cap < 1 || opt.MaxDraw < 0 || opt.MaxDraw > 5 {
+		return nil, errors.New("Invalid limits")
+	}
+	if opt.Button < 0 || opt.Button >= len(opt.Seats) {
+		return nil, errors.New("Invalid button")
+	}
+
+	g := &FiveCardDraw{
+		debug:       opt.Debug,
+		deckOptions: opt.DeckOptions,
+		smallBlind:  opt.SmallBlind,
+		bigBlind:    opt.BigBlind,
+		ante:        opt.Ante,
+		smallBet:    opt.SmallBet,
+		bigBet:      opt.BigBet,
+		cap:         opt.Cap,
+		maxDraw:     opt.MaxDraw,
+		maxHands:    opt.MaxHands,
+		button:      opt.Button,
+	}
+	for _, s := range opt.Seats {
+		if s.Decider == nil {
+			return nil, errors.New("Seat " + s.Name + " has no decider")
+		}
+		g.players = append(g.players, &Player{name: s.Name, stack: s.Stack, decider: s.Decider})
+	}
+	return g, nil
+}
+
+// Players returns the players in seat order
+func (g *FiveCardDraw) Players() []*Player {
+	return g.players
+}
+
+// Button returns the seat of the dealer button for the next hand
+func (g *FiveCardDraw) Button() int {
+	return g.button
+}
+
+// Play plays hands until one player has all the chips
+func (g *FiveCardDraw) Play() error {
+	for g.activePlayers() > 1 {
+		if g.hands >= g.maxHands {
+			return errors.New("Too many hands played")
+		}
+		if _, err := g.PlayHand(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Winner announces the winner, the only player with chips left
+func (g *FiveCardDraw) Winner() []Player {
+	var winners []Player
+	for _, p := range g.players {
+		if p.stack > 0 {
+			winners = append(winners, *p)
+		}
+	}
+	return winners
+}
+
+func (g *FiveCardDraw) activePlayers() int {
+	return betting.Active(g.stacks())
+}
+
+// nextActive returns the next seat after seat with chips
+func (g *FiveCardDraw) nextActive(seat int) int {
+	return betting.NextActive(g.stacks(), seat)
+}
+
+// stacks returns the players' chips in seat order
+func (g *FiveCardDraw) stacks() []int {
+	stacks := make([]int, len(g.players))
+	for i, p := range g.players {
+		stacks[i] = p.stack
+	}
+	return stacks
+}
+
+// shuffle makes a stub of cards shuffled with the game's deck options,
+// so a seeded or stacked deck reproduces the reshuffles as well as the deal
+func (g *FiveCardDraw) shuffle(cards []deck.Card) (*deck.Deck, error) {
+	options := append(append([]func(*deck.Options){}, g.deckOptions...), func(o *deck.Options) {
+		o.Cards = cards
+		o.Signature = ""
+		o.Decks = 1
+	})
+	return deck.New(options...)
+}
+
+// HandResult is the record of a hand
+type HandResult struct {
+	Number     int
+	Button     int
+	SmallBlind int // seat of the small blind
+	BigBlind   int // seat of the big blind
+	Signature  string
+	Stacks     []int         // stacks at the start of the hand, by seat
+	Dealt      [][]deck.Card // the five cards dealt by seat, nil for players not dealt in
+	Final      [][]deck.Card // the hands after the draw by seat
+	Draws      []DrawRecord  // in the order players drew
+	// Reshuffles are the signatures of the discards shuffled into a new stub,
+	// in order, each time the deck ran out on the draw
+	Reshuffles []string
+	Actions    []ActionRecord
+	Pots       []pot.Pot
+	Shown      []bool            // players who showed their cards at showdown, by seat
+	Hands      []poker.HandValue // the value of shown hands, by seat
+	Winnings   []int             // chips won, by seat
+}
+
+// PlayHand plays a single hand and moves the button.
+// If a player's decider returns an illegal action or discard the hand is abandoned,
+// every stack is restored and an error is returned.
+func (g *FiveCardDraw) PlayHand() (*HandResult, error) {
+	if g.activePlayers() < 2 {
+		return nil, errors.New("Not enough players with chips")
+	}
+	if g.players[g.button].stack == 0 {
+		g.button = g.nextActive(g.button)
+	}
+	g.hands++
+
+	d, err := deck.New(g.deckOptions...)
+	if err != nil {
+		return nil, err
+	}
+	discards, _ := deck.New(deck.Empty)
+	n := &hand{
+		Hand:     betting.New(g.stacks()),
+		game:     g,
+		deck:     d,
+		discards: discards,
+		drew:     make([]int, len(g.players)),
+		result: &HandResult{
+			Number:    g.hands,
+			Button:    g.button,
+			Signature: d.GetSignature(),
+			Stacks:    g.stacks(),
+			Dealt:     make([][]deck.Card, len(g.players)),
+			Final:     make([][]deck.Card, len(g.players)),
+			Shown:     make([]bool, len(g.players)),
+			Hands:     make([]poker.HandValue, len(g.players)),
+			Winnings:  make([]int, len(g.players)),
+		},
+	}
+	for i := range n.drew {
+		n.drew[i] = -1
+	}
+	if err := n.play(); err != nil {
+		return nil, err
+	}
+	for i, p := range g.players {
+		p.stack = n.Stacks[i]
+	}
+	g.button = g.nextActive(g.button)
+	return n.result, nil
+}
+
+// hand is the state of the hand being played
+type hand struct {
+	*betting.Hand
+	game     *FiveCardDraw
+	deck     *deck.Deck // the stub
+	discards *deck.Deck // discards and burn cards, reshuffled when the stub runs out
+	street   Street
+	drew     []int
+	betSize  int
+	raises   int // full bets and raises on the current street
+	result   *HandResult
+}
+
+func (n *hand) debugf(format string, a ...interface{}) {
+	deck.Debugf(n.game.debug, format, a...)
+}
+
+func (n *hand) player(seat int) *Player {
+	return n.game.players[seat]
+}
+
+// fromButton returns the seats still in the hand, starting left of the button
+func (n *hand) fromButton() []int {
+	var seats []int
+	for s := n.Next(n.game.button); ; s = n.Next(s) {
+		seats = append(seats, s)
+		if s == n.game.button || len(seats) == len(n.Folded) {
+			return seats
+		}
+	}
+}
+
+// put moves chips from a player's stack to their bet, all in if the stack is short, and records the action
+func (n *hand) put(seat, amount int, t ActionType, toBet bool) {
+	amount = n.Put(seat, amount, toBet)
+	n.result.Actions = append(n.result.Actions, ActionRecord{
+		Street: n.street,
+		Seat:   seat,
+		Type:   t,
+		Amount: amount,
+		To:     n.Bets[seat],
+		AllIn:  n.Stacks[seat] == 0,
+	})
+	n.debugf("  %s: %s %d\n", n.player(seat).name, t, amount)
+}
+
+func (n *hand) play() error {
+	g := n.game
+	n.debugf("Hand %d Started, button %s\n", n.result.Number, n.player(g.button).name)
+
+	// Antes and blinds. Heads up the button posts the small blind.
+	if g.ante > 0 {
+		for _, s := range n.fromButton() {
+			n.put(s, g.ante, PostAnte, false)
+		}
+	}
+	sb := n.Next(g.button)
+	if n.InHand() == 2 {
+		sb = g.button
+	}
+	bb := n.Next(sb)
+	n.result.SmallBlind, n.result.BigBlind = sb, bb
+	n.put(sb, g.smallBlind, PostSmallBlind, true)
+	n.put(bb, g.bigBlind, PostBigBlind, true)
+	n.CurrentBet = g.bigBlind
+	n.betSize = g.smallBet
+	if g.bigBlind == g.smallBet {
+		n.raises = 1
+	}
+
+	// Deal five cards, one at a time, starting left of the button
+	seats := n.fromButton()
+	hands := make([]*deck.Deck, len(seats))
+	for i := range hands {
+		hands[i], _ = deck.New(deck.Empty)
+	}
+	if n.deck.NumberOfCards() < 5*len(seats)+1 {
+		return errors.New("Not enough cards in the deck")
+	}
+	n.deck.Deal(5, hands...)
+	for i, s := range seats {
+		n.result.Dealt[s] = hands[i].Cards
+		n.result.Final[s] = hands[i].Cards
+	}
+
+	if err := n.Round(n.Next(bb), n.act); err != nil {
+		return err
+	}
+	if n.InHand() > 1 {
+		n.street = Draw
+		n.debugf("%s\n", Draw)
+		n.deck.Deal(1, n.discards)
+		for _, s := range n.fromButton() {
+			if err := n.draw(s); err != nil {
+				return err
+			}
+		}
+
+		n.street = PostDraw
+		n.debugf("%s\n", PostDraw)
+		n.NewStreet()
+		n.raises = 0
+		n.betSize = g.bigBet
+		if err := n.Round(n.Next(g.button), n.act); err != nil {
+			return err
+		}
+	}
+	n.street = Showdown
+	return n.award()
+}
+
+// draw asks a player for their discards and replaces them
+func (n *hand) draw(seat int) error {
+	p := n.player(seat)
+	held := n.result.Final[seat]
+	discarded := p.decider.Discard(n.state(seat))
+	invalid := func(reason string) error {
+		return fmt.Errorf("Invalid discard %s by %s: %s", cardList(discarded), p.name, reason)
+	}
+	if len(discarded) > n.game.maxDraw {
+		return invalid(fmt.Sprintf("at most %d cards can be drawn", n.game.maxDraw))
+	}
+	kept := append([]deck.Card{}, held...)
+	for _, c := range discarded {
+		i := indexOf(kept, c)
+		if i < 0 {
+			return invalid(c.String() + " is not in the hand")
+		}
+		kept = append(kept[:i], kept[i+1:]...)
+	}
+
+	drawn, _ := deck.New(deck.Empty)
+	for drawn.NumberOfCards() < len(discarded) {
+		if n.deck.NumberOfCards() == 0 {
+			// the stub ran out: shuffle the discards so far, not this player's, into a new stub
+			if n.discards.NumberOfCards() == 0 {
+				return errors.New("Not enough cards to draw")
+			}
+			stub, err := n.game.shuffle(n.discards.Cards)
+			if err != nil {
+				return err
+			}
+			n.deck, n.discards = stub, n.deck
+			n.result.Reshuffles = append(n.result.Reshuffles, n.deck.GetSignature())
+			n.debugf("  Reshuffled %d discards\n", n.deck.NumberOfCards())
+		}
+		n.deck.Deal(1, drawn)
+	}
+	n.discards.Cards = append(n.discards.Cards, discarded...)
+
+	n.drew[seat] = len(discarded)
+	n.result.Final[seat] = append(kept, drawn.Cards...)
+	n.result.Draws = append(n.result.Draws, DrawRecord{
+		Seat:      seat,
+		Discarded: append([]deck.Card{}, discarded...),
+		Drawn:     drawn.Cards,
+	})
+	n.debugf("  %s draws %d\n", p.name, len(discarded))
+	return nil
+}
+
+func (n *hand) state(seat int) State {
+	s := State{
+		Street:     n.street,
+		Seat:       seat,
+		Hand:       append([]deck.Card{}, n.result.Final[seat]...),
+		Button:     n.game.button,
+		CurrentBet: n.CurrentBet,
+		ToCall:     n.ToCall(seat),
+		BetSize:    n.betSize,
+		Raises:     n.raises,
+		CanRaise:   n.CanRaise(seat) && (n.raises < n.game.cap || n.InHand() == 2),
+		MaxDraw:    n.game.maxDraw,
+		Pot:        n.Pot(),
+		Actions:    append([]ActionRecord{}, n.result.Actions...),
+	}
+	for i, q := range n.game.players {
+		s.Players = append(s.Players, PlayerState{
+			Name:   q.name,
+			Stack:  n.Stacks[i],
+			Bet:    n.Bets[i],
+			Drew:   n.drew[i],
+			InHand: !n.Folded[i],
+			AllIn:  n.AllIn(i),
+		})
+	}
+	return s
+}
+
+// act asks a player for an action and applies it
+func (n *hand) act(seat int) error {
+	p := n.player(seat)
+	s := n.state(seat)
+	a := p.decider.Act(s)
+	invalid := func(reason string) error {
+		return fmt.Errorf("Invalid action %s by %s: %s", a, p.name, reason)
+	}
+
+	switch a {
+	case Fold:
+		n.Folded[seat] = true
+		n.put(seat, 0, Fold, true)
+		return nil
+	case Check:
+		if s.ToCall > 0 {
+			return invalid("there is a bet to call")
+		}
+		n.put(seat, 0, Check, true)
+		return nil
+	case Call:
+		if s.ToCall == 0 {
+			return invalid("there is nothing to call")
+		}
+		n.put(seat, s.ToCall, Call, true)
+		return nil
+	case Bet, Raise:
+		if !s.CanRaise && n.raises >= n.game.cap && n.InHand() > 2 {
+			return invalid("the betting is capped")
+		}
+		if !s.CanRaise {
+			return invalid("betting is not open to this player")
+		}
+		n.raise(seat)
+		return nil
+	}
+	return invalid("unknown action")
+}
+
+// raise bets or raises by one bet, or all in for less
+func (n *hand) raise(seat int) {
+	to, t := n.CurrentBet+n.betSize, Raise
+	switch {
+	case n.CurrentBet == 0:
+		to, t = n.betSize, Bet
+	case n.CurrentBet < n.betSize:
+		// a big blind or an all in for less than a bet is raised to a full bet
+		to = n.betSize
+	}
+	if most := n.Bets[seat] + n.Stacks[seat]; to > most {
+		to = most
+	}
+	// the first full bet, or an all in raise of at least half a bet, reopens the betting
+	if (n.raises == 0 && to >= n.betSize) || 2*(to-n.CurrentBet) >= n.betSize {
+		n.raises++
+		n.Reopen(seat)
+	}
+	n.CurrentBet = to
+	n.put(seat, to-n.Bets[seat], t, true)
+}
+
+// award gives the pots to the winners, at showdown or to the last player left
+func (n *hand) award() error {
+	r := n.result
+	showdown := n.InHand() > 1
+	values := make([]int, len(n.Folded))
+	for i, f := range n.Folded {
+		if f || !showdown {
+			continue
+		}
+		r.Hands[i] = poker.Evaluate(r.Final[i])
+		values[i] = r.Hands[i].Value
+		r.Shown[i] = true
+		n.debugf("  %s shows %s: %s\n", n.player(i).name, cardList(r.Final[i]), r.Hands[i])
+	}
+
+	// odd chips go to the winners closest to the left of the button
+	split, err := n.Award(values, pot.LeftOfButton(n.game.button, len(values)))
+	if err != nil {
+		return err
+	}
+	r.Pots = split.Pots
+	r.Winnings = split.Winnings
+	for i, won := range r.Winnings {
+		if won > 0 {
+			n.debugf("  %s wins %d\n", n.player(i).name, won)
+		}
+	}
+	return nil
+}
+
+func indexOf(cards []deck.Card, card deck.Card) int {
+	for i, c := range cards {
+		if c == card {
+			return i
+		}
+	}
+	return -1
+}
+
+func cardList(cards []deck.Card) string {
+	str := ""
+	for i, c := range cards {
+		if i > 0 {
+			str += " "
+		}
+		str += c.String()
+	}
+	return str
+}
@@ -0,0 +1,49 @@
+package draw
+
+import (
+	"fmt"
+
+	"adamclerk/deck"
+)
+
+// keepPairs checks when it can, calls otherwise and draws to the cards it has more than one of
+var keepPairs = DeciderFuncs(func(s State) ActionType {
+	if s.CanCheck() {
+		return Check
+	}
+	return Call
+}, func(s State) []deck.Card {
+	counts := map[int]int{}
+	for _, c := range s.Hand {
+		counts[c.Face()]++
+	}
+	var discards []deck.Card
+	for _, c := range s.Hand {
+		if counts[c.Face()] == 1 {
+			discards = append(discards, c)
+		}
+	}
+	return discards
+})
+
+// This example plays an unshuffled deck: nobody is dealt a pair, so both players draw five
+func Example() {
+	game, err := New(
+		WithDeck(deck.Unshuffled),
+		WithSeat("Alice", 100, keepPairs),
+		WithSeat("Bob", 100, keepPairs),
+	)
+	if err != nil {
+		panic(err)
+	}
+	result, err := game.PlayHand()
+	if err != nil {
+		panic(err)
+	}
+	for i, p := range game.Players() {
+		fmt.Printf("%s: %s draws %s, %s, %d chips\n", p.Name(), cardList(result.Dealt[i]), cardList(result.Final[i]), result.Hands[i], p.Stack())
+	}
+	// Output:
+	// Alice: 2♣ 4♣ 6♣ 8♣ T♣ draws 4♦ 5♦ 6♦ 7♦ 8♦, Straight Flush (8), 102 chips
+	// Bob: A♣ 3♣ 5♣ 7♣ 9♣ draws Q♣ K♣ A♦ 2♦ 3♦, High Card (A K Q 3 2), 98 chips
+}
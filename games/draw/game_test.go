@@ -0,0 +1,223 @@
+package draw
+
+import (
+	"math/rand"
+	"testing"
+
+	"adamclerk/deck"
+	"adamclerk/deck/internal/decktest"
+	"github.com/stretchr/testify/assert"
+)
+
+// passive checks when it can, calls otherwise and stands pat
+var passive = DeciderFuncs(check, nil)
+
+func check(s State) ActionType {
+	if s.CanCheck() {
+		return Check
+	}
+	return Call
+}
+
+// discarding plays passively and discards the given cards
+func discarding(cards ...string) Decider {
+	return DeciderFuncs(check, func(s State) []deck.Card {
+		return decktest.Cards(cards...)
+	})
+}
+
+// stacked returns a deck option dealing the given cards first; the rest of the deck follows in order.
+// Cards are dealt one at a time starting left of the button.
+func stacked(cards ...string) func(*Options) {
+	return WithDeck(decktest.Stacked(cards...)...)
+}
+
+func stacks(g *FiveCardDraw) []int {
+	var result []int
+	for _, p := range g.Players() {
+		result = append(result, p.Stack())
+	}
+	return result
+}
+
+func TestDrawImprovesTheHand(t *testing.T) {
+	game, _ := New(
+		stacked(
+			"Kc", "Ac", "Kd", "Ad", "Qh", "7h", "Jh", "6s", "9s", "2c", // Bob, left of the button, is dealt first
+			"Tc",       // burn
+			"Ah", "Kh", // Alice's draw
+		),
+		WithSeat("Alice", 100, discarding("6s", "2c")),
+		WithSeat("Bob", 100, passive),
+	)
+	result, err := game.PlayHand()
+	assert.Nil(t, err)
+	assert.Equal(t, []DrawRecord{
+		{Seat: 1, Discarded: []deck.Card{}, Drawn: []deck.Card{}},
+		{Seat: 0, Discarded: decktest.Cards("6s", "2c"), Drawn: decktest.Cards("Ah", "Kh")},
+	}, result.Draws)
+	assert.Equal(t, decktest.Cards("Ac", "Ad", "7h", "Ah", "Kh"), result.Final[0])
+	assert.Equal(t, "Three of a Kind (A K 7)", result.Hands[0].String())
+	assert.Equal(t, []int{102, 98}, stacks(game))
+}
+
+func TestReshuffleTheDiscards(t *testing.T) {
+	// 16 cards: 10 are dealt, 1 burnt and 5 left to draw
+	game, _ := New(
+		WithDeck(deck.Unshuffled, deck.Faces(deck.ACE, deck.TWO, deck.THREE, deck.FOUR)),
+		WithSeat("Alice", 100, DeciderFuncs(check, func(s State) []deck.Card { return s.Hand[:4] })),
+		WithSeat("Bob", 100, DeciderFuncs(check, func(s State) []deck.Card { return s.Hand[:3] })),
+	)
+	result, err := game.PlayHand()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(result.Reshuffles))
+	bob, alice := result.Draws[0], result.Draws[1]
+	assert.Equal(t, 3, len(bob.Drawn))
+	assert.Equal(t, 4, len(alice.Drawn))
+	// two cards came from the stub, two from the burn card and Bob's discards but never Alice's
+	seen := map[deck.Card]bool{}
+	for _, hand := range result.Final {
+		for _, c := range hand {
+			assert.False(t, seen[c])
+			seen[c] = true
+		}
+	}
+	for _, c := range alice.Drawn[2:] {
+		assert.Equal(t, -1, indexOf(alice.Discarded, c))
+	}
+}
+
+func TestReshuffleIsReproducible(t *testing.T) {
+	var results []*HandResult
+	for i := 0; i < 2; i++ {
+		game, _ := New(
+			WithDeck(deck.FromSeed("draw"), deck.Faces(deck.ACE, deck.TWO, deck.THREE, deck.FOUR)),
+			WithSeat("Alice", 100, DeciderFuncs(check, func(s State) []deck.Card { return s.Hand[:4] })),
+			WithSeat("Bob", 100, DeciderFuncs(check, func(s State) []deck.Card { return s.Hand[:3] })),
+		)
+		// the global source mustn't matter
+		rand.Seed(int64(i))
+		result, err := game.PlayHand()
+		assert.Nil(t, err)
+		results = append(results, result)
+	}
+	assert.Equal(t, 1, len(results[0].Reshuffles))
+	assert.Equal(t, results[0].Reshuffles, results[1].Reshuffles)
+	assert.Equal(t, results[0].Final, results[1].Final)
+}
+
+func TestInvalidDiscards(t *testing.T) {
+	for _, test := range []struct {
+		discards []string
+		message  string
+	}{
+		{[]string{"Ks"}, "Invalid discard K♠ by Alice: K♠ is not in the hand"},
+		{[]string{"Ac", "Ac"}, "Invalid discard A♣ A♣ by Alice: A♣ is not in the hand"},
+		{[]string{"Ac", "Ad", "7h", "Jh"}, "Invalid discard A♣ A♦ 7♥ J♥ by Alice: at most 3 cards can be drawn"},
+	} {
+		game, _ := New(
+			stacked("Kc", "Ac", "Kd", "Ad", "Qh", "7h", "Jh", "6s", "9s", "2c"),
+			WithSeat("Alice", 100, discarding(test.discards...)),
+			WithSeat("Bob", 100, passive),
+			MaxDraw(3),
+		)
+		_, err := game.PlayHand()
+		assert.Equal(t, test.message, err.Error())
+		assert.Equal(t, []int{100, 100}, stacks(game))
+	}
+}
+
+func TestLimitBetting(t *testing.T) {
+	raise := DeciderFuncs(func(s State) ActionType {
+		if s.CanRaise {
+			return Raise
+		}
+		return check(s)
+	}, nil)
+	game, _ := New(
+		WithSeat("Button", 100, raise),
+		WithSeat("Small", 100, raise),
+		WithSeat("Big", 100, raise),
+		Blinds(1, 2),
+	)
+	result, err := game.PlayHand()
+	assert.Nil(t, err)
+	var to []int
+	for _, a := range result.Actions {
+		if a.Street == PreDraw && (a.Type == Raise || a.Type == Bet) {
+			to = append(to, a.To)
+		}
+	}
+	// the big blind is the first bet, then three raises of 2
+	assert.Equal(t, []int{4, 6, 8}, to)
+	for _, a := range result.Actions {
+		if a.Street == PostDraw && a.Type == Bet {
+			assert.Equal(t, 4, a.Amount)
+		}
+	}
+	assert.Equal(t, 3*(8+16), result.Pots[0].Amount)
+}
+
+func TestFoldToTheBigBlind(t *testing.T) {
+	fold := DeciderFuncs(func(s State) ActionType { return Fold }, nil)
+	game, _ := New(
+		WithSeat("Button", 100, fold),
+		WithSeat("Small", 100, fold),
+		WithSeat("Big", 100, passive),
+	)
+	result, err := game.PlayHand()
+	assert.Nil(t, err)
+	assert.Nil(t, result.Draws)
+	assert.Equal(t, []int{100, 99, 101}, stacks(game))
+	assert.Equal(t, 1, game.Button())
+}
+
+func TestNewErrors(t *testing.T) {
+	_, err := New(WithSeat("Alice", 100, passive))
+	assert.Equal(t, "At least two seats are needed", err.Error())
+	_, err = New(WithSeat("Alice", 100, nil), WithSeat("Bob", 100, nil))
+	assert.Equal(t, "Seat Alice has no decider", err.Error())
+	_, err = New(WithSeat("Alice", 100, passive), WithSeat("Bob", 100, passive), Limits(1, 2))
+	assert.Equal(t, "Invalid limits", err.Error())
+	_, err = New(WithSeat("Alice", 100, passive), WithSeat("Bob", 100, passive), Blinds(3, 2))
+	assert.Equal(t, "Invalid blinds", err.Error())
+}
+
+func TestRandomPlayKeepsEveryChip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	random := DeciderFuncs(func(s State) ActionType {
+		switch r.Intn(4) {
+		case 0:
+			return Fold
+		case 1:
+			if s.CanRaise {
+				return Raise
+			}
+		}
+		return check(s)
+	}, func(s State) []deck.Card {
+		return s.Hand[:r.Intn(s.MaxDraw+1)]
+	})
+	for game := 0; game < 50; game++ {
+		var options []func(*Options)
+		for seat := 0; seat < 2+game%7; seat++ {
+			options = append(options, WithSeat(string(rune('A'+seat)), 5+r.Intn(100), random))
+		}
+		g, _ := New(append(options, Ante(game%2))...)
+		total := 0
+		for _, p := range g.Players() {
+			total += p.Stack()
+		}
+		for g.activePlayers() > 1 {
+			result, err := g.PlayHand()
+			if !assert.Nil(t, err) {
+				return
+			}
+			sum := 0
+			for _, p := range g.Players() {
+				sum += p.Stack()
+			}
+			assert.Equal(t, total, sum, "hand %d", result.Number)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+// Package freecell is FreeCell solitaire: eight columns dealt face up, four cells and four foundations.
+package freecell
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"adamclerk/deck"
+	"adamclerk/deck/games/internal/solitaire"
+)
+
+// FreeCell is a game of FreeCell.
+// Rules can be found here: https://bicyclecards.com/how-to-play/freecell/
+type FreeCell struct {
+	debug       bool
+	autoPlay    bool
+	number      int
+	signature   string
+	tableau     [8][]deck.Card
+	cells       []deck.Card
+	foundations solitaire.Foundations
+	moves       []Move
+}
+
+// none marks an empty cell
+const none = deck.Card(-1)
+
+// Options how to configure a game of FreeCell
+type Options struct {
+	DeckOptions []func(*deck.Options)
+	Deal        int
+	Cells       int
+	AutoPlay    bool
+	Debug       bool
+}
+
+// WithDeck allows the a game to be configured with a specific deck
+func WithDeck(deckOptions ...func(*deck.Options)) func(*Options) {
+	return func(o *Options) {
+		o.DeckOptions = deckOptions
+	}
+}
+
+// Deal deals the numbered deal of the classic Microsoft game, 1 to 32000, as deck.MicrosoftDeal does
+func Deal(number int) func(*Options) {
+	return func(o *Options) {
+		o.Deal = number
+	}
+}
+
+// Cells sets how many cells there are, 4 by default; fewer make a deal harder
+func Cells(count int) func(*Options) {
+	return func(o *Options) {
+		o.Cells = count
+	}
+}
+
+// AutoPlay plays safe cards to the foundations after every move
+func AutoPlay(o *Options) {
+	o.AutoPlay = true
+}
+
+// Debug sets the debug param for the game
+func Debug(o *Options) {
+	o.Debug = true
+}
+
+// New function creates a new game of FreeCell, from a shuffled deck unless configured otherwise
+func New(options ...func(*Options)) (*FreeCell, error) {
+	opt := Options{DeckOptions: []func(*deck.Options){}, Cells: 4}
+	for _, option := range options {
+		option(&opt)
+	}
+	if opt.Deal < 0 {
+		return nil, errors.New("Invalid deal number")
+	}
+	if opt.Cells < 0 || opt.Cells > 10 {
+		return nil, errors.New("Invalid number of cells")
+	}
+	if opt.Deal > 0 {
+		opt.DeckOptions = []func(*deck.Options){deck.MicrosoftDeal(opt.Deal)}
+	}
+	d, err := deck.New(opt.DeckOptions...)
+	if err != nil {
+		return nil, err
+	}
+	if err := solitaire.FullDeck("FreeCell", d.Cards); err != nil {
+		return nil, err
+	}
+	g := &FreeCell{
+		debug:     opt.Debug,
+		autoPlay:  opt.AutoPlay,
+		number:    opt.Deal,
+		signature: d.GetSignature(),
+		cells:     make([]deck.Card, opt.Cells),
+	}
+	for i := range g.cells {
+		g.cells[i] = none
+	}
+	for i, c := range d.Cards {
+		g.tableau[i%8] = append(g.tableau[i%8], c)
+	}
+	g.debugf("%s\n", g)
+	if g.autoPlay {
+		g.AutoMove()
+	}
+	return g, nil
+}
+
+func (g *FreeCell) debugf(format string, a ...interface{}) {
+	deck.Debugf(g.debug, format, a...)
+}
+
+// Number returns the Microsoft deal number the game was dealt from, or 0
+func (g *FreeCell) Number() int {
+	return g.number
+}
+
+// Signature returns the signature of the deck the game was dealt from
+func (g *FreeCell) Signature() string {
+	return g.signature
+}
+
+// Tableau returns a column, 0 to 7, the card that can be moved last
+func (g *FreeCell) Tableau(column int) []deck.Card {
+	return g.tableau[column]
+}
+
+// Cell returns the card in a cell, and false when it's empty
+func (g *FreeCell) Cell(cell int) (deck.Card, bool) {
+	c := g.cells[cell]
+	return c, c != none
+}
+
+// NumberOfCells returns how many cells there are, empty or not
+func (g *FreeCell) NumberOfCells() int {
+	return len(g.cells)
+}
+
+// Foundation returns the cards on the foundation of a suit, the ace first
+func (g *FreeCell) Foundation(suit deck.Suit) []deck.Card {
+	return g.foundations[suit]
+}
+
+// Moves returns the moves played so far, automatic ones included
+func (g *FreeCell) Moves() []Move {
+	return g.moves
+}
+
+// Won tells you if every card is on the foundations
+func (g *FreeCell) Won() bool {
+	return g.foundations.Full()
+}
+
+// Clone returns a copy of the game that can be played on its own
+func (g *FreeCell) Clone() *FreeCell {
+	c := *g
+	for i, p := range g.tableau {
+		c.tableau[i] = append([]deck.Card{}, p...)
+	}
+	c.foundations = g.foundations.Clone()
+	c.cells = append([]deck.Card{}, g.cells...)
+	c.moves = append([]Move{}, g.moves...)
+	return &c
+}
+
+// String draws the game: the cells, with empty ones as --, the foundations, then the columns
+func (g *FreeCell) String() string {
+	b := &strings.Builder{}
+	b.WriteString("Cells:")
+	for _, c := range g.cells {
+		if c == none {
+			b.WriteString(" --")
+		} else {
+			fmt.Fprintf(b, " %s", c)
+		}
+	}
+	fmt.Fprintf(b, "  Foundations:%s\n", g.foundations.String())
+	for i, p := range g.tableau {
+		fmt.Fprintf(b, "T%d:%s\n", i+1, solitaire.CardList(p))
+	}
+	return b.String()
+}
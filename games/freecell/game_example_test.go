@@ -0,0 +1,68 @@
+package freecell
+
+import (
+	"fmt"
+)
+
+// This example deals classic deal #1, then frees the A♣ and lets the safe cards go up
+func Example() {
+	game, err := New(Deal(1), AutoPlay)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Print(game)
+	for _, m := range []Move{{Kind: TableauToCell, From: 5, To: 0}, {Kind: TableauToCell, From: 5, To: 1}} {
+		if err := game.Move(m); err != nil {
+			panic(err)
+		}
+	}
+	fmt.Println(game.Moves())
+	fmt.Print(game)
+	// Output:
+	// Cells: -- -- -- --  Foundations: ♣ ♦ ♥ ♠
+	// T1: J♦ K♦ 2♠ 4♣ 3♠ 6♦ 6♠
+	// T2: 2♦ K♣ K♠ 5♣ T♦ 8♠ 9♣
+	// T3: 9♥ 9♠ 9♦ T♠ 4♠ 8♦ 2♥
+	// T4: J♣ 5♠ Q♦ Q♥ T♥ Q♠ 6♥
+	// T5: 5♦ A♦ J♠ 4♥ 8♥ 6♣
+	// T6: 7♥ Q♣ A♠ A♣ 2♣ 3♦
+	// T7: 7♣ K♥ A♥ 4♦ J♥ 8♣
+	// T8: 5♥ 3♥ 3♣ 7♠ 7♦ T♣
+	// [T6-C1 T6-C2 T6-♣ C2-♣ T6-♠]
+	// Cells: 3♦ -- -- --  Foundations: 2♣ ♦ ♥ A♠
+	// T1: J♦ K♦ 2♠ 4♣ 3♠ 6♦ 6♠
+	// T2: 2♦ K♣ K♠ 5♣ T♦ 8♠ 9♣
+	// T3: 9♥ 9♠ 9♦ T♠ 4♠ 8♦ 2♥
+	// T4: J♣ 5♠ Q♦ Q♥ T♥ Q♠ 6♥
+	// T5: 5♦ A♦ J♠ 4♥ 8♥ 6♣
+	// T6: 7♥ Q♣
+	// T7: 7♣ K♥ A♥ 4♦ J♥ 8♣
+	// T8: 5♥ 3♥ 3♣ 7♠ 7♦ T♣
+}
+
+// This example tells how many cards can move at once
+func ExampleFreeCell_Capacity() {
+	game, _ := New(Deal(1))
+	fmt.Println(game.Capacity(0), Supermove(4, 1), Supermove(2, 2))
+	// Output: 5 10 12
+}
+
+// This example checks deals before offering them, the way a game would skip the ones that can't be won
+func ExampleFreeCell_Solve() {
+	for _, number := range []int{11981, 11982, 11983} {
+		game, _ := New(Deal(number))
+		solution, err := game.Solve()
+		if err != nil {
+			panic(err)
+		}
+		fmt.Printf("#%d: %s", number, solution.Verdict)
+		if solution.Verdict == Solved {
+			fmt.Printf(" in %d moves, starting %v", len(solution.Moves), solution.Moves[:4])
+		}
+		fmt.Println()
+	}
+	// Output:
+	// #11981: Solved in 110 moves, starting [T1-C1 T1-T2 T5-T2 T7-T5]
+	// #11982: Unwinnable
+	// #11983: Solved in 101 moves, starting [T2-♦ T1-♦ T5-♠ T3-♠]
+}
@@ -0,0 +1,260 @@
+package freecell
+
+import (
+	"math/rand"
+	"testing"
+
+	"adamclerk/deck"
+	"adamclerk/deck/internal/decktest"
+	"github.com/stretchr/testify/assert"
+)
+
+// empty returns a game with nothing dealt, to lay a position out by hand
+func empty(options ...func(*Options)) *FreeCell {
+	g, _ := New(options...)
+	g.tableau = [8][]deck.Card{}
+	return g
+}
+
+// found puts the cards of a suit up to the face on its foundation
+func (g *FreeCell) found(suit deck.Suit, face deck.Face) {
+	g.foundations[suit] = nil
+	for f := deck.ACE; f <= face; f++ {
+		g.foundations[suit] = append(g.foundations[suit], deck.NewCard(f, suit))
+	}
+}
+
+func TestNew(t *testing.T) {
+	g, err := New(Deal(1))
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, 1, g.Number())
+	assert.Equal(t, decktest.Cards("Jd", "Kd", "2s", "4c", "3s", "6d", "6s"), g.Tableau(0))
+	assert.Equal(t, decktest.Cards("5h", "3h", "3c", "7s", "7d", "Tc"), g.Tableau(7))
+	assert.Equal(t, 4, g.NumberOfCells())
+	_, full := g.Cell(0)
+	assert.False(t, full)
+	assert.False(t, g.Won())
+
+	// a deal replays from its signature
+	shuffled, _ := New()
+	assert.Equal(t, 0, shuffled.Number())
+	replay, err := New(WithDeck(deck.FromSignature(shuffled.Signature()), deck.Unshuffled), Cells(2))
+	if assert.Nil(t, err) {
+		assert.Equal(t, shuffled.Tableau(3), replay.Tableau(3))
+		assert.Equal(t, 2, replay.NumberOfCells())
+	}
+
+	tests := []struct {
+		options []func(*Options)
+		err     string
+	}{
+		{[]func(*Options){Deal(-1)}, "Invalid deal number"},
+		{[]func(*Options){Cells(11)}, "Invalid number of cells"},
+		{[]func(*Options){WithDeck(deck.Decks(2))}, "FreeCell needs the 52 cards of a deck, not 104"},
+		{[]func(*Options){WithDeck(deck.Unshuffled, deck.WithCards(append(decktest.Cards("2c", "2c"), decktest.Cards("Ac")[0]+2)...))}, "FreeCell needs the 52 cards of a deck, not 3"},
+	}
+	for _, test := range tests {
+		_, err := New(test.options...)
+		if assert.NotNil(t, err, test.err) {
+			assert.Equal(t, test.err, err.Error())
+		}
+	}
+	cards := decktest.Cards("Ac", "Ac")
+	d, _ := deck.New(deck.Unshuffled)
+	_, err = New(WithDeck(deck.Unshuffled, deck.WithCards(append(cards, d.Cards[2:]...)...)))
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "FreeCell needs the 52 cards of a deck, A♣ is there twice", err.Error())
+	}
+}
+
+func TestSupermove(t *testing.T) {
+	assert.Equal(t, 1, Supermove(0, 0))
+	assert.Equal(t, 5, Supermove(4, 0))
+	assert.Equal(t, 10, Supermove(4, 1))
+	assert.Equal(t, 20, Supermove(4, 2))
+	assert.Equal(t, 4, Supermove(1, 1))
+}
+
+func TestLegalMoves(t *testing.T) {
+	g := empty()
+	g.found(deck.CLUB, deck.ACE)
+	g.cells[0] = decktest.Cards("Qh")[0]
+	g.tableau[0] = decktest.Cards("9h", "8s", "7d", "6c")
+	g.tableau[1] = decktest.Cards("Tc")
+	g.tableau[3] = decktest.Cards("2c")
+	g.tableau[4] = decktest.Cards("Kd", "7s")
+	g.tableau[5] = decktest.Cards("5h")
+	g.tableau[6] = decktest.Cards("Js")
+
+	// three free cells and two empty columns
+	assert.Equal(t, 16, g.Capacity(1))
+	assert.Equal(t, 8, g.Capacity(2))
+	moves := []string{}
+	for _, m := range g.LegalMoves() {
+		moves = append(moves, m.String())
+	}
+	assert.Equal(t, []string{
+		"T4-♣",
+		"T1-T2 x4", "T1-T3", "T1-T3 x2", "T1-T3 x3", "T1-T8", "T1-T8 x2", "T1-T8 x3",
+		"T5-T3", "T5-T8", "T6-T1",
+		"C1-T3", "C1-T8",
+		"T1-C2", "T2-C2", "T4-C2", "T5-C2", "T6-C2", "T7-C2",
+	}, moves)
+
+	assert.Nil(t, g.Move(Move{Kind: TableauToTableau, From: 0, To: 1, Cards: 4}))
+	assert.Equal(t, decktest.Cards("Tc", "9h", "8s", "7d", "6c"), g.Tableau(1))
+	assert.Len(t, g.Tableau(0), 0)
+	assert.Nil(t, g.Move(Move{Kind: TableauToCell, From: 6, To: 3}))
+	c, full := g.Cell(3)
+	assert.True(t, full)
+	assert.Equal(t, decktest.Cards("Js")[0], c)
+	assert.Nil(t, g.Move(Move{Kind: CellToTableau, From: 0, To: 6}))
+	assert.Equal(t, decktest.Cards("Qh"), g.Tableau(6))
+	assert.Len(t, g.Moves(), 3)
+
+	// with the cells full and no empty column, cards move one at a time
+	g.cells[1], g.cells[2] = decktest.Cards("Kc")[0], decktest.Cards("Ks")[0]
+	g.cells[0] = decktest.Cards("Kh")[0]
+	g.tableau[0], g.tableau[2], g.tableau[7] = decktest.Cards("Qs"), decktest.Cards("Jc"), decktest.Cards("4d")
+	assert.Equal(t, 1, g.Capacity(0))
+	illegal := []Move{
+		{Kind: TableauToTableau, From: 1, To: 4, Cards: 2},
+		{Kind: TableauToTableau, From: 1, To: 0, Cards: 4},
+		{Kind: TableauToTableau, From: 1, To: 1, Cards: 1},
+		{Kind: TableauToTableau, From: 1, To: 5, Cards: 5},
+		{Kind: TableauToCell, From: 1, To: 0},
+		{Kind: TableauToCell, From: 1, To: 4},
+		{Kind: TableauToFoundation, From: 8, To: 0},
+		{Kind: CellToTableau, From: 3, To: 0},
+		{Kind: CellToFoundation, From: 0, To: 2},
+		{Kind: MoveKind(9)},
+	}
+	for _, m := range illegal {
+		err := g.Move(m)
+		if assert.NotNil(t, err, m.String()) {
+			assert.Equal(t, "Illegal move "+m.String(), err.Error())
+		}
+	}
+	assert.Len(t, g.Moves(), 3)
+	assert.Nil(t, g.Move(Move{Kind: CellToTableau, From: 3, To: 6}))
+}
+
+func TestAutoMove(t *testing.T) {
+	g := empty()
+	g.found(deck.CLUB, deck.FOUR)
+	g.found(deck.SPADE, deck.THREE)
+	g.found(deck.HEART, deck.THREE)
+	g.tableau[0] = decktest.Cards("Ad")
+	g.tableau[1] = decktest.Cards("4h")
+	g.tableau[2] = decktest.Cards("4s", "2d")
+	g.tableau[3] = decktest.Cards("6c", "5c")
+	g.cells[0] = decktest.Cards("3d")[0]
+	// the 4♥ is safe with the black threes up, the 4♠ once the 3♦ is up too,
+	// and the 5♣ waits for the red fours
+	played := g.AutoMove()
+	assert.Len(t, played, 5)
+	assert.Equal(t, decktest.Cards("Ad", "2d", "3d"), g.Foundation(deck.DIAMOND))
+	assert.Len(t, g.Foundation(deck.HEART), 4)
+	assert.Len(t, g.Foundation(deck.SPADE), 4)
+	assert.Len(t, g.Tableau(2), 0)
+	assert.Equal(t, decktest.Cards("6c", "5c"), g.Tableau(3))
+
+	g, _ = New(Deal(1), AutoPlay)
+	assert.Len(t, g.Moves(), 0)
+	assert.Nil(t, g.Move(Move{Kind: TableauToCell, From: 5, To: 0}))
+	assert.Nil(t, g.Move(Move{Kind: TableauToCell, From: 5, To: 1}))
+	// the A♣, the 2♣ from its cell and the A♠ then go up by themselves
+	assert.Equal(t, decktest.Cards("Ac", "2c"), g.Foundation(deck.CLUB))
+	assert.Equal(t, decktest.Cards("As"), g.Foundation(deck.SPADE))
+	assert.Equal(t, decktest.Cards("7h", "Qc"), g.Tableau(5))
+	assert.Len(t, g.Moves(), 5)
+}
+
+func TestUndo(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	g, _ := New(Deal(3), AutoPlay)
+	start, dealt := g.String(), len(g.Moves())
+	for i := 0; i < 200; i++ {
+		moves := g.LegalMoves()
+		if len(moves) == 0 {
+			break
+		}
+		// a move and the safe moves after it are taken back one at a time
+		before, played := g.String(), len(g.Moves())
+		assert.Nil(t, g.Move(moves[0]))
+		for len(g.Moves()) > played {
+			assert.Nil(t, g.Undo())
+		}
+		assert.Equal(t, before, g.String())
+		assert.Nil(t, g.Move(moves[r.Intn(len(moves))]))
+	}
+	for len(g.Moves()) > dealt {
+		assert.Nil(t, g.Undo())
+	}
+	assert.Equal(t, start, g.String())
+	g, _ = New(Deal(3))
+	err := g.Undo()
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "Nothing to undo", err.Error())
+	}
+}
+
+func TestClone(t *testing.T) {
+	g, _ := New(Deal(1))
+	c := g.Clone()
+	c.Move(Move{Kind: TableauToCell, From: 0, To: 0})
+	_, full := g.Cell(0)
+	assert.False(t, full)
+	assert.Len(t, g.Tableau(0), 7)
+	assert.Len(t, c.Tableau(0), 6)
+}
+
+// TestRandomPlay plays random legal moves and checks no card is lost and the capacity is respected
+func TestRandomPlay(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	for game := 1; game <= 50; game++ {
+		options := []func(*Options){Deal(game)}
+		if game%2 == 0 {
+			options = append(options, Cells(2), AutoPlay)
+		}
+		g, err := New(options...)
+		if !assert.Nil(t, err) {
+			return
+		}
+		for i := 0; i < 300; i++ {
+			moves := g.LegalMoves()
+			if len(moves) == 0 {
+				break
+			}
+			for _, m := range moves {
+				if m.Kind == TableauToTableau {
+					assert.True(t, m.Cards <= g.Capacity(m.To), m.String())
+				}
+			}
+			if !assert.Nil(t, g.Move(moves[r.Intn(len(moves))])) {
+				return
+			}
+			seen := map[deck.Card]bool{}
+			count := func(cards []deck.Card) {
+				for _, c := range cards {
+					assert.False(t, seen[c], c.String())
+					seen[c] = true
+				}
+			}
+			for s := range g.foundations {
+				count(g.Foundation(deck.Suit(s)))
+			}
+			for cell := 0; cell < g.NumberOfCells(); cell++ {
+				if c, full := g.Cell(cell); full {
+					count([]deck.Card{c})
+				}
+			}
+			for col := 0; col < 8; col++ {
+				count(g.Tableau(col))
+			}
+			assert.Len(t, seen, 52)
+		}
+	}
+}
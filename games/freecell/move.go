@@ -0,0 +1,315 @@
+package freecell
+
+import (
+	"errors"
+	"fmt"
+
+	"adamclerk/deck"
+	"adamclerk/deck/games/internal/solitaire"
+)
+
+// MoveKind is what a move does
+type MoveKind int
+
+// Constants for MoveKind
+const (
+	TableauToTableau    MoveKind = iota // moves cards from one column to another
+	TableauToCell                       // puts the bottom card of a column in a cell
+	TableauToFoundation                 // plays the bottom card of a column to its foundation
+	CellToTableau                       // takes a card from a cell onto a column
+	CellToFoundation                    // plays a card from a cell to its foundation
+)
+
+var moveKinds = []string{"TableauToTableau", "TableauToCell", "TableauToFoundation", "CellToTableau", "CellToFoundation"}
+
+func (k MoveKind) String() string {
+	if k < 0 || int(k) >= len(moveKinds) {
+		return fmt.Sprintf("MoveKind(%d)", int(k))
+	}
+	return moveKinds[k]
+}
+
+// Move is a move in a game. From and To are columns, 0 to 7, cells, or the suit of a foundation;
+// Cards is how many cards a tableau to tableau move takes.
+type Move struct {
+	Kind  MoveKind
+	From  int
+	To    int
+	Cards int
+}
+
+// String writes the move short, counted from 1: T1, T2... for the columns, C1, C2... for the cells,
+// and the foundation's suit
+func (m Move) String() string {
+	switch m.Kind {
+	case TableauToTableau:
+		if m.Cards > 1 {
+			return fmt.Sprintf("T%d-T%d x%d", m.From+1, m.To+1, m.Cards)
+		}
+		return fmt.Sprintf("T%d-T%d", m.From+1, m.To+1)
+	case TableauToCell:
+		return fmt.Sprintf("T%d-C%d", m.From+1, m.To+1)
+	case TableauToFoundation:
+		return fmt.Sprintf("T%d-%s", m.From+1, solitaire.SuitName(m.To))
+	case CellToTableau:
+		return fmt.Sprintf("C%d-T%d", m.From+1, m.To+1)
+	case CellToFoundation:
+		return fmt.Sprintf("C%d-%s", m.From+1, solitaire.SuitName(m.To))
+	}
+	return m.Kind.String()
+}
+
+// Supermove returns how many cards can move from column to column at once, as a sequence of single card
+// moves through free cells and empty columns: one more than the free cells, doubled for each empty column.
+// Moving to an empty column, that column doesn't count.
+func Supermove(freeCells, emptyColumns int) int {
+	return (freeCells + 1) << uint(emptyColumns)
+}
+
+// Capacity returns how many cards can move to the column at once, with the free cells and empty columns there are now
+func (g *FreeCell) Capacity(column int) int {
+	cells, columns := g.free()
+	if len(g.tableau[column]) == 0 {
+		columns--
+	}
+	return Supermove(cells, columns)
+}
+
+// free counts the empty cells and columns
+func (g *FreeCell) free() (int, int) {
+	cells, columns := 0, 0
+	for _, c := range g.cells {
+		if c == none {
+			cells++
+		}
+	}
+	for _, p := range g.tableau {
+		if len(p) == 0 {
+			columns++
+		}
+	}
+	return cells, columns
+}
+
+// run returns how many cards at the bottom of the column build down in alternate colours
+func (g *FreeCell) run(column int) int {
+	p := g.tableau[column]
+	if len(p) == 0 {
+		return 0
+	}
+	n := 1
+	for n < len(p) && solitaire.BuildsOn(p[len(p)-n], p[len(p)-n-1]) {
+		n++
+	}
+	return n
+}
+
+// freeCell returns the first empty cell, or -1
+func (g *FreeCell) freeCell() int {
+	for i, c := range g.cells {
+		if c == none {
+			return i
+		}
+	}
+	return -1
+}
+
+// LegalMoves returns every move that can be played now, foundation moves first.
+// Of the empty cells only the first is offered, and moving a whole column to an empty one is left out,
+// since they change nothing.
+func (g *FreeCell) LegalMoves() []Move {
+	moves := []Move{}
+	for i, c := range g.cells {
+		if c != none && g.foundations.Accepts(c) {
+			moves = append(moves, Move{Kind: CellToFoundation, From: i, To: c.Suit()})
+		}
+	}
+	for i, p := range g.tableau {
+		if c, ok := solitaire.Top(p); ok && g.foundations.Accepts(c) {
+			moves = append(moves, Move{Kind: TableauToFoundation, From: i, To: c.Suit()})
+		}
+	}
+	for i, p := range g.tableau {
+		run := g.run(i)
+		for j := range g.tableau {
+			if j == i {
+				continue
+			}
+			capacity := g.Capacity(j)
+			for n := 1; n <= run && n <= capacity; n++ {
+				if n == len(p) && len(g.tableau[j]) == 0 {
+					continue
+				}
+				if g.toTableau(p[len(p)-n], j) {
+					moves = append(moves, Move{Kind: TableauToTableau, From: i, To: j, Cards: n})
+				}
+			}
+		}
+	}
+	for i, c := range g.cells {
+		if c == none {
+			continue
+		}
+		for j := range g.tableau {
+			if g.toTableau(c, j) {
+				moves = append(moves, Move{Kind: CellToTableau, From: i, To: j})
+			}
+		}
+	}
+	if cell := g.freeCell(); cell >= 0 {
+		for i, p := range g.tableau {
+			if len(p) > 0 {
+				moves = append(moves, Move{Kind: TableauToCell, From: i, To: cell})
+			}
+		}
+	}
+	return moves
+}
+
+// toTableau tells you if the card can go on the column: any card on an empty one, or a build
+func (g *FreeCell) toTableau(c deck.Card, column int) bool {
+	t, ok := solitaire.Top(g.tableau[column])
+	return !ok || solitaire.BuildsOn(c, t)
+}
+
+// legal tells you if the move can be played now
+func (g *FreeCell) legal(m Move) bool {
+	switch m.Kind {
+	case TableauToTableau:
+		if !column(m.From) || !column(m.To) || m.From == m.To {
+			return false
+		}
+		p := g.tableau[m.From]
+		if m.Cards < 1 || m.Cards > g.run(m.From) || m.Cards > g.Capacity(m.To) {
+			return false
+		}
+		return g.toTableau(p[len(p)-m.Cards], m.To)
+	case TableauToCell:
+		return column(m.From) && len(g.tableau[m.From]) > 0 && g.cell(m.To) && g.cells[m.To] == none
+	case TableauToFoundation:
+		if !column(m.From) {
+			return false
+		}
+		c, ok := solitaire.Top(g.tableau[m.From])
+		return ok && c.Suit() == m.To && g.foundations.Accepts(c)
+	case CellToTableau:
+		return g.cell(m.From) && g.cells[m.From] != none && column(m.To) && g.toTableau(g.cells[m.From], m.To)
+	case CellToFoundation:
+		if !g.cell(m.From) {
+			return false
+		}
+		c := g.cells[m.From]
+		return c != none && c.Suit() == m.To && g.foundations.Accepts(c)
+	}
+	return false
+}
+
+func column(i int) bool {
+	return i >= 0 && i < 8
+}
+
+func (g *FreeCell) cell(i int) bool {
+	return i >= 0 && i < len(g.cells)
+}
+
+// Move plays a move. With AutoPlay, safe cards then go to the foundations.
+func (g *FreeCell) Move(m Move) error {
+	if !g.legal(m) {
+		return fmt.Errorf("Illegal move %s", m)
+	}
+	g.play(m)
+	if g.autoPlay {
+		g.AutoMove()
+	}
+	return nil
+}
+
+// play plays a legal move and records it
+func (g *FreeCell) play(m Move) {
+	switch m.Kind {
+	case TableauToTableau:
+		p := g.tableau[m.From]
+		at := len(p) - m.Cards
+		g.tableau[m.To] = append(g.tableau[m.To], p[at:]...)
+		g.tableau[m.From] = p[:at]
+	case TableauToCell:
+		p := g.tableau[m.From]
+		g.cells[m.To] = p[len(p)-1]
+		g.tableau[m.From] = p[:len(p)-1]
+	case TableauToFoundation:
+		p := g.tableau[m.From]
+		g.foundations[m.To] = append(g.foundations[m.To], p[len(p)-1])
+		g.tableau[m.From] = p[:len(p)-1]
+	case CellToTableau:
+		g.tableau[m.To] = append(g.tableau[m.To], g.cells[m.From])
+		g.cells[m.From] = none
+	case CellToFoundation:
+		g.foundations[m.To] = append(g.foundations[m.To], g.cells[m.From])
+		g.cells[m.From] = none
+	}
+	g.moves = append(g.moves, m)
+	g.debugf("%s\n", m)
+}
+
+// Undo takes back the last move, automatic moves one at a time
+func (g *FreeCell) Undo() error {
+	if len(g.moves) == 0 {
+		return errors.New("Nothing to undo")
+	}
+	g.takeBack()
+	return nil
+}
+
+func (g *FreeCell) takeBack() {
+	last := len(g.moves) - 1
+	m := g.moves[last]
+	g.moves = g.moves[:last]
+	switch m.Kind {
+	case TableauToTableau:
+		to := g.tableau[m.To]
+		at := len(to) - m.Cards
+		g.tableau[m.From] = append(g.tableau[m.From], to[at:]...)
+		g.tableau[m.To] = to[:at]
+	case TableauToCell:
+		g.tableau[m.From] = append(g.tableau[m.From], g.cells[m.To])
+		g.cells[m.To] = none
+	case TableauToFoundation:
+		f := g.foundations[m.To]
+		g.tableau[m.From] = append(g.tableau[m.From], f[len(f)-1])
+		g.foundations[m.To] = f[:len(f)-1]
+	case CellToTableau:
+		to := g.tableau[m.To]
+		g.cells[m.From] = to[len(to)-1]
+		g.tableau[m.To] = to[:len(to)-1]
+	case CellToFoundation:
+		f := g.foundations[m.To]
+		g.cells[m.From] = f[len(f)-1]
+		g.foundations[m.To] = f[:len(f)-1]
+	}
+}
+
+// AutoMove plays every safe card to the foundations, from the cells and the tableau,
+// and returns the moves it played
+func (g *FreeCell) AutoMove() []Move {
+	played := []Move{}
+	for m, ok := g.nextSafe(); ok; m, ok = g.nextSafe() {
+		g.play(m)
+		played = append(played, m)
+	}
+	return played
+}
+
+// nextSafe returns a move of a safe card to its foundation, from the cells first
+func (g *FreeCell) nextSafe() (Move, bool) {
+	for i, c := range g.cells {
+		if c != none && g.foundations.Safe(c) {
+			return Move{Kind: CellToFoundation, From: i, To: c.Suit()}, true
+		}
+	}
+	for i, p := range g.tableau {
+		if c, ok := solitaire.Top(p); ok && g.foundations.Safe(c) {
+			return Move{Kind: TableauToFoundation, From: i, To: c.Suit()}, true
+		}
+	}
+	return Move{}, false
+}
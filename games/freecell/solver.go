@@ -0,0 +1,216 @@
+package freecell
+
+import (
+	"sort"
+	"time"
+
+	"adamclerk/deck"
+	"adamclerk/deck/games/internal/solitaire"
+)
+
+// Verdict is what the solver found
+type Verdict = solitaire.Verdict
+
+// Constants for Verdict
+const (
+	Solved     = solitaire.Solved     // the moves win the game
+	Unwinnable = solitaire.Unwinnable // every position was searched: no sequence of moves wins
+	Undecided  = solitaire.Undecided  // the search ran out of states or time
+)
+
+// Solution is the solver's answer
+type Solution struct {
+	Verdict Verdict
+	Moves   []Move // the winning moves, when solved
+	States  int    // the positions searched
+}
+
+// SolveOptions how to configure the solver. With every card face up, the mode makes no difference.
+type SolveOptions = solitaire.SolveOptions
+
+// MaxStates sets how many positions the solver searches before it gives up, a million by default
+func MaxStates(count int) func(*SolveOptions) {
+	return solitaire.MaxStates(count)
+}
+
+// Timeout sets how long the solver searches before it gives up; there's no limit by default
+func Timeout(d time.Duration) func(*SolveOptions) {
+	return solitaire.Timeout(d)
+}
+
+// Solve searches for moves that win the game from the current position, which it leaves as it is,
+// in the same way as the Klondike solver. Sequences move as one supermove within the capacity,
+// and moves are tried by how the position looks after them. Every card is in sight from the deal,
+// so a deal the search can't win within its limits is proved unwinnable.
+//
+// The moves replay the win with Move, the safe moves to the foundations included unless the game has AutoPlay.
+func (g *FreeCell) Solve(options ...func(*SolveOptions)) (*Solution, error) {
+	opt, err := solitaire.NewSolveOptions(options...)
+	if err != nil {
+		return nil, err
+	}
+	s := &solver{g: g.Clone()}
+	s.g.debug, s.g.autoPlay = false, false
+	start := len(s.g.moves)
+	s.search = solitaire.NewSearch(s, opt, start)
+
+	solution := &Solution{Verdict: s.search.Run(), States: s.search.States}
+	if solution.Verdict == Solved {
+		for i, m := range s.g.moves[start:] {
+			if !g.autoPlay || !s.search.Auto[start+i] {
+				solution.Moves = append(solution.Moves, m)
+			}
+		}
+	}
+	return solution, nil
+}
+
+// solver plays a game for the search
+type solver struct {
+	g      *FreeCell
+	search *solitaire.Search
+}
+
+// candidate is a move worth trying, with the score of the position it leads to
+type candidate struct {
+	move  Move
+	score int
+}
+
+func (s *solver) Won() bool {
+	return s.g.Won()
+}
+
+func (s *solver) PlaySafe() bool {
+	m, ok := s.g.nextSafe()
+	if ok {
+		s.g.play(m)
+	}
+	return ok
+}
+
+func (s *solver) TakeBack() {
+	s.g.takeBack()
+}
+
+// Candidates turns the moves worth trying into tries for the search. No card is ever face down.
+func (s *solver) Candidates() []solitaire.Candidate {
+	tries := []solitaire.Candidate{}
+	for _, c := range s.candidates() {
+		m := c.move
+		tries = append(tries, func() (int, bool, bool) {
+			return s.play(m), true, false
+		})
+	}
+	return tries
+}
+
+// play plays a move, then the safe moves, and returns how many moves it played
+func (s *solver) play(m Move) int {
+	return s.search.Play(func() { s.g.play(m) })
+}
+
+// Key writes the position. Cells are sorted like the columns, and the foundations follow from the cards left.
+func (s *solver) Key(k *solitaire.Key) {
+	g := s.g
+	for _, p := range g.tableau {
+		k.Column(0, p)
+	}
+	cells := make([]deck.Card, 0, len(g.cells))
+	for _, c := range g.cells {
+		if c != none {
+			cells = append(cells, c)
+		}
+	}
+	sort.Slice(cells, func(i, j int) bool { return cells[i] < cells[j] })
+	k.Cards(cells)
+}
+
+// candidates returns the moves worth trying, the most promising first, by how the position looks after each.
+// Safe moves to the foundations have been played already, and of several empty cells or columns
+// only the first is tried.
+func (s *solver) candidates() []candidate {
+	g := s.g
+	moves := []candidate{}
+	add := func(m Move) {
+		moves = append(moves, candidate{move: m})
+	}
+	firstEmpty := -1
+	for j, p := range g.tableau {
+		if len(p) == 0 {
+			firstEmpty = j
+			break
+		}
+	}
+
+	for i, c := range g.cells {
+		if c != none && g.foundations.Accepts(c) {
+			add(Move{Kind: CellToFoundation, From: i, To: c.Suit()})
+		}
+	}
+	for i, p := range g.tableau {
+		if c, ok := solitaire.Top(p); ok && g.foundations.Accepts(c) {
+			add(Move{Kind: TableauToFoundation, From: i, To: c.Suit()})
+		}
+	}
+	for i, c := range g.cells {
+		if c == none {
+			continue
+		}
+		for j, p := range g.tableau {
+			if len(p) > 0 && g.toTableau(c, j) || j == firstEmpty {
+				add(Move{Kind: CellToTableau, From: i, To: j})
+			}
+		}
+	}
+	for i, p := range g.tableau {
+		run := g.run(i)
+		for j, q := range g.tableau {
+			if j == i || len(q) == 0 && j != firstEmpty {
+				continue
+			}
+			capacity := g.Capacity(j)
+			for n := 1; n <= run && n <= capacity; n++ {
+				if len(q) == 0 && n < len(p) || len(q) > 0 && solitaire.BuildsOn(p[len(p)-n], q[len(q)-1]) {
+					add(Move{Kind: TableauToTableau, From: i, To: j, Cards: n})
+				}
+			}
+		}
+	}
+	if cell := g.freeCell(); cell >= 0 {
+		for i, p := range g.tableau {
+			if len(p) > 0 {
+				add(Move{Kind: TableauToCell, From: i, To: cell})
+			}
+		}
+	}
+
+	for i := range moves {
+		played := s.play(moves[i].move)
+		moves[i].score = s.evaluate()
+		s.search.TakeBack(played)
+	}
+	sort.SliceStable(moves, func(i, j int) bool { return moves[i].score > moves[j].score })
+	return moves
+}
+
+// evaluate scores a position: cards up, free cells and empty columns, built sequences,
+// less the cards covering the next card of each foundation
+func (s *solver) evaluate() int {
+	g := s.g
+	score := 0
+	for _, f := range g.foundations {
+		score += 40 * len(f)
+	}
+	cells, columns := g.free()
+	score += 15*cells + 30*columns
+	for i, p := range g.tableau {
+		score += 3 * g.run(i)
+		for k, c := range p {
+			if g.foundations.Accepts(c) {
+				score -= 8 * (len(p) - 1 - k)
+			}
+		}
+	}
+	return score
+}
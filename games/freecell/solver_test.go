@@ -0,0 +1,39 @@
+package freecell
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSolve(t *testing.T) {
+	tests := []struct {
+		options []func(*Options)
+		verdict Verdict
+	}{
+		{[]func(*Options){Deal(1)}, Solved},
+		{[]func(*Options){Deal(617), AutoPlay}, Solved},
+		{[]func(*Options){Deal(2), Cells(3)}, Solved},
+		// the one classic deal that can't be won
+		{[]func(*Options){Deal(11982)}, Unwinnable},
+		{[]func(*Options){Deal(11982), Cells(5)}, Solved},
+		{[]func(*Options){Deal(3), Cells(1)}, Unwinnable},
+	}
+	for i, test := range tests {
+		g, _ := New(test.options...)
+		before := g.String()
+		solution, err := g.Solve(MaxStates(200000))
+		if !assert.Nil(t, err, i) {
+			continue
+		}
+		assert.Equal(t, test.verdict, solution.Verdict, i)
+		assert.True(t, solution.States > 0, i)
+		assert.Equal(t, before, g.String(), i)
+		// the moves win a new game, AutoPlay or not
+		replay, _ := New(test.options...)
+		for _, m := range solution.Moves {
+			assert.Nil(t, replay.Move(m), i)
+		}
+		assert.Equal(t, solution.Verdict == Solved, replay.Won(), i)
+	}
+}
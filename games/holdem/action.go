@@ -0,0 +1,123 @@
+package holdem
+
+import (
+	"fmt"
+
+	"adamclerk/deck"
+)
+
+// Street is a betting round of a hand
+type Street int
+
+// Constants for Street
+const (
+	Preflop Street = iota
+	Flop
+	Turn
+	River
+	Showdown
+)
+
+func (s Street) String() string {
+	switch s {
+	case Preflop:
+		return "Preflop"
+	case Flop:
+		return "Flop"
+	case Turn:
+		return "Turn"
+	case River:
+		return "River"
+	case Showdown:
+		return "Showdown"
+	}
+	return fmt.Sprintf("Street(%d)", int(s))
+}
+
+// ActionType is what a player does when it's their turn, or a forced bet
+type ActionType int
+
+// Constants for ActionType
+const (
+	Fold ActionType = iota
+	Check
+	Call
+	Bet
+	Raise
+	AllIn
+	PostAnte
+	PostSmallBlind
+	PostBigBlind
+)
+
+func (a ActionType) String() string {
+	names := []string{"Fold", "Check", "Call", "Bet", "Raise", "AllIn", "PostAnte", "PostSmallBlind", "PostBigBlind"}
+	if a < 0 || int(a) >= len(names) {
+		return fmt.Sprintf("ActionType(%d)", int(a))
+	}
+	return names[a]
+}
+
+// Action is a player's decision. For Bet and Raise, Amount is the total the
+// player's bet for the street is made to ("raise to"); it's ignored otherwise.
+// Bet and Raise are interchangeable, the hand records whichever applies.
+// AllIn bets or raises every chip the player has, or only calls (or checks)
+// when the player isn't allowed to raise.
+type Action struct {
+	Type   ActionType
+	Amount int
+}
+
+// Decider chooses a player's actions. Bots and humans plug in by implementing it.
+type Decider interface {
+	Act(state State) Action
+}
+
+// DeciderFunc adapts a function to the Decider interface
+type DeciderFunc func(state State) Action
+
+// Act calls f(state)
+func (f DeciderFunc) Act(state State) Action {
+	return f(state)
+}
+
+// PlayerState is what everyone can see of a player
+type PlayerState struct {
+	Name   string
+	Stack  int  // chips behind
+	Bet    int  // chips bet on this street
+	InHand bool // dealt in and not folded
+	AllIn  bool
+}
+
+// State is what a player sees when it's their turn to act
+type State struct {
+	Street     Street
+	Seat       int
+	Hole       []deck.Card
+	Board      []deck.Card
+	Button     int
+	Pot        int // every chip committed so far, including this street's bets
+	CurrentBet int // the highest bet on this street
+	ToCall     int // chips needed to call
+	MinRaiseTo int // the smallest total a bet or raise can be made to
+	MaxRaiseTo int // the total bet when going all in
+	CanRaise   bool
+	Players    []PlayerState
+	Actions    []ActionRecord // everything that happened this hand so far
+}
+
+// CanCheck tells you if checking is allowed
+func (s State) CanCheck() bool {
+	return s.ToCall == 0
+}
+
+// ActionRecord is an action as it happened, with the chips it moved
+type ActionRecord struct {
+	Street Street
+	Seat   int
+	Type   ActionType
+	Amount int  // chips put in by the action
+	To     int  // the player's total bet for the street afterwards
+	AllIn  bool // the action put the player all in
+}
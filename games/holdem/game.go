@@ -0,0 +1,509 @@
+// Package holdem is a No-Limit Texas Hold'em engine.
+package holdem
+
+import (
+	"errors"
+	"fmt"
+
+	"adamclerk/deck"
+	"adamclerk/deck/games/internal/betting"
+	"adamclerk/deck/poker"
+	"adamclerk/deck/pot"
+)
+
+// Holdem is a table of players playing No-Limit Texas Hold'em, hand after hand.
+// Rules can be found here: https://www.pokernews.com/poker-rules/texas-holdem.htm
+type Holdem struct {
+	debug       bool
+	deckOptions []func(*deck.Options)
+	smallBlind  int
+	bigBlind    int
+	ante        int
+	maxHands    int
+	hands       int
+	button      int
+	players     []*Player
+}
+
+// Seat describes a player joining the table
+type Seat struct {
+	Name    string
+	Stack   int
+	Decider Decider
+}
+
+// Options how to configure a game of Hold'em
+type Options struct {
+	DeckOptions []func(*deck.Options)
+	Seats       []Seat
+	SmallBlind  int
+	BigBlind    int
+	Ante        int
+	Button      int
+	MaxHands    int
+	Debug       bool
+}
+
+// Player is a player at the table
+type Player struct {
+	name    string
+	stack   int
+	decider Decider
+}
+
+// Name returns the players name for verification and announcement
+func (p Player) Name() string {
+	return p.name
+}
+
+// Stack returns the chips the player has
+func (p Player) Stack() int {
+	return p.stack
+}
+
+// WithDeck allows the a game to be configured with a specific deck.
+// A new deck is created with these options for every hand.
+func WithDeck(deckOptions ...func(*deck.Options)) func(*Options) {
+	return func(o *Options) {
+		o.DeckOptions = deckOptions
+	}
+}
+
+// WithSeat adds a player to the table. Players sit in the order they are added.
+func WithSeat(name string, stack int, decider Decider) func(*Options) {
+	return func(o *Options) {
+		o.Seats = append(o.Seats, Seat{Name: name, Stack: stack, Decider: decider})
+	}
+}
+
+// Blinds sets the small and big blinds
+func Blinds(small, big int) func(*Options) {
+	return func(o *Options) {
+		o.SmallBlind = small
+		o.BigBlind = big
+	}
+}
+
+// Ante sets the ante every player posts before each hand
+func Ante(amount int) func(*Options) {
+	return func(o *Options) {
+		o.Ante = amount
+	}
+}
+
+// Button sets the seat of the dealer button for the first hand
+func Button(seat int) func(*Options) {
+	return func(o *Options) {
+		o.Button = seat
+	}
+}
+
+// MaxHands before Play returns an error.
+func MaxHands(hands int) func(*Options) {
+	return func(o *Options) {
+		o.MaxHands = hands
+	}
+}
+
+// Debug sets the debug param for the game
+func Debug(o *Options) {
+	o.Debug = true
+}
+
+// MaxSeats is the most seats a deck can deal a hand to: two hole cards each, three burns and five board cards
+const MaxSeats = 22
+
+// New function creates a new game of Hold'em
+func New(options ...func(*Options)) (*Holdem, error) {
+	opt := Options{DeckOptions: []func(*deck.Options){}, SmallBlind: 1, BigBlind: 2, MaxHands: 100000}
+	for _, option := range options {
+		option(&opt)
+	}
+	if err := betting.CheckSeats(len(opt.Seats), MaxSeats); err != nil {
+		return nil, err
+	}
+	if opt.BigBlind <= 0 || opt.SmallBlind < 0 || opt.Ante < 0 {
+		return nil, errors.New("Invalid blinds")
+	}
+	if opt.Button < 0 || opt.Button >= len(opt.Seats) {
+		return nil, errors.New("Invalid button")
+	}
+
+	h := &Holdem{
+		debug:       opt.Debug,
+		deckOptions: opt.DeckOptions,
+		smallBlind:  opt.SmallBlind,
+		bigBlind:    opt.BigBlind,
+		ante:        opt.Ante,
+		maxHands:    opt.MaxHands,
+		button:      opt.Button,
+	}
+	for _, s := range opt.Seats {
+		if s.Decider == nil {
+			return nil, errors.New("Seat " + s.Name + " has no decider")
+		}
+		h.players = append(h.players, &Player{name: s.Name, stack: s.Stack, decider: s.Decider})
+	}
+	return h, nil
+}
+
+// Players returns the players in seat order
+func (h *Holdem) Players() []*Player {
+	return h.players
+}
+
+// Button returns the seat of the dealer button for the next hand
+func (h *Holdem) Button() int {
+	return h.button
+}
+
+// Play plays hands until one player has all the chips
+func (h *Holdem) Play() error {
+	for h.activePlayers() > 1 {
+		if h.hands >= h.maxHands {
+			return errors.New("Too many hands played")
+		}
+		if _, err := h.PlayHand(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Winner announces the winner, the only player with chips left
+func (h *Holdem) Winner() []Player {
+	var winners []Player
+	for _, p := range h.players {
+		if p.stack > 0 {
+			winners = append(winners, *p)
+		}
+	}
+	return winners
+}
+
+func (h *Holdem) activePlayers() int {
+	return betting.Active(h.stacks())
+}
+
+// nextActive returns the next seat after seat with chips
+func (h *Holdem) nextActive(seat int) int {
+	return betting.NextActive(h.stacks(), seat)
+}
+
+// stacks returns the players' chips in seat order
+func (h *Holdem) stacks() []int {
+	stacks := make([]int, len(h.players))
+	for i, p := range h.players {
+		stacks[i] = p.stack
+	}
+	return stacks
+}
+
+// HandResult is the record of a hand
+type HandResult struct {
+	Number     int
+	Button     int
+	SmallBlind int // seat of the small blind
+	BigBlind   int // seat of the big blind
+	Signature  string
+	Stacks     []int         // stacks at the start of the hand, by seat
+	Hole       [][]deck.Card // hole cards by seat, nil for players not dealt in
+	Board      []deck.Card
+	Actions    []ActionRecord
+	Pots       []pot.Pot
+	Shown      []bool            // players who showed their cards at showdown, by seat
+	Hands      []poker.HandValue // the value of shown hands, by seat
+	Winnings   []int             // chips won, by seat
+}
+
+// PlayHand plays a single hand and moves the button.
+// If a player's decider returns an illegal action the hand is abandoned,
+// every stack is restored and an error is returned.
+func (h *Holdem) PlayHand() (*HandResult, error) {
+	if h.activePlayers() < 2 {
+		return nil, errors.New("Not enough players with chips")
+	}
+	if h.players[h.button].stack == 0 {
+		h.button = h.nextActive(h.button)
+	}
+	h.hands++
+
+	d, err := deck.New(h.deckOptions...)
+	if err != nil {
+		return nil, err
+	}
+	n := &hand{
+		Hand: betting.New(h.stacks()),
+		game: h,
+		deck: d,
+		result: &HandResult{
+			Number:    h.hands,
+			Button:    h.button,
+			Signature: d.GetSignature(),
+			Stacks:    h.stacks(),
+			Hole:      make([][]deck.Card, len(h.players)),
+			Shown:     make([]bool, len(h.players)),
+			Hands:     make([]poker.HandValue, len(h.players)),
+			Winnings:  make([]int, len(h.players)),
+		},
+	}
+	if err := n.play(); err != nil {
+		return nil, err
+	}
+	for i, p := range h.players {
+		p.stack = n.Stacks[i]
+	}
+	h.button = h.nextActive(h.button)
+	return n.result, nil
+}
+
+// hand is the state of the hand being played
+type hand struct {
+	*betting.Hand
+	game      *Holdem
+	deck      *deck.Deck
+	street    Street
+	lastRaise int // the size of the last full bet or raise
+	result    *HandResult
+}
+
+func (n *hand) debugf(format string, a ...interface{}) {
+	deck.Debugf(n.game.debug, format, a...)
+}
+
+func (n *hand) player(seat int) *Player {
+	return n.game.players[seat]
+}
+
+// put moves chips from a player's stack to their bet, all in if the stack is short, and records the action
+func (n *hand) put(seat, amount int, t ActionType, toBet bool) {
+	amount = n.Put(seat, amount, toBet)
+	n.result.Actions = append(n.result.Actions, ActionRecord{
+		Street: n.street,
+		Seat:   seat,
+		Type:   t,
+		Amount: amount,
+		To:     n.Bets[seat],
+		AllIn:  n.Stacks[seat] == 0,
+	})
+	n.debugf("  %s: %s %d\n", n.player(seat).name, t, amount)
+}
+
+func (n *hand) play() error {
+	g := n.game
+	n.debugf("Hand %d Started, button %s\n", n.result.Number, n.player(g.button).name)
+
+	// Antes and blinds. Heads up the button posts the small blind.
+	if g.ante > 0 {
+		for s := n.Next(g.button); ; s = n.Next(s) {
+			n.put(s, g.ante, PostAnte, false)
+			if s == g.button {
+				break
+			}
+		}
+	}
+	sb := n.Next(g.button)
+	if n.InHand() == 2 {
+		sb = g.button
+	}
+	bb := n.Next(sb)
+	n.result.SmallBlind, n.result.BigBlind = sb, bb
+	n.put(sb, g.smallBlind, PostSmallBlind, true)
+	n.put(bb, g.bigBlind, PostBigBlind, true)
+	n.CurrentBet = g.bigBlind
+	n.lastRaise = g.bigBlind
+
+	// Deal two hole cards, one at a time, starting left of the button
+	var seats []int
+	for s := n.Next(g.button); ; s = n.Next(s) {
+		seats = append(seats, s)
+		if s == g.button {
+			break
+		}
+	}
+	holes := make([]*deck.Deck, len(seats))
+	for i := range holes {
+		holes[i], _ = deck.New(deck.Empty)
+	}
+	if n.deck.NumberOfCards() < 2*len(seats)+8 {
+		return errors.New("Not enough cards in the deck")
+	}
+	n.deck.Deal(2, holes...)
+	for i, s := range seats {
+		n.result.Hole[s] = holes[i].Cards
+	}
+
+	if err := n.Round(n.Next(bb), n.act); err != nil {
+		return err
+	}
+
+	board, _ := deck.New(deck.Empty)
+	burn, _ := deck.New(deck.Empty)
+	for _, street := range []Street{Flop, Turn, River} {
+		if n.InHand() < 2 {
+			break
+		}
+		n.street = street
+		n.deck.Deal(1, burn)
+		if street == Flop {
+			n.deck.Deal(3, board)
+		} else {
+			n.deck.Deal(1, board)
+		}
+		n.result.Board = board.Cards
+		n.debugf("%s: %s\n", street, cardList(board.Cards))
+
+		n.NewStreet()
+		n.lastRaise = g.bigBlind
+		if err := n.Round(n.Next(g.button), n.act); err != nil {
+			return err
+		}
+	}
+	n.street = Showdown
+	return n.award()
+}
+
+func (n *hand) state(seat int) State {
+	s := State{
+		Street:     n.street,
+		Seat:       seat,
+		Hole:       append([]deck.Card{}, n.result.Hole[seat]...),
+		Board:      append([]deck.Card{}, n.result.Board...),
+		Button:     n.game.button,
+		CurrentBet: n.CurrentBet,
+		ToCall:     n.ToCall(seat),
+		MinRaiseTo: n.CurrentBet + n.lastRaise,
+		MaxRaiseTo: n.Bets[seat] + n.Stacks[seat],
+		CanRaise:   n.CanRaise(seat),
+		Pot:        n.Pot(),
+		Actions:    append([]ActionRecord{}, n.result.Actions...),
+	}
+	if s.MinRaiseTo > s.MaxRaiseTo {
+		s.MinRaiseTo = s.MaxRaiseTo
+	}
+	for i, q := range n.game.players {
+		s.Players = append(s.Players, PlayerState{
+			Name:   q.name,
+			Stack:  n.Stacks[i],
+			Bet:    n.Bets[i],
+			InHand: !n.Folded[i],
+			AllIn:  n.AllIn(i),
+		})
+	}
+	return s
+}
+
+// act asks a player for an action and applies it
+func (n *hand) act(seat int) error {
+	p := n.player(seat)
+	s := n.state(seat)
+	a := p.decider.Act(s)
+	invalid := func(reason string) error {
+		return fmt.Errorf("Invalid action %s %d by %s: %s", a.Type, a.Amount, p.name, reason)
+	}
+
+	switch a.Type {
+	case Fold:
+		n.Folded[seat] = true
+		n.put(seat, 0, Fold, true)
+		return nil
+	case Check:
+		if s.ToCall > 0 {
+			return invalid("there is a bet to call")
+		}
+		n.put(seat, 0, Check, true)
+		return nil
+	case Call:
+		if s.ToCall == 0 {
+			return invalid("there is nothing to call")
+		}
+		n.put(seat, s.ToCall, Call, true)
+		return nil
+	case Bet, Raise, AllIn:
+		to := a.Amount
+		if a.Type == AllIn {
+			to = s.MaxRaiseTo
+			if !s.CanRaise {
+				// going all in when raising isn't allowed calls what it can
+				to = n.Bets[seat] + s.ToCall
+			}
+		}
+		if to > s.MaxRaiseTo {
+			return invalid("not enough chips")
+		}
+		if to <= n.CurrentBet {
+			if a.Type == AllIn && to > n.Bets[seat] {
+				// all in for no more than the current bet is a call
+				n.put(seat, to-n.Bets[seat], Call, true)
+				return nil
+			}
+			if a.Type == AllIn {
+				n.put(seat, 0, Check, true)
+				return nil
+			}
+			return invalid("a raise must be more than the current bet")
+		}
+		if !s.CanRaise {
+			return invalid("betting is not open to this player")
+		}
+		if to < s.MinRaiseTo && to < s.MaxRaiseTo {
+			return invalid(fmt.Sprintf("the minimum is %d", s.MinRaiseTo))
+		}
+		t := Raise
+		if n.CurrentBet == 0 {
+			t = Bet
+		}
+		if raise := to - n.CurrentBet; raise >= n.lastRaise {
+			// a full raise reopens the betting for everyone
+			n.lastRaise = raise
+			n.Reopen(seat)
+		}
+		n.CurrentBet = to
+		n.put(seat, to-n.Bets[seat], t, true)
+		return nil
+	}
+	return invalid("unknown action")
+}
+
+// award gives the pots to the winners, at showdown or to the last player left
+func (n *hand) award() error {
+	r := n.result
+	showdown := n.InHand() > 1
+	values := make([]int, len(n.Folded))
+	for i, f := range n.Folded {
+		if f || !showdown {
+			continue
+		}
+		cards := append(append([]deck.Card{}, r.Hole[i]...), r.Board...)
+		h := poker.NewHand(cards...)
+		values[i] = h.Value()
+		r.Hands[i] = h.Evaluate()
+		r.Shown[i] = true
+		n.debugf("  %s shows %s: %s\n", n.player(i).name, cardList(r.Hole[i]), r.Hands[i])
+	}
+
+	// odd chips go to the winners closest to the left of the button
+	split, err := n.Award(values, pot.LeftOfButton(n.game.button, len(values)))
+	if err != nil {
+		return err
+	}
+	r.Pots = split.Pots
+	r.Winnings = split.Winnings
+	for i, won := range r.Winnings {
+		if won > 0 {
+			n.debugf("  %s wins %d\n", n.player(i).name, won)
+		}
+	}
+	return nil
+}
+
+func cardList(cards []deck.Card) string {
+	str := ""
+	for i, c := range cards {
+		if i > 0 {
+			str += " "
+		}
+		str += c.String()
+	}
+	return str
+}
@@ -0,0 +1,108 @@
+package holdem
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"adamclerk/deck"
+)
+
+// callingStation checks when it can and calls otherwise
+var callingStation = DeciderFunc(func(s State) Action {
+	if s.CanCheck() {
+		return Action{Type: Check}
+	}
+	return Action{Type: Call}
+})
+
+// This example uses a stacked deck to get a specific result
+func Example() {
+	cards := []deck.Card{
+		deck.NewCard(deck.KING, deck.DIAMOND), // Bob, left of the button, is dealt first
+		deck.NewCard(deck.ACE, deck.HEART),
+		deck.NewCard(deck.KING, deck.CLUB),
+		deck.NewCard(deck.ACE, deck.SPADE),
+	}
+	full, _ := deck.New(deck.Unshuffled)
+	for _, c := range full.Cards {
+		if c != cards[0] && c != cards[1] && c != cards[2] && c != cards[3] {
+			cards = append(cards, c)
+		}
+	}
+	game, err := New(
+		WithDeck(deck.Unshuffled, deck.WithCards(cards...)),
+		WithSeat("Alice", 100, callingStation),
+		WithSeat("Bob", 100, callingStation),
+	)
+	if err != nil {
+		panic(err)
+	}
+	result, err := game.PlayHand()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("Board: %s\n", cardList(result.Board))
+	for i, p := range game.Players() {
+		fmt.Printf("%s: %s, %d chips\n", p.Name(), result.Hands[i], p.Stack())
+	}
+	// Output:
+	// Board: 2♣ 3♣ 4♣ 6♣ 8♣
+	// Alice: Flush (8 6 4 3 2), 98 chips
+	// Bob: Flush (K 8 6 4 3), 102 chips
+}
+
+// This example writes a hand history, reads it back and replays the hand from the deck signature
+func ExampleParseHistories() {
+	game, _ := New(
+		WithDeck(deck.Unshuffled),
+		WithSeat("Alice", 100, callingStation),
+		WithSeat("Bob", 100, callingStation),
+	)
+	result, _ := game.PlayHand()
+	hist := game.History(result, 0)
+	hist.Time = time.Date(2020, 1, 12, 6, 15, 36, 0, time.UTC)
+	text := hist.String()
+	fmt.Println(strings.TrimSpace(text))
+
+	histories, err := ParseHistories(strings.NewReader(text))
+	if err != nil {
+		panic(err)
+	}
+	replayed, err := histories[0].Replay()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("Replayed board: %s\n", cardList(replayed.Board))
+	// Output:
+	// PokerStars Hand #1:  Hold'em No Limit (1/2) - 2020/01/12 6:15:36 UTC
+	// Table 'deck' 2-max (Play Money) Seat #1 is the button
+	// Seat 1: Alice (100 in chips)
+	// Seat 2: Bob (100 in chips)
+	// Alice: posts small blind 1
+	// Bob: posts big blind 2
+	// *** HOLE CARDS ***
+	// Dealt to Alice [2c 4c]
+	// Alice: calls 1
+	// Bob: checks
+	// *** FLOP *** [6c 7c 8c]
+	// Bob: checks
+	// Alice: checks
+	// *** TURN *** [6c 7c 8c] [Tc]
+	// Bob: checks
+	// Alice: checks
+	// *** RIVER *** [6c 7c 8c Tc] [Qc]
+	// Bob: checks
+	// Alice: checks
+	// *** SHOW DOWN ***
+	// Alice: shows [2c 4c] (Flush (Q T 8 7 6))
+	// Bob: shows [Ac 3c] (Flush (A Q T 8 7))
+	// Bob collected 4 from pot
+	// *** SUMMARY ***
+	// Total pot 4 | Rake 0
+	// Board [6c 7c 8c Tc Qc]
+	// Seat 1: Alice (button) (small blind) showed [2c 4c] and lost with Flush (Q T 8 7 6)
+	// Seat 2: Bob (big blind) showed [Ac 3c] and won (4) with Flush (A Q T 8 7)
+	// Deck: 00102030405060708090a0b0c001112131415161718191a1b1c102122232425262728292a2b2c203132333435363738393a3b3c3
+	// Replayed board: 6♣ 7♣ 8♣ T♣ Q♣
+}
@@ -0,0 +1,285 @@
+package holdem
+
+import (
+	"math/rand"
+	"testing"
+
+	"adamclerk/deck"
+	"adamclerk/deck/internal/decktest"
+	"adamclerk/deck/pot"
+	"github.com/stretchr/testify/assert"
+)
+
+// script plays the actions in order and then checks or calls
+func script(actions ...Action) Decider {
+	return DeciderFunc(func(s State) Action {
+		if len(actions) == 0 {
+			return passive(s)
+		}
+		a := actions[0]
+		actions = actions[1:]
+		return a
+	})
+}
+
+// passive checks when it can and calls otherwise
+func passive(s State) Action {
+	if s.CanCheck() {
+		return Action{Type: Check}
+	}
+	return Action{Type: Call}
+}
+
+func shove(s State) Action {
+	return Action{Type: AllIn}
+}
+
+// stacked returns a deck option dealing the given cards first; the rest of the deck follows in order.
+func stacked(cards ...string) func(*Options) {
+	return WithDeck(decktest.Stacked(cards...)...)
+}
+
+func stacks(g *Holdem) []int {
+	var result []int
+	for _, p := range g.Players() {
+		result = append(result, p.Stack())
+	}
+	return result
+}
+
+func TestFoldToTheBigBlind(t *testing.T) {
+	fold := Action{Type: Fold}
+	game, _ := New(
+		WithSeat("Button", 100, script(fold)),
+		WithSeat("Small", 100, script(fold)),
+		WithSeat("Big", 100, script()),
+	)
+	result, err := game.PlayHand()
+	assert.Nil(t, err)
+	assert.Equal(t, []int{100, 99, 101}, stacks(game))
+	assert.Equal(t, []int{0, 0, 3}, result.Winnings)
+	assert.Equal(t, 1, result.SmallBlind)
+	assert.Equal(t, 2, result.BigBlind)
+	assert.Equal(t, 0, len(result.Board))
+	assert.Equal(t, 1, game.Button())
+}
+
+func TestHeadsUpButtonPostsSmallBlind(t *testing.T) {
+	game, _ := New(
+		WithSeat("Alice", 100, DeciderFunc(passive)),
+		WithSeat("Bob", 100, DeciderFunc(passive)),
+	)
+	result, err := game.PlayHand()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, result.SmallBlind)
+	assert.Equal(t, 1, result.BigBlind)
+
+	var preflop, flop []int
+	for _, a := range result.Actions {
+		if a.Type == PostSmallBlind || a.Type == PostBigBlind {
+			continue
+		}
+		if a.Street == Preflop {
+			preflop = append(preflop, a.Seat)
+		}
+		if a.Street == Flop {
+			flop = append(flop, a.Seat)
+		}
+	}
+	// the button acts first before the flop and last after it
+	assert.Equal(t, []int{0, 1}, preflop)
+	assert.Equal(t, []int{1, 0}, flop)
+	assert.Equal(t, 5, len(result.Board))
+}
+
+func TestShowdownWithBurnCards(t *testing.T) {
+	game, _ := New(
+		stacked(
+			"Kd", "Ah", // Bob, Alice: the deal starts left of the button
+			"Kc", "As",
+			"2c",             // burn
+			"Ad", "7s", "8d", // flop
+			"3c", "9h", // burn, turn
+			"4c", "Th", // burn, river
+		),
+		WithSeat("Alice", 100, DeciderFunc(passive)),
+		WithSeat("Bob", 100, DeciderFunc(passive)),
+	)
+	result, err := game.PlayHand()
+	assert.Nil(t, err)
+	assert.Equal(t, "A♦ 7♠ 8♦ 9♥ T♥", cardList(result.Board))
+	assert.Equal(t, []bool{true, true}, result.Shown)
+	assert.Equal(t, "Three of a Kind (A T 9)", result.Hands[0].String())
+	assert.Equal(t, []int{102, 98}, stacks(game))
+}
+
+func TestSidePots(t *testing.T) {
+	game, _ := New(
+		stacked(
+			"Kd", "Qd", "Ah", // Small, Big, Button
+			"Kc", "Qc", "As",
+			"2h", "7d", "8s", "9c", "3h", "Js", "4h", "2s",
+		),
+		WithSeat("Button", 50, DeciderFunc(shove)),
+		WithSeat("Small", 100, DeciderFunc(shove)),
+		WithSeat("Big", 200, DeciderFunc(shove)),
+	)
+	result, err := game.PlayHand()
+	assert.Nil(t, err)
+	assert.Equal(t, []pot.Pot{
+		{Amount: 150, Eligible: []int{0, 1, 2}, Winners: []int{0}},
+		{Amount: 100, Eligible: []int{1, 2}, Winners: []int{1}},
+	}, result.Pots)
+	// Big only calls the 100 Small can cover
+	assert.Equal(t, []int{150, 100, 100}, stacks(game))
+}
+
+func TestSplitPotOddChip(t *testing.T) {
+	game, _ := New(
+		stacked(
+			"2c", "2d", "7h", // Small, Big, Button
+			"3c", "3d", "8h",
+			"4s", "Ts", "Js", "Qs", "4h", "Ks", "4d", "As",
+		),
+		Ante(1),
+		WithSeat("Button", 100, script(Action{Type: Fold})),
+		WithSeat("Small", 100, DeciderFunc(passive)),
+		WithSeat("Big", 100, DeciderFunc(passive)),
+	)
+	result, err := game.PlayHand()
+	assert.Nil(t, err)
+	// the board plays: 7 chips split, the odd chip to the small blind
+	assert.Equal(t, 7, result.Pots[0].Amount)
+	assert.Equal(t, []int{1, 2}, result.Pots[0].Winners)
+	assert.Equal(t, []int{99, 101, 100}, stacks(game))
+}
+
+func TestMinimumRaise(t *testing.T) {
+	game, _ := New(
+		WithSeat("Alice", 100, script(Action{Type: Raise, Amount: 3})),
+		WithSeat("Bob", 100, DeciderFunc(passive)),
+	)
+	_, err := game.PlayHand()
+	assert.Equal(t, "Invalid action Raise 3 by Alice: the minimum is 4", err.Error())
+	// the hand is abandoned and the chips go back
+	assert.Equal(t, []int{100, 100}, stacks(game))
+}
+
+func TestShortAllInDoesNotReopenBetting(t *testing.T) {
+	game, _ := New(
+		WithSeat("Alice", 100, script(Action{Type: Raise, Amount: 10}, Action{Type: Raise, Amount: 40})),
+		WithSeat("Bob", 15, DeciderFunc(shove)),
+	)
+	_, err := game.PlayHand()
+	assert.Equal(t, "Invalid action Raise 40 by Alice: betting is not open to this player", err.Error())
+
+	game, _ = New(
+		WithSeat("Alice", 100, script(Action{Type: Raise, Amount: 10})),
+		WithSeat("Bob", 15, DeciderFunc(shove)),
+	)
+	result, err := game.PlayHand()
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(result.Board))
+	assert.Equal(t, 115, stacks(game)[0]+stacks(game)[1])
+}
+
+func TestIllegalActions(t *testing.T) {
+	for action, message := range map[Action]string{
+		{Type: Check}:              "Invalid action Check 0 by Alice: there is a bet to call",
+		{Type: ActionType(42)}:     "Invalid action ActionType(42) 0 by Alice: unknown action",
+		{Type: Raise, Amount: 500}: "Invalid action Raise 500 by Alice: not enough chips",
+		{Type: Raise, Amount: 2}:   "Invalid action Raise 2 by Alice: a raise must be more than the current bet",
+	} {
+		game, _ := New(WithSeat("Alice", 100, script(action)), WithSeat("Bob", 100, DeciderFunc(passive)))
+		_, err := game.PlayHand()
+		assert.Equal(t, message, err.Error())
+	}
+}
+
+func TestPlayUntilOnePlayerIsLeft(t *testing.T) {
+	deck.Seed()
+	game, _ := New(
+		WithSeat("Alice", 100, DeciderFunc(shove)),
+		WithSeat("Bob", 100, DeciderFunc(passive)),
+		WithSeat("Carol", 100, DeciderFunc(shove)),
+		WithSeat("Dave", 100, DeciderFunc(passive)),
+	)
+	err := game.Play()
+	assert.Nil(t, err)
+	winners := game.Winner()
+	assert.Equal(t, 1, len(winners))
+	assert.Equal(t, 400, winners[0].Stack())
+}
+
+func TestNewErrors(t *testing.T) {
+	_, err := New(WithSeat("Alice", 100, DeciderFunc(passive)))
+	assert.Equal(t, "At least two seats are needed", err.Error())
+	_, err = New(WithSeat("Alice", 100, nil), WithSeat("Bob", 100, nil))
+	assert.Equal(t, "Seat Alice has no decider", err.Error())
+	_, err = New(WithSeat("Alice", 100, DeciderFunc(passive)), WithSeat("Bob", 100, DeciderFunc(passive)), Button(2))
+	assert.Equal(t, "Invalid button", err.Error())
+	var seats []func(*Options)
+	for seat := 0; seat <= MaxSeats; seat++ {
+		seats = append(seats, WithSeat(string(rune('A'+seat)), 100, DeciderFunc(passive)))
+	}
+	_, err = New(seats...)
+	assert.Equal(t, "At most 22 seats can be dealt from one deck", err.Error())
+}
+
+func TestFullTable(t *testing.T) {
+	var seats []func(*Options)
+	for seat := 0; seat < MaxSeats; seat++ {
+		seats = append(seats, WithSeat(string(rune('A'+seat)), 100, DeciderFunc(passive)))
+	}
+	game, err := New(seats...)
+	if !assert.Nil(t, err) {
+		return
+	}
+	// every seat sees the river, which takes the whole deck
+	result, err := game.PlayHand()
+	if assert.Nil(t, err) {
+		assert.Len(t, result.Hole, MaxSeats)
+		assert.Len(t, result.Board, 5)
+	}
+}
+
+func TestRandomPlayKeepsEveryChip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	random := DeciderFunc(func(s State) Action {
+		switch r.Intn(5) {
+		case 0:
+			return Action{Type: Fold}
+		case 1:
+			if s.CanRaise {
+				return Action{Type: Raise, Amount: s.MinRaiseTo}
+			}
+		case 2:
+			if s.CanRaise {
+				return Action{Type: AllIn}
+			}
+		}
+		return passive(s)
+	})
+	for game := 0; game < 50; game++ {
+		var options []func(*Options)
+		for seat := 0; seat < 2+game%8; seat++ {
+			options = append(options, WithSeat(string(rune('A'+seat)), 20+r.Intn(200), random))
+		}
+		g, _ := New(append(options, Blinds(1, 2), Ante(game%2))...)
+		total := 0
+		for _, p := range g.Players() {
+			total += p.Stack()
+		}
+		for g.activePlayers() > 1 {
+			result, err := g.PlayHand()
+			if !assert.Nil(t, err) {
+				return
+			}
+			sum := 0
+			for _, p := range g.Players() {
+				sum += p.Stack()
+			}
+			assert.Equal(t, total, sum, "hand %d", result.Number)
+		}
+	}
+}
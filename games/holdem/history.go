@@ -0,0 +1,700 @@
+package holdem
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"adamclerk/deck"
+	"adamclerk/deck/poker"
+	"adamclerk/deck/pot"
+)
+
+// Stakes are the forced bets of a hand
+type Stakes struct {
+	SmallBlind int
+	BigBlind   int
+	Ante       int
+}
+
+// History is a hand history: a hand's record with the table it was played at.
+// It is written and parsed in the PokerStars text format, which most tracking
+// and replaying tools read. As at PokerStars only the hero's hole cards and the
+// hands shown at showdown are written, so a parsed history knows no others.
+// The deck order is kept in a "Deck:" line after the summary, as a GetSignature
+// string, so the hand can be replayed exactly. Histories from PokerStars have no
+// such line and can be read but not replayed.
+type History struct {
+	*HandResult
+	Names      []string // player names by seat
+	SittingOut []bool   // players at the table who weren't dealt in, by seat
+	Stakes     Stakes
+	Hero       int       // seat whose hole cards are written, -1 for none
+	Time       time.Time // when the hand was played, written in its own zone, which parsing keeps by name only
+	Currency   string    // the ISO code of a cash game, such as USD, whose chips are cents; empty for play chips
+}
+
+// History returns the history of a hand played at the table, as seen by the player
+// at seat hero, or by an observer when hero is -1. It is dated now and in play chips.
+func (h *Holdem) History(result *HandResult, hero int) *History {
+	hist := &History{
+		HandResult: result,
+		Stakes:     Stakes{SmallBlind: h.smallBlind, BigBlind: h.bigBlind, Ante: h.ante},
+		Hero:       hero,
+		Time:       time.Now(),
+	}
+	for seat, p := range h.players {
+		hist.Names = append(hist.Names, p.name)
+		hist.SittingOut = append(hist.SittingOut, result.Hole[seat] == nil)
+	}
+	return hist
+}
+
+// currencies are the symbols written before cash amounts
+var currencies = map[string]string{"USD": "$", "EUR": "€", "GBP": "£"}
+
+// chips writes an amount the way the history's game does: whole chips,
+// or the currency's units and cents in a cash game, e.g. $0.25 or $2
+func (hist *History) chips(amount int) string {
+	if hist.Currency == "" {
+		return strconv.Itoa(amount)
+	}
+	text := currencies[hist.Currency] + strconv.Itoa(amount/100)
+	if amount%100 != 0 {
+		text += fmt.Sprintf(".%02d", amount%100)
+	}
+	return text
+}
+
+// streetNames are the names used in the summary of the street a player folded on
+var streetNames = []string{"before Flop", "on the Flop", "on the Turn", "on the River"}
+
+// String returns the history as text
+func (hist *History) String() string {
+	var b bytes.Buffer
+	hist.WriteTo(&b)
+	return b.String()
+}
+
+// WriteTo writes the history as text
+func (hist *History) WriteTo(w io.Writer) (int64, error) {
+	var b bytes.Buffer
+	r := hist.HandResult
+	name := func(seat int) string {
+		return hist.Names[seat]
+	}
+	stakes, money := hist.chips(hist.Stakes.SmallBlind)+"/"+hist.chips(hist.Stakes.BigBlind), ""
+	if hist.Currency != "" {
+		stakes += " " + hist.Currency
+	} else {
+		money = "(Play Money) "
+	}
+	fmt.Fprintf(&b, "PokerStars Hand #%d:  Hold'em No Limit (%s)", r.Number, stakes)
+	if t := hist.Time; !t.IsZero() {
+		fmt.Fprintf(&b, " - %d/%02d/%02d %d:%02d:%02d %s", t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Format("MST"))
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "Table 'deck' %d-max %sSeat #%d is the button\n", len(hist.Names), money, r.Button+1)
+	for seat := range hist.Names {
+		fmt.Fprintf(&b, "Seat %d: %s (%s in chips)", seat+1, name(seat), hist.chips(r.Stacks[seat]))
+		if hist.SittingOut[seat] {
+			b.WriteString(" is sitting out")
+		}
+		b.WriteString("\n")
+	}
+
+	contributed := make([]int, len(hist.Names))
+	street, currentBet := Preflop, 0
+	for i, a := range r.Actions {
+		if a.Type < PostAnte && (i == 0 || r.Actions[i-1].Type >= PostAnte) {
+			writeHoleCards(&b, hist)
+		}
+		for ; street < a.Street; street++ {
+			writeStreet(&b, street+1, r.Board)
+			currentBet = 0
+		}
+		contributed[a.Seat] += a.Amount
+		fmt.Fprintf(&b, "%s: ", name(a.Seat))
+		switch a.Type {
+		case PostAnte:
+			b.WriteString("posts the ante " + hist.chips(a.Amount))
+		case PostSmallBlind:
+			b.WriteString("posts small blind " + hist.chips(a.Amount))
+		case PostBigBlind:
+			b.WriteString("posts big blind " + hist.chips(a.Amount))
+		case Fold:
+			b.WriteString("folds")
+		case Check:
+			b.WriteString("checks")
+		case Call:
+			b.WriteString("calls " + hist.chips(a.Amount))
+		case Bet:
+			b.WriteString("bets " + hist.chips(a.Amount))
+		case Raise:
+			fmt.Fprintf(&b, "raises %s to %s", hist.chips(a.To-currentBet), hist.chips(a.To))
+		}
+		if a.AllIn {
+			b.WriteString(" and is all-in")
+		}
+		b.WriteString("\n")
+		if a.Type != PostAnte && a.To > currentBet {
+			currentBet = a.To
+		}
+	}
+	if len(r.Actions) == 0 || r.Actions[len(r.Actions)-1].Type >= PostAnte {
+		// the forced bets put everyone all in, so nobody acted
+		writeHoleCards(&b, hist)
+	}
+	// the rest of the board is dealt out when players are all in
+	for ; street < River && len(r.Board) >= boardSize(street+1); street++ {
+		writeStreet(&b, street+1, r.Board)
+	}
+
+	// the chips nobody called go back before the pots are paid
+	uncalled, seat := hist.uncalled(contributed)
+	if uncalled > 0 {
+		fmt.Fprintf(&b, "Uncalled bet (%s) returned to %s\n", hist.chips(uncalled), name(seat))
+	}
+	pots := append([]pot.Pot{}, r.Pots...)
+	if uncalled > 0 && len(pots) > 0 {
+		pots[len(pots)-1].Amount -= uncalled
+		if pots[len(pots)-1].Amount == 0 {
+			pots = pots[:len(pots)-1]
+		}
+	}
+	showdown := false
+	for _, shown := range r.Shown {
+		showdown = showdown || shown
+	}
+	if showdown {
+		b.WriteString("*** SHOW DOWN ***\n")
+		for seat, shown := range r.Shown {
+			if shown {
+				fmt.Fprintf(&b, "%s: shows %s (%s)\n", name(seat), cardsText(r.Hole[seat]), r.Hands[seat])
+			}
+		}
+	}
+	collected := make([]int, len(hist.Names))
+	opt := pot.Options{}
+	pot.LeftOfButton(r.Button, len(hist.Names))(&opt)
+	total := 0
+	for i, p := range pots {
+		total += p.Amount
+		each, odd := p.Amount/len(p.Winners), p.Amount%len(p.Winners)
+		for k, w := range opt.OddChips(p.Winners) {
+			won := each
+			if k < odd {
+				won++
+			}
+			collected[w] += won
+			fmt.Fprintf(&b, "%s collected %s from %s\n", name(w), hist.chips(won), potName(i, len(pots)))
+		}
+	}
+
+	b.WriteString("*** SUMMARY ***\n")
+	fmt.Fprintf(&b, "Total pot %s", hist.chips(total))
+	if len(pots) > 1 {
+		for i, p := range pots {
+			name := potName(i, len(pots))
+			fmt.Fprintf(&b, " %s%s %s.", strings.ToUpper(name[:1]), name[1:], hist.chips(p.Amount))
+		}
+	}
+	fmt.Fprintf(&b, " | Rake %s\n", hist.chips(0))
+	if len(r.Board) > 0 {
+		fmt.Fprintf(&b, "Board %s\n", cardsText(r.Board))
+	}
+	folded := make([]Street, len(hist.Names))
+	for i := range folded {
+		folded[i] = Showdown
+	}
+	for _, a := range r.Actions {
+		if a.Type == Fold {
+			folded[a.Seat] = a.Street
+		}
+	}
+	for seat := range hist.Names {
+		if r.Hole[seat] == nil {
+			continue
+		}
+		fmt.Fprintf(&b, "Seat %d: %s", seat+1, name(seat))
+		if seat == r.Button {
+			b.WriteString(" (button)")
+		}
+		if seat == r.SmallBlind {
+			b.WriteString(" (small blind)")
+		}
+		if seat == r.BigBlind {
+			b.WriteString(" (big blind)")
+		}
+		switch {
+		case folded[seat] != Showdown:
+			fmt.Fprintf(&b, " folded %s", streetNames[folded[seat]])
+		case r.Shown[seat] && collected[seat] > 0:
+			fmt.Fprintf(&b, " showed %s and won (%s) with %s", cardsText(r.Hole[seat]), hist.chips(collected[seat]), r.Hands[seat])
+		case r.Shown[seat]:
+			fmt.Fprintf(&b, " showed %s and lost with %s", cardsText(r.Hole[seat]), r.Hands[seat])
+		case collected[seat] > 0:
+			fmt.Fprintf(&b, " collected (%s)", hist.chips(collected[seat]))
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "Deck: %s\n\n", r.Signature)
+	n, err := w.Write(b.Bytes())
+	return int64(n), err
+}
+
+// writeHoleCards starts the preflop betting, dealing the hero's cards face up
+func writeHoleCards(b *bytes.Buffer, hist *History) {
+	b.WriteString("*** HOLE CARDS ***\n")
+	if hist.Hero >= 0 && hist.Hole[hist.Hero] != nil {
+		fmt.Fprintf(b, "Dealt to %s %s\n", hist.Names[hist.Hero], cardsText(hist.Hole[hist.Hero]))
+	}
+}
+
+// writeStreet writes the line dealing the cards of a street
+func writeStreet(b *bytes.Buffer, street Street, board []deck.Card) {
+	n := boardSize(street)
+	if street == Flop {
+		fmt.Fprintf(b, "*** FLOP *** %s\n", cardsText(board[:n]))
+		return
+	}
+	fmt.Fprintf(b, "*** %s *** %s %s\n", strings.ToUpper(street.String()), cardsText(board[:n-1]), cardsText(board[n-1:n]))
+}
+
+// boardSize is the number of board cards once a street is dealt
+func boardSize(street Street) int {
+	return []int{0, 3, 4, 5, 5}[street]
+}
+
+// uncalled returns the chips a player bet that nobody called, and the player
+func (hist *History) uncalled(contributed []int) (int, int) {
+	top, second, seat := 0, 0, -1
+	for s, c := range contributed {
+		switch {
+		case c > top:
+			top, second, seat = c, top, s
+		case c > second:
+			second = c
+		}
+	}
+	for _, a := range hist.Actions {
+		if seat >= 0 && a.Seat == seat && a.Type == Fold {
+			return 0, -1
+		}
+	}
+	return top - second, seat
+}
+
+func potName(i, pots int) string {
+	switch {
+	case pots == 1:
+		return "pot"
+	case i == 0:
+		return "main pot"
+	case pots == 2:
+		return "side pot"
+	}
+	return fmt.Sprintf("side pot-%d", i)
+}
+
+var (
+	headerLine    = regexp.MustCompile(`^PokerStars (?:Zoom )?Hand #(\d+): +Hold'em No Limit \(([^/ ]+)/([^/ )]+)(?: ([A-Z]{3}))?\)(?: - (\d+/\d+/\d+ \d+:\d+:\d+)(?: ([A-Z]+))?)?`)
+	tableLine     = regexp.MustCompile(`^Table '.*' \d+-max (?:\(Play Money\) )?Seat #(\d+) is the button`)
+	seatLine      = regexp.MustCompile(`^Seat (\d+): (.+) \((\S+) in chips(?:, [^)]*)?\)( is sitting out)?`)
+	streetLine    = regexp.MustCompile(`^\*\*\* (HOLE CARDS|FLOP|TURN|RIVER|SHOW DOWN|SUMMARY) \*\*\*(.*)$`)
+	dealtLine     = regexp.MustCompile(`^Dealt to (.+) \[(.+)\]$`)
+	actionLine    = regexp.MustCompile(`^(.+): (posts the ante|posts small blind|posts big blind|folds|checks|calls|bets|raises)(?: ([^ \[]+))?(?: to (\S+))?( and is all-in)?(?: \[.+\])?$`)
+	uncalledLine  = regexp.MustCompile(`^Uncalled bet \((\S+)\) returned to (.+)$`)
+	showsLine     = regexp.MustCompile(`^(.+): shows \[(.+?)\]`)
+	collectedLine = regexp.MustCompile(`^(.+) collected (\S+) from `)
+	deckLine      = regexp.MustCompile(`^Deck: ([0-9a-f]*)$`)
+)
+
+// ParseHistories reads every hand in a text written by WriteTo or by PokerStars.
+// Lines it doesn't know about are skipped.
+func ParseHistories(r io.Reader) ([]*History, error) {
+	var histories []*History
+	var hist *History
+	var p *parser
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if m := headerLine.FindStringSubmatch(text); m != nil {
+			if hist != nil {
+				if err := p.finish(); err != nil {
+					return nil, err
+				}
+			}
+			hist = &History{HandResult: &HandResult{}, Hero: -1, Currency: m[4]}
+			p = &parser{hist: hist, seats: map[string]int{}}
+			if err := p.header(m); err != nil {
+				return nil, fmt.Errorf("Line %d: %s", line, err)
+			}
+			histories = append(histories, hist)
+			continue
+		}
+		if hist == nil || text == "" {
+			continue
+		}
+		if err := p.parse(text); err != nil {
+			return nil, fmt.Errorf("Line %d: %s", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if hist != nil {
+		if err := p.finish(); err != nil {
+			return nil, err
+		}
+	}
+	return histories, nil
+}
+
+// parser is the state of a hand being parsed
+type parser struct {
+	hist    *History
+	seats   map[string]int
+	numbers []int // the table's seat numbers, by seat
+	button  int   // the seat number of the button
+	street  Street
+	summary bool
+	bets    []int
+}
+
+// header reads the hand number, the stakes and the date
+func (p *parser) header(m []string) error {
+	var err error
+	hist := p.hist
+	hist.Number, _ = strconv.Atoi(m[1])
+	if hist.Stakes.SmallBlind, err = p.chips(m[2]); err != nil {
+		return err
+	}
+	if hist.Stakes.BigBlind, err = p.chips(m[3]); err != nil {
+		return err
+	}
+	if m[5] == "" {
+		return nil
+	}
+	zone := time.UTC
+	if m[6] != "" && m[6] != "UTC" {
+		zone = time.FixedZone(m[6], 0)
+	}
+	hist.Time, err = time.ParseInLocation("2006/01/02 15:04:05", m[5], zone)
+	return err
+}
+
+// chips reads an amount written by History.chips
+func (p *parser) chips(text string) (int, error) {
+	invalid := errors.New("Invalid amount " + text)
+	amount := strings.TrimLeft(text, "$€£")
+	if p.hist.Currency == "" {
+		chips, err := strconv.Atoi(amount)
+		if err != nil {
+			return 0, invalid
+		}
+		return chips, nil
+	}
+	units, cents := amount, "00"
+	if i := strings.IndexByte(amount, '.'); i >= 0 {
+		units, cents = amount[:i], (amount[i+1:] + "0")[:2]
+	}
+	u, err := strconv.Atoi(units)
+	if err != nil {
+		return 0, invalid
+	}
+	c, err := strconv.Atoi(cents)
+	if err != nil {
+		return 0, invalid
+	}
+	return u*100 + c, nil
+}
+
+func (p *parser) seat(name string) (int, error) {
+	seat, ok := p.seats[name]
+	if !ok {
+		return 0, errors.New("Unknown player " + name)
+	}
+	return seat, nil
+}
+
+func (p *parser) parse(text string) error {
+	r := p.hist.HandResult
+	if m := streetLine.FindStringSubmatch(text); m != nil {
+		switch m[1] {
+		case "HOLE CARDS":
+			p.street = Preflop
+		case "FLOP", "TURN", "RIVER":
+			cards, err := deck.ParseCards(strings.Replace(strings.Replace(m[2], "[", "", -1), "]", "", -1))
+			if err != nil {
+				return err
+			}
+			r.Board = cards
+			p.street = map[string]Street{"FLOP": Flop, "TURN": Turn, "RIVER": River}[m[1]]
+			p.bets = make([]int, len(p.hist.Names))
+		case "SHOW DOWN":
+			p.street = Showdown
+		case "SUMMARY":
+			p.summary = true
+		}
+		return nil
+	}
+	if m := deckLine.FindStringSubmatch(text); m != nil {
+		r.Signature = m[1]
+		return nil
+	}
+	if p.summary {
+		return nil
+	}
+	if m := tableLine.FindStringSubmatch(text); m != nil {
+		p.button, _ = strconv.Atoi(m[1])
+		return nil
+	}
+	if m := seatLine.FindStringSubmatch(text); m != nil {
+		// the table's seat numbers can skip empty seats
+		number, _ := strconv.Atoi(m[1])
+		if len(p.numbers) > 0 && number <= p.numbers[len(p.numbers)-1] {
+			return errors.New("Seats must be numbered in order")
+		}
+		stack, err := p.chips(m[3])
+		if err != nil {
+			return err
+		}
+		if number == p.button {
+			r.Button = len(p.hist.Names)
+		}
+		p.numbers = append(p.numbers, number)
+		p.hist.SittingOut = append(p.hist.SittingOut, m[4] != "")
+		p.seats[m[2]] = len(p.hist.Names)
+		p.hist.Names = append(p.hist.Names, m[2])
+		r.Stacks = append(r.Stacks, stack)
+		r.Hole = append(r.Hole, nil)
+		r.Shown = append(r.Shown, false)
+		r.Hands = append(r.Hands, poker.HandValue{})
+		r.Winnings = append(r.Winnings, 0)
+		p.bets = append(p.bets, 0)
+		return nil
+	}
+	if m := dealtLine.FindStringSubmatch(text); m != nil {
+		seat, err := p.seat(m[1])
+		if err != nil {
+			return err
+		}
+		p.hist.Hero = seat
+		r.Hole[seat], err = deck.ParseCards(m[2])
+		return err
+	}
+	if m := actionLine.FindStringSubmatch(text); m != nil {
+		seat, err := p.seat(m[1])
+		if err != nil {
+			return err
+		}
+		var amount, to int
+		if m[3] != "" {
+			if amount, err = p.chips(m[3]); err != nil {
+				return err
+			}
+		}
+		if m[4] != "" {
+			if to, err = p.chips(m[4]); err != nil {
+				return err
+			}
+		}
+		a := ActionRecord{Street: p.street, Seat: seat, AllIn: m[5] != ""}
+		switch m[2] {
+		case "posts the ante":
+			a.Type = PostAnte
+			if amount > p.hist.Stakes.Ante {
+				p.hist.Stakes.Ante = amount
+			}
+		case "posts small blind":
+			a.Type, r.SmallBlind = PostSmallBlind, seat
+		case "posts big blind":
+			a.Type, r.BigBlind = PostBigBlind, seat
+		case "folds":
+			a.Type = Fold
+		case "checks":
+			a.Type = Check
+		case "calls":
+			a.Type = Call
+		case "bets":
+			a.Type = Bet
+		case "raises":
+			a.Type, amount = Raise, to-p.bets[seat]
+		}
+		if a.Type != PostAnte {
+			p.bets[seat] += amount
+		}
+		a.Amount, a.To = amount, p.bets[seat]
+		r.Actions = append(r.Actions, a)
+		return nil
+	}
+	if m := uncalledLine.FindStringSubmatch(text); m != nil {
+		return p.win(m[2], m[1])
+	}
+	if m := collectedLine.FindStringSubmatch(text); m != nil {
+		return p.win(m[1], m[2])
+	}
+	if m := showsLine.FindStringSubmatch(text); m != nil {
+		seat, err := p.seat(m[1])
+		if err != nil {
+			return err
+		}
+		r.Shown[seat] = true
+		r.Hole[seat], err = deck.ParseCards(m[2])
+		return err
+	}
+	return nil
+}
+
+func (p *parser) win(name, chips string) error {
+	seat, err := p.seat(name)
+	if err != nil {
+		return err
+	}
+	won, err := p.chips(chips)
+	if err != nil {
+		return err
+	}
+	p.hist.Winnings[seat] += won
+	return nil
+}
+
+// finish fills in what the text doesn't say directly: the values of the shown hands and the pots
+func (p *parser) finish() error {
+	r := p.hist.HandResult
+	if len(p.hist.Names) < 2 {
+		return fmt.Errorf("Hand #%d has fewer than two seats", r.Number)
+	}
+	players := make([]pot.Player, len(p.hist.Names))
+	for seat := range players {
+		players[seat].Folded = p.hist.SittingOut[seat]
+		if r.Shown[seat] {
+			h := poker.NewHand(append(append([]deck.Card{}, r.Hole[seat]...), r.Board...)...)
+			r.Hands[seat] = h.Evaluate()
+			players[seat].High = h.Value()
+		}
+	}
+	for _, a := range r.Actions {
+		players[a.Seat].Contributed += a.Amount
+		players[a.Seat].Folded = players[a.Seat].Folded || a.Type == Fold
+	}
+	split, err := pot.Distribute(players, pot.LeftOfButton(r.Button, len(players)))
+	if err != nil {
+		return fmt.Errorf("Hand #%d: %s", r.Number, err)
+	}
+	r.Pots = split.Pots
+	return nil
+}
+
+// Replay plays the hand again from the deck signature, with each player making
+// the recorded actions, and returns the new result. It fails if the history has no deck signature,
+// if the actions don't play out, or if the board, the cards shown or the winnings aren't the ones
+// the deck and the actions give.
+func (hist *History) Replay() (*HandResult, error) {
+	r := hist.HandResult
+	if r.Signature == "" {
+		return nil, fmt.Errorf("Hand #%d has no deck signature", r.Number)
+	}
+	missing, left := "", 0
+	options := []func(*Options){
+		WithDeck(deck.Unshuffled, deck.FromSignature(r.Signature)),
+		Blinds(hist.Stakes.SmallBlind, hist.Stakes.BigBlind),
+		Ante(hist.Stakes.Ante),
+		Button(r.Button),
+	}
+	for seat, name := range hist.Names {
+		var actions []Action
+		for _, a := range r.Actions {
+			if a.Seat == seat && a.Type < PostAnte {
+				actions = append(actions, Action{Type: a.Type, Amount: a.To})
+				left++
+			}
+		}
+		name := name
+		options = append(options, WithSeat(name, r.Stacks[seat], DeciderFunc(func(s State) Action {
+			if len(actions) == 0 {
+				missing = name
+				return Action{Type: ActionType(-1)}
+			}
+			a := actions[0]
+			actions = actions[1:]
+			left--
+			return a
+		})))
+	}
+	game, err := New(options...)
+	if err != nil {
+		return nil, err
+	}
+	game.hands = r.Number - 1
+	result, err := game.PlayHand()
+	if missing != "" {
+		return nil, errors.New("The history has no more actions for " + missing)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if left > 0 {
+		return nil, errors.New("The history has actions after the hand ended")
+	}
+	if err := hist.matches(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// matches checks the history says what the replayed hand gives: the board, the cards shown and the winnings
+func (hist *History) matches(result *HandResult) error {
+	r := hist.HandResult
+	if !sameCards(r.Board, result.Board) {
+		return fmt.Errorf("The history's board is %s, the deck's %s", cardsText(r.Board), cardsText(result.Board))
+	}
+	for seat, name := range hist.Names {
+		if r.Shown[seat] && !result.Shown[seat] {
+			return fmt.Errorf("The history shows cards %s doesn't show", name)
+		}
+		if !r.Shown[seat] && result.Shown[seat] {
+			return fmt.Errorf("The history doesn't show the cards %s shows", name)
+		}
+		if r.Hole[seat] != nil && !sameCards(r.Hole[seat], result.Hole[seat]) {
+			return fmt.Errorf("The history deals %s %s, the deck %s", name, cardsText(r.Hole[seat]), cardsText(result.Hole[seat]))
+		}
+		if r.Winnings[seat] != result.Winnings[seat] {
+			return fmt.Errorf("The history has %s win %s, not %s", name, hist.chips(r.Winnings[seat]), hist.chips(result.Winnings[seat]))
+		}
+	}
+	return nil
+}
+
+func sameCards(a, b []deck.Card) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+const (
+	faceNames = "A23456789TJQK"
+	suitNames = "cdhs"
+)
+
+// cardsText writes cards the way hand histories do, e.g. [Ah Kd]
+func cardsText(cards []deck.Card) string {
+	text := make([]string, len(cards))
+	for i, c := range cards {
+		text[i] = string([]byte{faceNames[c.Face()], suitNames[c.Suit()]})
+	}
+	return "[" + strings.Join(text, " ") + "]"
+}
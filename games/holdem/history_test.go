@@ -0,0 +1,302 @@
+package holdem
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"adamclerk/deck"
+	"adamclerk/deck/internal/decktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteHistory(t *testing.T) {
+	game, _ := New(
+		stacked(
+			"Kd", "Ah", "Kc", "As", // Bob, left of the button, is dealt first
+			"2s", "7h", "8d", "9c", // burn and flop
+			"2h", "Qs", // burn and turn
+			"3h", "4c", // burn and river
+		),
+		WithSeat("Alice", 100, script(Action{Type: Raise, Amount: 6}, Action{Type: Bet, Amount: 10})),
+		WithSeat("Bob", 100, script(Action{Type: Call}, Action{Type: Check}, Action{Type: Fold})),
+	)
+	result, err := game.PlayHand()
+	assert.Nil(t, err)
+	hist := game.History(result, 0)
+	hist.Time = time.Date(2020, 1, 12, 6, 15, 36, 0, time.UTC)
+	assert.Equal(t, `PokerStars Hand #1:  Hold'em No Limit (1/2) - 2020/01/12 6:15:36 UTC
+Table 'deck' 2-max (Play Money) Seat #1 is the button
+Seat 1: Alice (100 in chips)
+Seat 2: Bob (100 in chips)
+Alice: posts small blind 1
+Bob: posts big blind 2
+*** HOLE CARDS ***
+Dealt to Alice [Ah As]
+Alice: raises 4 to 6
+Bob: calls 4
+*** FLOP *** [7h 8d 9c]
+Bob: checks
+Alice: bets 10
+Bob: folds
+Uncalled bet (10) returned to Alice
+Alice collected 12 from pot
+*** SUMMARY ***
+Total pot 12 | Rake 0
+Board [7h 8d 9c]
+Seat 1: Alice (button) (small blind) collected (12)
+Seat 2: Bob (big blind) folded on the Flop
+Deck: `+result.Signature+`
+
+`, hist.String())
+
+	hist.Hero, hist.Currency = -1, "USD"
+	text := hist.String()
+	assert.Contains(t, text, "PokerStars Hand #1:  Hold'em No Limit ($0.01/$0.02 USD) - 2020/01/12 6:15:36 UTC\n")
+	assert.Contains(t, text, "Table 'deck' 2-max Seat #1 is the button\n")
+	assert.Contains(t, text, "Seat 1: Alice ($1 in chips)\n")
+	assert.Contains(t, text, "*** HOLE CARDS ***\nAlice: raises $0.04 to $0.06\n")
+	assert.Contains(t, text, "Total pot $0.12 | Rake $0\n")
+}
+
+// two hands from PokerStars: empty and sitting out seats, the hero's cards only, rake and no deck line
+const pokerStarsHands = "\ufeff" + `PokerStars Hand #208925468133:  Hold'em No Limit ($0.01/$0.02 USD) - 2020/01/12 12:15:36 CET [2020/01/12 6:15:36 ET]
+Table 'Acamar III' 6-max Seat #3 is the button
+Seat 1: pokerfan77 ($2.14 in chips)
+Seat 2: Hero ($2 in chips)
+Seat 3: ZX_Spectrum ($1.61 in chips)
+Seat 5: donkey_kong ($2.37 in chips)
+Seat 6: nitwit42 ($0.84 in chips) is sitting out
+donkey_kong: posts small blind $0.01
+pokerfan77: posts big blind $0.02
+*** HOLE CARDS ***
+Dealt to Hero [Qs Qd]
+Hero: raises $0.04 to $0.06
+ZX_Spectrum: folds
+donkey_kong: calls $0.05
+pokerfan77: folds
+*** FLOP *** [7h 8d 9c]
+donkey_kong: checks
+Hero: bets $0.09
+donkey_kong: calls $0.09
+*** TURN *** [7h 8d 9c] [2s]
+donkey_kong: checks
+Hero: bets $0.24
+donkey_kong: folds
+Uncalled bet ($0.24) returned to Hero
+Hero collected $0.31 from pot
+Hero: doesn't show hand
+*** SUMMARY ***
+Total pot $0.32 | Rake $0.01
+Board [7h 8d 9c 2s]
+Seat 1: pokerfan77 (big blind) folded before Flop
+Seat 2: Hero collected ($0.31)
+Seat 3: ZX_Spectrum (button) folded before Flop (didn't bet)
+Seat 5: donkey_kong (small blind) folded on the Turn
+
+PokerStars Hand #208925492071:  Hold'em No Limit ($0.01/$0.02 USD) - 2020/01/12 12:16:41 CET [2020/01/12 6:16:41 ET]
+Table 'Acamar III' 6-max Seat #5 is the button
+Seat 1: pokerfan77 ($2.12 in chips)
+Seat 2: Hero ($2.16 in chips)
+Seat 3: ZX_Spectrum ($1.61 in chips)
+Seat 5: donkey_kong ($2.22 in chips)
+pokerfan77: posts small blind $0.01
+Hero: posts big blind $0.02
+*** HOLE CARDS ***
+Dealt to Hero [Ah Kh]
+ZX_Spectrum: raises $0.04 to $0.06
+donkey_kong: folds
+pokerfan77: folds
+Hero: calls $0.04
+*** FLOP *** [Kd 5c 2h]
+Hero: checks
+ZX_Spectrum: bets $0.08
+Hero: raises $0.16 to $0.24
+ZX_Spectrum: calls $0.16
+*** TURN *** [Kd 5c 2h] [9s]
+Hero: bets $1.86 and is all-in
+ZX_Spectrum: calls $1.31 and is all-in
+Uncalled bet ($0.55) returned to Hero
+*** RIVER *** [Kd 5c 2h 9s] [3d]
+*** SHOW DOWN ***
+Hero: shows [Ah Kh] (a pair of Kings)
+ZX_Spectrum: shows [Qc Qs] (a pair of Queens)
+Hero collected $3.18 from pot
+*** SUMMARY ***
+Total pot $3.23 | Rake $0.05
+Board [Kd 5c 2h 9s 3d]
+Seat 1: pokerfan77 (small blind) folded before Flop
+Seat 2: Hero (big blind) showed [Ah Kh] and won ($3.18) with a pair of Kings
+Seat 3: ZX_Spectrum showed [Qc Qs] and lost with a pair of Queens
+Seat 5: donkey_kong (button) folded before Flop (didn't bet)
+`
+
+func TestParsePokerStarsHistory(t *testing.T) {
+	histories, err := ParseHistories(strings.NewReader(pokerStarsHands))
+	if !assert.Nil(t, err) || !assert.Equal(t, 2, len(histories)) {
+		return
+	}
+
+	first := histories[0]
+	assert.Equal(t, 208925468133, first.Number)
+	assert.Equal(t, []string{"pokerfan77", "Hero", "ZX_Spectrum", "donkey_kong", "nitwit42"}, first.Names)
+	assert.Equal(t, Stakes{SmallBlind: 1, BigBlind: 2}, first.Stakes)
+	assert.Equal(t, "USD", first.Currency)
+	assert.Equal(t, time.Date(2020, 1, 12, 12, 15, 36, 0, time.FixedZone("CET", 0)), first.Time)
+	assert.Equal(t, []int{214, 200, 161, 237, 84}, first.Stacks)
+	assert.Equal(t, 2, first.Button)
+	assert.Equal(t, 3, first.SmallBlind)
+	assert.Equal(t, 0, first.BigBlind)
+	assert.Equal(t, 1, first.Hero)
+	assert.Equal(t, [][]deck.Card{nil, decktest.Cards("Qs Qd"), nil, nil, nil}, first.Hole)
+	assert.Equal(t, ActionRecord{Street: Turn, Seat: 1, Type: Bet, Amount: 24, To: 24}, first.Actions[len(first.Actions)-2])
+	assert.Equal(t, []int{0, 55, 0, 0, 0}, first.Winnings)
+	assert.Equal(t, 56, first.Pots[0].Amount)
+
+	second := histories[1]
+	assert.Equal(t, 3, second.Button)
+	assert.Equal(t, []bool{false, true, true, false}, second.Shown)
+	assert.Equal(t, decktest.Cards("Qc Qs"), second.Hole[2])
+	assert.Equal(t, decktest.Cards("Kd 5c 2h 9s 3d"), second.Board)
+	assert.Equal(t, ActionRecord{Street: Turn, Seat: 1, Type: Bet, Amount: 186, To: 186, AllIn: true}, second.Actions[len(second.Actions)-2])
+	assert.Equal(t, "One Pair (K A 9 5)", second.Hands[1].String())
+	assert.Equal(t, []int{0, 373, 0, 0}, second.Winnings)
+
+	_, err = second.Replay()
+	assert.Equal(t, "Hand #208925492071 has no deck signature", err.Error())
+}
+
+func TestParseHistoryRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	random := DeciderFunc(func(s State) Action {
+		switch r.Intn(6) {
+		case 0:
+			return Action{Type: Fold}
+		case 1, 2:
+			if s.CanRaise {
+				return Action{Type: Raise, Amount: s.MinRaiseTo + r.Intn(s.MaxRaiseTo-s.MinRaiseTo+1)}
+			}
+		case 3:
+			return Action{Type: AllIn}
+		}
+		return passive(s)
+	})
+	var text strings.Builder
+	var results []*HandResult
+	var histories []*History
+	for game := 0; game < 20; game++ {
+		var options []func(*Options)
+		for seat := 0; seat < 2+game%8; seat++ {
+			options = append(options, WithSeat(string(rune('A'+seat))+" Player", 10+r.Intn(200), random))
+		}
+		g, _ := New(append(options, Blinds(1, 2), Ante(game%3))...)
+		for g.activePlayers() > 1 {
+			result, err := g.PlayHand()
+			if !assert.Nil(t, err) {
+				return
+			}
+			results = append(results, result)
+			hist := g.History(result, result.Button)
+			hist.Time = time.Date(2020, 1, 12, 6, 15, 36, 0, time.UTC)
+			if game%2 == 1 {
+				hist.Currency = "EUR"
+			}
+			histories = append(histories, hist)
+			hist.WriteTo(&text)
+		}
+	}
+
+	parsed, err := ParseHistories(strings.NewReader(text.String()))
+	assert.Nil(t, err)
+	assert.Equal(t, len(results), len(parsed))
+	for i, hist := range parsed {
+		want := histories[i]
+		assert.Equal(t, want.Names, hist.Names)
+		assert.Equal(t, want.SittingOut, hist.SittingOut)
+		if want.Stakes.Ante != hist.Stakes.Ante {
+			// a history only knows the ante players posted
+			hist.Stakes.Ante = want.Stakes.Ante
+		}
+		assert.Equal(t, want.Stakes, hist.Stakes)
+		assert.Equal(t, want.Hero, hist.Hero)
+		assert.Equal(t, want.Time, hist.Time)
+		assert.Equal(t, want.Currency, hist.Currency)
+		// only the hero's hole cards and the hands shown are written
+		known := *want.HandResult
+		known.Hole = make([][]deck.Card, len(want.Hole))
+		for seat, hole := range want.Hole {
+			if seat == want.Hero || want.Shown[seat] {
+				known.Hole[seat] = hole
+			}
+		}
+		assert.Equal(t, &known, hist.HandResult, "hand %d", i)
+
+		replayed, err := hist.Replay()
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.Equal(t, results[i], replayed, "hand %d", i)
+	}
+}
+
+func TestReplayDetectsBadHistories(t *testing.T) {
+	game, _ := New(
+		WithSeat("Alice", 100, DeciderFunc(passive)),
+		WithSeat("Bob", 100, DeciderFunc(passive)),
+		WithDeck(deck.FromSeed("history")),
+	)
+	result, _ := game.PlayHand()
+	text := game.History(result, -1).String()
+
+	histories, _ := ParseHistories(strings.NewReader(strings.Replace(text, "Bob: checks\n", "", 1)))
+	_, err := histories[0].Replay()
+	assert.Equal(t, "The history has no more actions for Bob", err.Error())
+
+	histories, _ = ParseHistories(strings.NewReader(strings.Replace(text, "Alice: calls 1\n", "Alice: raises 1 to 3\n", 1)))
+	_, err = histories[0].Replay()
+	assert.Equal(t, "Invalid action Raise 3 by Alice: the minimum is 4", err.Error())
+
+	// the actions play out, but not to what the history says
+	tests := []struct {
+		old, new, err string
+	}{
+		{"Jc", "Jd", "The history's board is [Js Jh 5c 2d Jd], the deck's [Js Jh 5c 2d Jc]"},
+		{"[Tc 8c]", "[Tc 9c]", "The history deals Bob [Tc 9c], the deck [Tc 8c]"},
+		{"collected 4", "collected 3", "The history has Alice win 3, not 4"},
+	}
+	for _, test := range tests {
+		histories, err = ParseHistories(strings.NewReader(strings.Replace(text, test.old, test.new, -1)))
+		if !assert.Nil(t, err, test.err) {
+			continue
+		}
+		_, err = histories[0].Replay()
+		if assert.NotNil(t, err, test.err) {
+			assert.Equal(t, test.err, err.Error())
+		}
+	}
+}
+
+func TestHistoryWithoutActions(t *testing.T) {
+	text := "PokerStars Hand #3: Hold'em No Limit (1/2)\nSeat 1: Alice (100 in chips)\nSeat 2: Bob (100 in chips)\n"
+	histories, err := ParseHistories(strings.NewReader(text))
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Len(t, histories[0].Actions, 0)
+	again, err := ParseHistories(strings.NewReader(histories[0].String()))
+	if assert.Nil(t, err) {
+		assert.Equal(t, histories[0].HandResult, again[0].HandResult)
+	}
+}
+
+func TestParseHistoryErrors(t *testing.T) {
+	_, err := ParseHistories(strings.NewReader("PokerStars Hand #3: Hold'em No Limit (1/2)\nSeat 1: Alice (100 in chips)\nDealt to Bob [Ah Kd]\n"))
+	assert.Equal(t, "Line 3: Unknown player Bob", err.Error())
+	_, err = ParseHistories(strings.NewReader("PokerStars Hand #3: Hold'em No Limit (1/2)\nSeat 1: Alice (100 in chips)\nDealt to Alice [Ah Kx]\n"))
+	assert.Equal(t, "Line 3: Invalid card Kx", err.Error())
+	_, err = ParseHistories(strings.NewReader("PokerStars Hand #3: Hold'em No Limit (1/2)\nSeat 1: Alice (100 in chips)\nSeat 1: Bob (100 in chips)\n"))
+	assert.Equal(t, "Line 3: Seats must be numbered in order", err.Error())
+	_, err = ParseHistories(strings.NewReader("PokerStars Hand #3: Hold'em No Limit ($0.01/$0.02 USD)\nSeat 1: Alice ($1.x in chips)\n"))
+	assert.Equal(t, "Line 2: Invalid amount $1.x", err.Error())
+}
@@ -0,0 +1,240 @@
+// Package betting moves the chips of the poker games: the stacks, the bets of each street,
+// the betting rounds and the pots. The games deal the cards and rule on the actions.
+package betting
+
+import (
+	"errors"
+	"fmt"
+
+	"adamclerk/deck/pot"
+)
+
+// CheckSeats validates the number of seats at a table dealt from one deck
+func CheckSeats(seats, most int) error {
+	if seats < 2 {
+		return errors.New("At least two seats are needed")
+	}
+	if seats > most {
+		return fmt.Errorf("At most %d seats can be dealt from one deck", most)
+	}
+	return nil
+}
+
+// Active counts the players with chips
+func Active(stacks []int) int {
+	n := 0
+	for _, stack := range stacks {
+		if stack > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// NextActive returns the next seat after seat with chips
+func NextActive(stacks []int, seat int) int {
+	for i := 1; i <= len(stacks); i++ {
+		s := (seat + i) % len(stacks)
+		if stacks[s] > 0 {
+			return s
+		}
+	}
+	return seat
+}
+
+// Hand is the chips of a hand being played, by seat.
+// Stacks are a copy: the game keeps them only when the hand is played out.
+type Hand struct {
+	Stacks      []int  // chips behind
+	Folded      []bool // folded or not dealt in
+	Bets        []int  // bets on the current street
+	Contributed []int  // every chip put in this hand
+	Acted       []bool
+	MayRaise    []bool
+	CurrentBet  int
+}
+
+// New starts a hand. Players without chips aren't dealt in.
+func New(stacks []int) *Hand {
+	h := &Hand{
+		Stacks:      append([]int{}, stacks...),
+		Folded:      make([]bool, len(stacks)),
+		Bets:        make([]int, len(stacks)),
+		Contributed: make([]int, len(stacks)),
+		Acted:       make([]bool, len(stacks)),
+		MayRaise:    make([]bool, len(stacks)),
+	}
+	for i, stack := range stacks {
+		h.Folded[i] = stack == 0
+	}
+	return h
+}
+
+// Next returns the next seat after seat that's still in the hand
+func (h *Hand) Next(seat int) int {
+	for i := 1; i <= len(h.Folded); i++ {
+		s := (seat + i) % len(h.Folded)
+		if !h.Folded[s] {
+			return s
+		}
+	}
+	return seat
+}
+
+// Seats returns the seats still in the hand, in seat order
+func (h *Hand) Seats() []int {
+	var seats []int
+	for s, f := range h.Folded {
+		if !f {
+			seats = append(seats, s)
+		}
+	}
+	return seats
+}
+
+// InHand counts the players still in the hand
+func (h *Hand) InHand() int {
+	return len(h.Seats())
+}
+
+// CanAct counts the players still in the hand with chips behind
+func (h *Hand) CanAct() int {
+	count := 0
+	for i, f := range h.Folded {
+		if !f && h.Stacks[i] > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// AllIn tells you if a player still in the hand has no chips behind
+func (h *Hand) AllIn(seat int) bool {
+	return !h.Folded[seat] && h.Stacks[seat] == 0
+}
+
+// Pot is every chip put in this hand
+func (h *Hand) Pot() int {
+	total := 0
+	for _, c := range h.Contributed {
+		total += c
+	}
+	return total
+}
+
+// Put moves chips from a player's stack to the pot, and to their bet when toBet,
+// all in if the stack is short. It returns the chips moved.
+func (h *Hand) Put(seat, amount int, toBet bool) int {
+	if amount > h.Stacks[seat] {
+		amount = h.Stacks[seat]
+	}
+	h.Stacks[seat] -= amount
+	h.Contributed[seat] += amount
+	if toBet {
+		h.Bets[seat] += amount
+	}
+	return amount
+}
+
+// ToCall is what a player needs to put in to call, no more than their stack
+func (h *Hand) ToCall(seat int) int {
+	call := h.CurrentBet - h.Bets[seat]
+	if call > h.Stacks[seat] {
+		call = h.Stacks[seat]
+	}
+	return call
+}
+
+// CanRaise tells you if betting is open to a player, with more chips than the call
+// and someone left to call a raise. Limit games add their cap.
+func (h *Hand) CanRaise(seat int) bool {
+	return h.MayRaise[seat] && h.Stacks[seat] > h.CurrentBet-h.Bets[seat] && h.CanAct() > 1
+}
+
+// Reopen lets every player but seat raise again, after a full bet or raise by seat
+func (h *Hand) Reopen(seat int) {
+	for i := range h.MayRaise {
+		if i != seat {
+			h.MayRaise[i] = true
+		}
+	}
+}
+
+// NewStreet clears the bets for the next betting round
+func (h *Hand) NewStreet() {
+	for i := range h.Bets {
+		h.Bets[i] = 0
+		h.Acted[i] = false
+	}
+	h.CurrentBet = 0
+}
+
+// Round asks players to act, starting with first, until every player
+// still in the hand has matched the current bet or is all in.
+// act applies a player's action, after which they have acted and can't raise until reopened.
+func (h *Hand) Round(first int, act func(seat int) error) error {
+	for i := range h.MayRaise {
+		h.MayRaise[i] = true
+	}
+	seat := first
+	if h.Folded[seat] {
+		seat = h.Next(seat)
+	}
+	for {
+		if h.InHand() < 2 {
+			return nil
+		}
+		if !h.needsAction() {
+			return nil
+		}
+		if !h.Folded[seat] && h.Stacks[seat] > 0 && (!h.Acted[seat] || h.Bets[seat] < h.CurrentBet) {
+			if err := act(seat); err != nil {
+				return err
+			}
+			h.Acted[seat] = true
+			h.MayRaise[seat] = false
+		}
+		seat = (seat + 1) % len(h.Folded)
+	}
+}
+
+// needsAction tells you if some player still has to act on this street
+func (h *Hand) needsAction() bool {
+	waiting := 0
+	for i, f := range h.Folded {
+		if f || h.Stacks[i] == 0 {
+			continue
+		}
+		if !h.Acted[i] || h.Bets[i] < h.CurrentBet {
+			waiting++
+		}
+	}
+	if waiting == 0 {
+		return false
+	}
+	// a lone player with chips who has matched the bet has nobody to bet against
+	if h.CanAct() == 1 {
+		for i, f := range h.Folded {
+			if !f && h.Stacks[i] > 0 && h.Bets[i] >= h.CurrentBet {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Award splits the pots between the players still in, ranked by high, and adds the winnings to their stacks
+func (h *Hand) Award(high []int, options ...func(*pot.Options)) (*pot.Result, error) {
+	players := make([]pot.Player, len(h.Folded))
+	for i := range players {
+		players[i] = pot.Player{Contributed: h.Contributed[i], Folded: h.Folded[i], High: high[i]}
+	}
+	split, err := pot.Distribute(players, options...)
+	if err != nil {
+		return nil, err
+	}
+	for i, won := range split.Winnings {
+		h.Stacks[i] += won
+	}
+	return split, nil
+}
@@ -0,0 +1,87 @@
+package betting
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckSeats(t *testing.T) {
+	assert.EqualError(t, CheckSeats(1, 8), "At least two seats are needed")
+	assert.EqualError(t, CheckSeats(9, 8), "At most 8 seats can be dealt from one deck")
+	assert.Nil(t, CheckSeats(8, 8))
+}
+
+func TestNextActive(t *testing.T) {
+	stacks := []int{10, 0, 0, 5}
+	assert.Equal(t, 2, Active(stacks))
+	assert.Equal(t, 3, NextActive(stacks, 0))
+	assert.Equal(t, 0, NextActive(stacks, 3))
+	assert.Equal(t, 0, NextActive([]int{10, 0}, 0))
+}
+
+func TestPutAllIn(t *testing.T) {
+	h := New([]int{100, 30, 0})
+	assert.Equal(t, []bool{false, false, true}, h.Folded)
+	assert.Equal(t, 5, h.Put(0, 5, false))
+	assert.Equal(t, 30, h.Put(1, 50, true))
+	assert.Equal(t, []int{95, 0, 0}, h.Stacks)
+	assert.Equal(t, []int{0, 30, 0}, h.Bets)
+	assert.Equal(t, 35, h.Pot())
+	assert.True(t, h.AllIn(1))
+	assert.False(t, h.AllIn(2))
+}
+
+func TestRound(t *testing.T) {
+	h := New([]int{100, 100, 100})
+	var order []int
+	err := h.Round(1, func(seat int) error {
+		order = append(order, seat)
+		if seat == 2 {
+			// raise once, everyone else calls
+			h.CurrentBet = 10
+			h.Reopen(seat)
+		}
+		h.Put(seat, h.ToCall(seat), true)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 2, 0, 1}, order)
+	assert.Equal(t, []int{10, 10, 10}, h.Bets)
+	assert.Equal(t, []bool{false, false, false}, h.MayRaise)
+
+	h.NewStreet()
+	err = h.Round(0, func(seat int) error {
+		return errors.New("Invalid action")
+	})
+	assert.EqualError(t, err, "Invalid action")
+	assert.Equal(t, 30, h.Pot())
+}
+
+func TestRoundEndsWhenOnlyOnePlayerCanAct(t *testing.T) {
+	h := New([]int{100, 20})
+	h.Put(1, 20, true)
+	h.CurrentBet = 20
+	var order []int
+	err := h.Round(0, func(seat int) error {
+		order = append(order, seat)
+		h.Put(seat, h.ToCall(seat), true)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0}, order)
+	assert.False(t, h.CanRaise(0))
+}
+
+func TestAward(t *testing.T) {
+	h := New([]int{100, 100, 100})
+	for seat := range h.Stacks {
+		h.Put(seat, 10, true)
+	}
+	h.Folded[2] = true
+	split, err := h.Award([]int{7, 9, 0})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 30, 0}, split.Winnings)
+	assert.Equal(t, []int{90, 120, 90}, h.Stacks)
+}
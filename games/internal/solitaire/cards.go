@@ -0,0 +1,154 @@
+// Package solitaire is what the solitaires have in common: how cards build on the tableau and up the foundations,
+// and a depth first search for a win. The games lay the cards out and rule on the moves.
+package solitaire
+
+import (
+	"fmt"
+	"strings"
+
+	"adamclerk/deck"
+)
+
+// FullDeck checks there are the 52 cards of a deck, once each, for the game named
+func FullDeck(game string, cards []deck.Card) error {
+	if len(cards) != 52 {
+		return fmt.Errorf("%s needs the 52 cards of a deck, not %d", game, len(cards))
+	}
+	seen := map[deck.Card]bool{}
+	for _, c := range cards {
+		if c < 0 || c >= 52 {
+			return fmt.Errorf("Unknown card %d", int(c))
+		}
+		if seen[c] {
+			return fmt.Errorf("%s needs the 52 cards of a deck, %s is there twice", game, c)
+		}
+		seen[c] = true
+	}
+	return nil
+}
+
+// SuitName returns the symbol of a suit, as the cards show it
+func SuitName(suit int) string {
+	s := deck.NewCard(deck.ACE, deck.Suit(suit)).String()
+	return s[1:]
+}
+
+// Red tells you if the card is a diamond or a heart
+func Red(c deck.Card) bool {
+	s := deck.Suit(c.Suit())
+	return s == deck.DIAMOND || s == deck.HEART
+}
+
+// BuildsOn tells you if the card can go on the other in the tableau: one rank lower and the other colour
+func BuildsOn(c, on deck.Card) bool {
+	return c.Face()+1 == on.Face() && Red(c) != Red(on)
+}
+
+// Top returns the last card of a pile, the one on top
+func Top(cards []deck.Card) (deck.Card, bool) {
+	if len(cards) == 0 {
+		return 0, false
+	}
+	return cards[len(cards)-1], true
+}
+
+// CardList writes the cards, each after a space
+func CardList(cards []deck.Card) string {
+	s := ""
+	for _, c := range cards {
+		s += " " + c.String()
+	}
+	return s
+}
+
+// Pile is a column of the tableau. The first FaceDown cards are face down, the last card is on top.
+type Pile struct {
+	Cards    []deck.Card
+	FaceDown int
+}
+
+// FaceUp returns the face up cards, from the deepest one to the top
+func (p Pile) FaceUp() []deck.Card {
+	return p.Cards[p.FaceDown:]
+}
+
+// Top returns the card on top of the pile
+func (p Pile) Top() (deck.Card, bool) {
+	return Top(p.Cards)
+}
+
+// Turn turns the top card face up once the face up ones have gone, and tells you if it did
+func (p *Pile) Turn() bool {
+	if p.FaceDown > 0 && p.FaceDown == len(p.Cards) {
+		p.FaceDown--
+		return true
+	}
+	return false
+}
+
+// Clone returns a copy of the pile that can be played on its own
+func (p Pile) Clone() Pile {
+	return Pile{Cards: append([]deck.Card{}, p.Cards...), FaceDown: p.FaceDown}
+}
+
+// String writes the pile with face down cards as ##, each card after a space
+func (p Pile) String() string {
+	return strings.Repeat(" ##", p.FaceDown) + CardList(p.FaceUp())
+}
+
+// Foundations are the four foundations, by suit, built up from the ace
+type Foundations [4][]deck.Card
+
+// Accepts tells you if the card is the next one for its foundation
+func (f *Foundations) Accepts(c deck.Card) bool {
+	return len(f[c.Suit()]) == c.Face()
+}
+
+// Safe tells you if a card can go to its foundation without being needed in the tableau:
+// aces and twos, or a card whose rank below is up on both foundations of the other colour
+func (f *Foundations) Safe(c deck.Card) bool {
+	if !f.Accepts(c) {
+		return false
+	}
+	if c.Face() <= int(deck.TWO) {
+		return true
+	}
+	for s, cards := range f {
+		if Red(deck.NewCard(deck.ACE, deck.Suit(s))) != Red(c) && len(cards) < c.Face() {
+			return false
+		}
+	}
+	return true
+}
+
+// Full tells you if every card is on the foundations
+func (f *Foundations) Full() bool {
+	for _, cards := range f {
+		if len(cards) < 13 {
+			return false
+		}
+	}
+	return true
+}
+
+// Clone returns a copy of the foundations that can be played on its own
+func (f *Foundations) Clone() Foundations {
+	var c Foundations
+	for s, cards := range f {
+		c[s] = append([]deck.Card{}, cards...)
+	}
+	return c
+}
+
+// String writes the top card of each foundation, or its suit when it's empty, each after a space
+func (f *Foundations) String() string {
+	s := ""
+	for suit, cards := range f {
+		if top, ok := Top(cards); ok {
+			s += " " + top.String()
+		} else {
+			s += " " + SuitName(suit)
+		}
+	}
+	return s
+}
@@ -0,0 +1,34 @@
+package solitaire
+
+import (
+	"testing"
+
+	"adamclerk/deck"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildsOn(t *testing.T) {
+	assert.True(t, BuildsOn(deck.NewCard(deck.SIX, deck.HEART), deck.NewCard(deck.SEVEN, deck.CLUB)))
+	assert.False(t, BuildsOn(deck.NewCard(deck.SIX, deck.HEART), deck.NewCard(deck.SEVEN, deck.DIAMOND)))
+	assert.False(t, BuildsOn(deck.NewCard(deck.FIVE, deck.HEART), deck.NewCard(deck.SEVEN, deck.CLUB)))
+}
+
+func TestFoundations(t *testing.T) {
+	var f Foundations
+	for face := deck.ACE; face <= deck.FOUR; face++ {
+		f[deck.CLUB] = append(f[deck.CLUB], deck.NewCard(face, deck.CLUB))
+	}
+	f[deck.HEART] = []deck.Card{deck.NewCard(deck.ACE, deck.HEART)}
+	assert.Equal(t, " 4♣ ♦ A♥ ♠", f.String())
+	assert.True(t, f.Accepts(deck.NewCard(deck.FIVE, deck.CLUB)))
+	assert.False(t, f.Accepts(deck.NewCard(deck.SIX, deck.CLUB)))
+	// the fives of the other colour could still need the four to build on
+	assert.False(t, f.Safe(deck.NewCard(deck.FIVE, deck.CLUB)))
+	assert.True(t, f.Safe(deck.NewCard(deck.TWO, deck.HEART)))
+	assert.True(t, f.Safe(deck.NewCard(deck.ACE, deck.SPADE)))
+	assert.False(t, f.Full())
+
+	c := f.Clone()
+	c[deck.CLUB] = c[deck.CLUB][:1]
+	assert.Len(t, f[deck.CLUB], 4)
+}
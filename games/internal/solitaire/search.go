@@ -0,0 +1,268 @@
+package solitaire
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"time"
+
+	"adamclerk/deck"
+)
+
+// Verdict is what the solver found
+type Verdict int
+
+// Constants for Verdict
+const (
+	Solved     Verdict = iota // the moves win the game
+	Unwinnable                // every position was searched: no sequence of moves wins
+	Lost                      // in standard mode, the solver turned a card over and could not win from there
+	Undecided                 // the search ran out of states or time
+)
+
+var verdicts = []string{"Solved", "Unwinnable", "Lost", "Undecided"}
+
+func (v Verdict) String() string {
+	if v < 0 || int(v) >= len(verdicts) {
+		return fmt.Sprintf("Verdict(%d)", int(v))
+	}
+	return verdicts[v]
+}
+
+// Mode is what the solver may look at
+type Mode int
+
+// Constants for Mode
+const (
+	Thoughtful Mode = iota // every card is known, the face down ones included
+	Standard               // face down cards stay unknown until they are turned over
+)
+
+var modes = []string{"Thoughtful", "Standard"}
+
+func (m Mode) String() string {
+	if m < 0 || int(m) >= len(modes) {
+		return fmt.Sprintf("Mode(%d)", int(m))
+	}
+	return modes[m]
+}
+
+// SolveOptions how to configure the solver
+type SolveOptions struct {
+	Mode      Mode
+	MaxStates int
+	Timeout   time.Duration
+}
+
+// WithMode sets what the solver may look at
+func WithMode(mode Mode) func(*SolveOptions) {
+	return func(o *SolveOptions) {
+		o.Mode = mode
+	}
+}
+
+// MaxStates sets how many positions the solver searches before it gives up, a million by default
+func MaxStates(count int) func(*SolveOptions) {
+	return func(o *SolveOptions) {
+		o.MaxStates = count
+	}
+}
+
+// Timeout sets how long the solver searches before it gives up; there's no limit by default
+func Timeout(d time.Duration) func(*SolveOptions) {
+	return func(o *SolveOptions) {
+		o.Timeout = d
+	}
+}
+
+// NewSolveOptions applies the options to the defaults, thoughtful mode and a million states, and checks them
+func NewSolveOptions(options ...func(*SolveOptions)) (SolveOptions, error) {
+	opt := SolveOptions{Mode: Thoughtful, MaxStates: 1000000}
+	for _, option := range options {
+		option(&opt)
+	}
+	if opt.Mode != Thoughtful && opt.Mode != Standard {
+		return opt, fmt.Errorf("Unknown mode %s", opt.Mode)
+	}
+	if opt.MaxStates < 1 {
+		return opt, errors.New("Invalid state limit")
+	}
+	if opt.Timeout < 0 {
+		return opt, errors.New("Invalid timeout")
+	}
+	return opt, nil
+}
+
+// Game is a game as the search plays it, moving cards and taking them back
+type Game interface {
+	Won() bool
+	// Key writes the position
+	Key(k *Key)
+	// Candidates returns the moves worth trying, the most promising first
+	Candidates() []Candidate
+	// PlaySafe plays a safe card to its foundation, and tells you if there was one
+	PlaySafe() bool
+	// TakeBack takes back the last move
+	TakeBack()
+}
+
+// Candidate plays a move worth trying with Search.Play, and returns how many moves it played,
+// if it could be played through and if it turned a face down card over
+type Candidate func() (played int, ok, turned bool)
+
+// Search looks for a win depth first, with a transposition table so that no position is searched twice.
+// It plays safe cards to the foundations after every move. In standard mode, once a move turns a card over
+// it has to go on from there: what was turned over can't be unseen.
+type Search struct {
+	Auto     []bool // which of the game's moves were played automatically
+	States   int    // the positions searched
+	game     Game
+	mode     Mode
+	max      int
+	deadline time.Time
+	seen     map[[16]byte]bool
+	key      Key
+	stopped  bool // out of states or time
+	lost     bool // a card was turned over in standard mode, and the game was lost from there
+}
+
+// NewSearch starts a search of a game that has played moves so far
+func NewSearch(game Game, opt SolveOptions, moves int) *Search {
+	s := &Search{
+		Auto: make([]bool, moves),
+		game: game,
+		mode: opt.Mode,
+		max:  opt.MaxStates,
+		seen: map[[16]byte]bool{},
+		key:  Key{hash: fnv.New128a()},
+	}
+	if opt.Timeout > 0 {
+		s.deadline = time.Now().Add(opt.Timeout)
+	}
+	return s
+}
+
+// Run plays the safe moves, then searches. When the game is solved the winning moves are left played.
+func (s *Search) Run() Verdict {
+	s.autoMove()
+	switch {
+	case s.search():
+		return Solved
+	case s.stopped:
+		return Undecided
+	case s.lost:
+		return Lost
+	}
+	return Unwinnable
+}
+
+// search tells you if the game can be won from the position. When it can, the winning moves are left played.
+func (s *Search) search() bool {
+	if s.game.Won() {
+		return true
+	}
+	s.game.Key(&s.key)
+	key := s.key.sum()
+	if s.seen[key] {
+		return false
+	}
+	if s.States >= s.max || (!s.deadline.IsZero() && s.States%1024 == 0 && time.Now().After(s.deadline)) {
+		s.stopped = true
+		return false
+	}
+	s.seen[key] = true
+	s.States++
+
+	for _, try := range s.game.Candidates() {
+		played, ok, turned := try()
+		if ok {
+			if s.search() {
+				return true
+			}
+			if s.stopped || s.lost {
+				return false
+			}
+			if s.mode == Standard && turned {
+				s.lost = true
+				return false
+			}
+		}
+		s.TakeBack(played)
+	}
+	return false
+}
+
+// Play plays a move with play, then the safe moves, and returns how many moves it played
+func (s *Search) Play(play func()) int {
+	play()
+	s.Auto = append(s.Auto, false)
+	return 1 + s.autoMove()
+}
+
+func (s *Search) autoMove() int {
+	played := 0
+	for s.game.PlaySafe() {
+		s.Auto = append(s.Auto, true)
+		played++
+	}
+	return played
+}
+
+// TakeBack takes back the moves played last
+func (s *Search) TakeBack(played int) {
+	for i := 0; i < played; i++ {
+		s.game.TakeBack()
+	}
+	s.Auto = s.Auto[:len(s.Auto)-played]
+}
+
+// Key identifies a position in 16 bytes. Columns are sorted, since their order makes no difference.
+type Key struct {
+	hash    hash.Hash
+	columns [][]byte
+	buf     []byte
+	rest    []byte
+}
+
+// Column writes a column: how many cards are face down, then the cards
+func (k *Key) Column(faceDown int, cards []deck.Card) {
+	at := len(k.buf)
+	k.buf = append(k.buf, byte(faceDown))
+	for _, c := range cards {
+		k.buf = append(k.buf, byte(c))
+	}
+	k.buf = append(k.buf, 0xff)
+	k.columns = append(k.columns, k.buf[at:len(k.buf):len(k.buf)])
+}
+
+// Cards writes cards after the columns
+func (k *Key) Cards(cards []deck.Card) {
+	for _, c := range cards {
+		k.rest = append(k.rest, byte(c))
+	}
+}
+
+// Write writes bytes after the columns
+func (k *Key) Write(b ...byte) {
+	k.rest = append(k.rest, b...)
+}
+
+func (k *Key) sum() [16]byte {
+	columns := k.columns
+	for i := 1; i < len(columns); i++ {
+		for j := i; j > 0 && bytes.Compare(columns[j], columns[j-1]) < 0; j-- {
+			columns[j], columns[j-1] = columns[j-1], columns[j]
+		}
+	}
+	k.hash.Reset()
+	for _, c := range columns {
+		k.hash.Write(c)
+	}
+	k.hash.Write(k.rest)
+	var key [16]byte
+	k.hash.Sum(key[:0])
+	k.columns, k.buf, k.rest = k.columns[:0], k.buf[:0], k.rest[:0]
+	return key
+}
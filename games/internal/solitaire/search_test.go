@@ -0,0 +1,127 @@
+package solitaire
+
+import (
+	"hash/fnv"
+	"testing"
+	"time"
+
+	"adamclerk/deck"
+	"github.com/stretchr/testify/assert"
+)
+
+// count is a game of adding to a number to reach the target without going past it
+type count struct {
+	search *Search
+	n      int
+	target int
+	steps  []int
+	turns  bool // steps of two turn a card over
+	safe   int  // one is added by itself from here
+	played []int
+}
+
+func (c *count) Won() bool {
+	return c.n == c.target
+}
+
+func (c *count) Key(k *Key) {
+	k.Write(byte(c.n))
+}
+
+func (c *count) Candidates() []Candidate {
+	tries := []Candidate{}
+	for _, step := range c.steps {
+		step := step
+		if c.n+step <= c.target {
+			tries = append(tries, func() (int, bool, bool) {
+				return c.search.Play(func() { c.add(step) }), true, c.turns && step == 2
+			})
+		}
+	}
+	return tries
+}
+
+func (c *count) PlaySafe() bool {
+	if c.n != c.safe {
+		return false
+	}
+	c.add(1)
+	return true
+}
+
+func (c *count) add(step int) {
+	c.n += step
+	c.played = append(c.played, step)
+}
+
+func (c *count) TakeBack() {
+	last := len(c.played) - 1
+	c.n -= c.played[last]
+	c.played = c.played[:last]
+}
+
+func run(c *count, options ...func(*SolveOptions)) Verdict {
+	opt, _ := NewSolveOptions(options...)
+	c.search = NewSearch(c, opt, 0)
+	return c.search.Run()
+}
+
+func TestSearch(t *testing.T) {
+	c := &count{target: 5, steps: []int{2}, safe: 2}
+	assert.Equal(t, Solved, run(c))
+	assert.Equal(t, []int{2, 1, 2}, c.played)
+	assert.Equal(t, []bool{false, true, false}, c.search.Auto)
+
+	c = &count{target: 5, steps: []int{2}, safe: -1}
+	assert.Equal(t, Unwinnable, run(c))
+	assert.Equal(t, 3, c.search.States)
+	assert.Len(t, c.played, 0)
+
+	c = &count{target: 5, steps: []int{2}, safe: -1, turns: true}
+	assert.Equal(t, Unwinnable, run(c))
+	c = &count{target: 5, steps: []int{2}, safe: -1, turns: true}
+	assert.Equal(t, Lost, run(c, WithMode(Standard)))
+	c = &count{target: 5, steps: []int{2, 1}, safe: -1, turns: true}
+	assert.Equal(t, Solved, run(c, WithMode(Standard)))
+
+	c = &count{target: 50, steps: []int{1}, safe: -1}
+	assert.Equal(t, Undecided, run(c, MaxStates(10)))
+	assert.Equal(t, 10, c.search.States)
+	c = &count{target: 50, steps: []int{1}, safe: -1}
+	assert.Equal(t, Undecided, run(c, Timeout(time.Nanosecond)))
+}
+
+func TestSolveOptions(t *testing.T) {
+	opt, err := NewSolveOptions()
+	assert.Nil(t, err)
+	assert.Equal(t, SolveOptions{Mode: Thoughtful, MaxStates: 1000000}, opt)
+
+	tests := []struct {
+		option func(*SolveOptions)
+		err    string
+	}{
+		{MaxStates(0), "Invalid state limit"},
+		{Timeout(-time.Second), "Invalid timeout"},
+		{WithMode(Mode(5)), "Unknown mode Mode(5)"},
+	}
+	for _, test := range tests {
+		_, err := NewSolveOptions(test.option)
+		if assert.NotNil(t, err, test.err) {
+			assert.Equal(t, test.err, err.Error())
+		}
+	}
+}
+
+func TestKey(t *testing.T) {
+	k := &Key{hash: fnv.New128a()}
+	sum := func(columns ...[]deck.Card) [16]byte {
+		for _, c := range columns {
+			k.Column(0, c)
+		}
+		return k.sum()
+	}
+	// the order of the columns makes no difference, what's in them does
+	a, b := []deck.Card{1, 2}, []deck.Card{3}
+	assert.Equal(t, sum(a, b), sum(b, a))
+	assert.NotEqual(t, sum(a, b), sum([]deck.Card{1}, []deck.Card{2, 3}))
+}
@@ -0,0 +1,199 @@
+// Package klondike is the Klondike solitaire: seven columns, four foundations, a stock and a waste.
+package klondike
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"adamclerk/deck"
+	"adamclerk/deck/games/internal/solitaire"
+)
+
+// Unlimited redeals
+const Unlimited = -1
+
+// Klondike is a game of Klondike solitaire.
+// Rules can be found here: https://bicyclecards.com/how-to-play/solitaire/
+type Klondike struct {
+	debug       bool
+	draw        int
+	redealLimit int
+	autoPlay    bool
+	signature   string
+	tableau     [7]Pile
+	foundations solitaire.Foundations
+	stock       []deck.Card
+	waste       []deck.Card
+	redeals     int
+	moves       []Move
+	undo        []int
+}
+
+// Pile is a column of the tableau. The first FaceDown cards are face down, the last card is on top.
+type Pile = solitaire.Pile
+
+// Options how to configure a game of Klondike
+type Options struct {
+	DeckOptions []func(*deck.Options)
+	Draw        int
+	Redeals     int
+	AutoPlay    bool
+	Debug       bool
+}
+
+// WithDeck allows the a game to be configured with a specific deck
+func WithDeck(deckOptions ...func(*deck.Options)) func(*Options) {
+	return func(o *Options) {
+		o.DeckOptions = deckOptions
+	}
+}
+
+// WithSignature deals the deal a deck signature describes, as returned by Signature, to replay or share it
+func WithSignature(signature string) func(*Options) {
+	return WithDeck(deck.FromSignature(signature), deck.Unshuffled)
+}
+
+// DrawThree turns three cards at a time from the stock
+func DrawThree(o *Options) {
+	o.Draw = 3
+}
+
+// Redeals sets how many times the waste can be turned back over into the stock, or Unlimited
+func Redeals(count int) func(*Options) {
+	return func(o *Options) {
+		o.Redeals = count
+	}
+}
+
+// AutoPlay plays safe cards to the foundations after every move
+func AutoPlay(o *Options) {
+	o.AutoPlay = true
+}
+
+// Debug sets the debug param for the game
+func Debug(o *Options) {
+	o.Debug = true
+}
+
+// New function creates a new game of Klondike: draw one with unlimited redeals unless configured otherwise
+func New(options ...func(*Options)) (*Klondike, error) {
+	opt := Options{DeckOptions: []func(*deck.Options){}, Draw: 1, Redeals: Unlimited}
+	for _, option := range options {
+		option(&opt)
+	}
+	if opt.Redeals < Unlimited {
+		return nil, errors.New("Invalid redeal limit")
+	}
+	d, err := deck.New(opt.DeckOptions...)
+	if err != nil {
+		return nil, err
+	}
+	if err := solitaire.FullDeck("Klondike", d.Cards); err != nil {
+		return nil, err
+	}
+	g := &Klondike{
+		debug:       opt.Debug,
+		draw:        opt.Draw,
+		redealLimit: opt.Redeals,
+		autoPlay:    opt.AutoPlay,
+		signature:   d.GetSignature(),
+	}
+	g.deal(d.Cards)
+	g.debugf("%s\n", g)
+	if g.autoPlay {
+		g.AutoMove()
+	}
+	return g, nil
+}
+
+// deal lays the columns out row by row, the last card of each face up; the rest is the stock
+func (g *Klondike) deal(cards []deck.Card) {
+	n := 0
+	for row := 0; row < 7; row++ {
+		for col := row; col < 7; col++ {
+			g.tableau[col].Cards = append(g.tableau[col].Cards, cards[n])
+			n++
+		}
+	}
+	for col := range g.tableau {
+		g.tableau[col].FaceDown = col
+	}
+	g.stock = append([]deck.Card{}, cards[n:]...)
+}
+
+func (g *Klondike) debugf(format string, a ...interface{}) {
+	deck.Debugf(g.debug, format, a...)
+}
+
+// Signature returns the signature of the deck the game was dealt from
+func (g *Klondike) Signature() string {
+	return g.signature
+}
+
+// Tableau returns a column, 0 to 6
+func (g *Klondike) Tableau(column int) Pile {
+	return g.tableau[column]
+}
+
+// Foundation returns the cards on the foundation of a suit, the ace first
+func (g *Klondike) Foundation(suit deck.Suit) []deck.Card {
+	return g.foundations[suit]
+}
+
+// Stock returns the cards left in the stock, the next one to turn first
+func (g *Klondike) Stock() []deck.Card {
+	return g.stock
+}
+
+// Waste returns the waste, the card that can be played last
+func (g *Klondike) Waste() []deck.Card {
+	return g.waste
+}
+
+func (g *Klondike) wasteTop() (deck.Card, bool) {
+	return solitaire.Top(g.waste)
+}
+
+// RedealsLeft returns how many more times the waste can be turned over, or Unlimited
+func (g *Klondike) RedealsLeft() int {
+	if g.redealLimit == Unlimited {
+		return Unlimited
+	}
+	return g.redealLimit - g.redeals
+}
+
+// Moves returns the moves played so far, automatic ones included
+func (g *Klondike) Moves() []Move {
+	return g.moves
+}
+
+// Won tells you if every card is on the foundations
+func (g *Klondike) Won() bool {
+	return g.foundations.Full()
+}
+
+// Clone returns a copy of the game that can be played on its own
+func (g *Klondike) Clone() *Klondike {
+	c := *g
+	for i, p := range g.tableau {
+		c.tableau[i] = p.Clone()
+	}
+	c.foundations = g.foundations.Clone()
+	c.stock = append([]deck.Card{}, g.stock...)
+	c.waste = append([]deck.Card{}, g.waste...)
+	c.moves = append([]Move{}, g.moves...)
+	c.undo = append([]int{}, g.undo...)
+	return &c
+}
+
+// String draws the game: the stock and the waste, the foundations, then the columns with face down cards as ##
+func (g *Klondike) String() string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "Stock: %d  Waste:%s\n", len(g.stock), solitaire.CardList(g.waste))
+	fmt.Fprintf(b, "Foundations:%s\n", g.foundations.String())
+	for i, p := range g.tableau {
+		fmt.Fprintf(b, "T%d:%s\n", i+1, p)
+	}
+	return b.String()
+}
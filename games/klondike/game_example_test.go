@@ -0,0 +1,72 @@
+package klondike
+
+import (
+	"fmt"
+
+	"adamclerk/deck"
+)
+
+// This example deals a game from a seeded deck, then plays the first legal move
+func Example() {
+	game, err := New(WithDeck(deck.FromSeed("klondike")), DrawThree, Redeals(2))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Print(game)
+	fmt.Println(game.LegalMoves())
+	if err := game.Move(game.LegalMoves()[0]); err != nil {
+		panic(err)
+	}
+	fmt.Print(game)
+	// Output:
+	// Stock: 24  Waste:
+	// Foundations: ♣ ♦ ♥ ♠
+	// T1: K♠
+	// T2: ## 9♦
+	// T3: ## ## 6♣
+	// T4: ## ## ## J♣
+	// T5: ## ## ## ## 8♥
+	// T6: ## ## ## ## ## 9♥
+	// T7: ## ## ## ## ## ## A♣
+	// [T7-♣ Draw]
+	// Stock: 24  Waste:
+	// Foundations: A♣ ♦ ♥ ♠
+	// T1: K♠
+	// T2: ## 9♦
+	// T3: ## ## 6♣
+	// T4: ## ## ## J♣
+	// T5: ## ## ## ## 8♥
+	// T6: ## ## ## ## ## 9♥
+	// T7: ## ## ## ## ## 7♦
+}
+
+// This example replays a shared deal from its signature
+func ExampleWithSignature() {
+	game, _ := New(WithDeck(deck.FromSeed("klondike")))
+	replay, _ := New(WithSignature(game.Signature()))
+	fmt.Println(replay.Tableau(6).FaceUp(), replay.Stock()[:3])
+	// Output: [A♣] [2♥ T♣ 2♦]
+}
+
+// This example picks out the deals the solver wins, the way a game would offer casual players only winnable deals
+func ExampleKlondike_Solve() {
+	for i := 0; i < 5; i++ {
+		shuffled, _ := deck.New(deck.FromSeed(fmt.Sprint("deal", i)))
+		game, _ := New(WithSignature(shuffled.GetSignature()), DrawThree)
+		solution, err := game.Solve(MaxStates(5000))
+		if err != nil {
+			panic(err)
+		}
+		fmt.Printf("deal%d: %s", i, solution.Verdict)
+		if solution.Verdict == Solved {
+			fmt.Printf(" in %d moves, starting %v", len(solution.Moves), solution.Moves[:4])
+		}
+		fmt.Println()
+	}
+	// Output:
+	// deal0: Solved in 159 moves, starting [T7-T5 T5-T2 x2 T7-T5 T7-T6]
+	// deal1: Undecided
+	// deal2: Solved in 116 moves, starting [T2-♥ T3-T5 T7-T3 T7-T1]
+	// deal3: Undecided
+	// deal4: Solved in 146 moves, starting [T7-T3 T6-T5 T2-T6 Draw]
+}
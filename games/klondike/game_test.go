@@ -0,0 +1,263 @@
+package klondike
+
+import (
+	"math/rand"
+	"testing"
+
+	"adamclerk/deck"
+	"adamclerk/deck/games/internal/solitaire"
+	"adamclerk/deck/internal/decktest"
+	"github.com/stretchr/testify/assert"
+)
+
+// empty returns a game with nothing dealt, to lay a position out by hand
+func empty(options ...func(*Options)) *Klondike {
+	g, _ := New(options...)
+	g.tableau = [7]Pile{}
+	g.stock, g.waste = nil, nil
+	return g
+}
+
+// found puts the cards of a suit up to the face on its foundation
+func (g *Klondike) found(suit deck.Suit, face deck.Face) {
+	g.foundations[suit] = nil
+	for f := deck.ACE; f <= face; f++ {
+		g.foundations[suit] = append(g.foundations[suit], deck.NewCard(f, suit))
+	}
+}
+
+func TestNew(t *testing.T) {
+	g, err := New(WithDeck(deck.Unshuffled))
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, Pile{Cards: decktest.Cards("Ac"), FaceDown: 0}, g.Tableau(0))
+	assert.Equal(t, Pile{Cards: decktest.Cards("2c", "8c"), FaceDown: 1}, g.Tableau(1))
+	assert.Equal(t, Pile{Cards: decktest.Cards("7c", "Kc", "5d", "9d", "Qd", "Ah", "2h"), FaceDown: 6}, g.Tableau(6))
+	assert.Equal(t, decktest.Cards("2h"), g.Tableau(6).FaceUp())
+	assert.Len(t, g.Stock(), 24)
+	assert.Equal(t, decktest.Cards("3h"), g.Stock()[:1])
+	assert.Len(t, g.Waste(), 0)
+	assert.Equal(t, Unlimited, g.RedealsLeft())
+	assert.False(t, g.Won())
+
+	// a deal replays from its signature
+	shuffled, _ := New()
+	replay, err := New(WithSignature(shuffled.Signature()))
+	if assert.Nil(t, err) {
+		assert.Equal(t, shuffled.String(), replay.String())
+		assert.Equal(t, shuffled.Stock(), replay.Stock())
+		assert.Equal(t, shuffled.Signature(), replay.Signature())
+	}
+
+	_, err = New(Redeals(-2))
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "Invalid redeal limit", err.Error())
+	}
+	_, err = New(WithDeck(deck.Decks(2)))
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "Klondike needs the 52 cards of a deck, not 104", err.Error())
+	}
+	cards := decktest.Cards("Ac", "Ac")
+	full, _ := deck.New(deck.Unshuffled)
+	cards = append(cards, full.Cards[2:]...)
+	_, err = New(WithDeck(deck.Unshuffled, deck.WithCards(cards...)))
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "Klondike needs the 52 cards of a deck, A♣ is there twice", err.Error())
+	}
+}
+
+func TestLegalMoves(t *testing.T) {
+	g := empty()
+	g.found(deck.CLUB, deck.TWO)
+	g.found(deck.HEART, deck.ACE)
+	g.found(deck.DIAMOND, deck.SIX)
+	g.tableau[0] = Pile{Cards: decktest.Cards("Kd", "3c"), FaceDown: 1}
+	g.tableau[1] = Pile{Cards: decktest.Cards("4h"), FaceDown: 0}
+	g.tableau[2] = Pile{Cards: decktest.Cards("5c", "9s", "8h", "7s"), FaceDown: 1}
+	g.tableau[3] = Pile{Cards: decktest.Cards("9c"), FaceDown: 0}
+	g.tableau[4] = Pile{Cards: decktest.Cards("5d", "Ks", "Qh"), FaceDown: 1}
+	g.stock = decktest.Cards("2d")
+	g.waste = decktest.Cards("Jc")
+
+	moves := []string{}
+	for _, m := range g.LegalMoves() {
+		moves = append(moves, m.String())
+	}
+	assert.Equal(t, []string{"T1-♣", "T1-T2", "T3-T4 x2", "T5-T6 x2", "T5-T7 x2", "W-T5", "♦-T3", "Draw"}, moves)
+
+	// the face down card turns over
+	assert.Nil(t, g.Move(Move{Kind: TableauToFoundation, From: 0, To: int(deck.CLUB)}))
+	assert.Equal(t, Pile{Cards: decktest.Cards("Kd"), FaceDown: 0}, g.Tableau(0))
+	assert.Nil(t, g.Move(Move{Kind: TableauToTableau, From: 2, To: 3, Cards: 2}))
+	assert.Equal(t, Pile{Cards: decktest.Cards("5c", "9s"), FaceDown: 1}, g.Tableau(2))
+	assert.Equal(t, decktest.Cards("9c", "8h", "7s"), g.Tableau(3).Cards)
+	assert.Nil(t, g.Move(Move{Kind: WasteToTableau, To: 4}))
+	assert.Equal(t, decktest.Cards("5d", "Ks", "Qh", "Jc"), g.Tableau(4).Cards)
+	assert.Len(t, g.Moves(), 3)
+
+	illegal := []Move{
+		{Kind: Redeal},
+		{Kind: WasteToFoundation, To: 0},
+		{Kind: TableauToTableau, From: 1, To: 1, Cards: 1},
+		{Kind: TableauToTableau, From: 3, To: 5, Cards: 3},
+		{Kind: TableauToTableau, From: 3, To: 0, Cards: 4},
+		{Kind: TableauToFoundation, From: 7, To: 0},
+		{Kind: FoundationToTableau, From: 1, To: 2},
+		{Kind: MoveKind(9)},
+	}
+	for _, m := range illegal {
+		err := g.Move(m)
+		if assert.NotNil(t, err, m.String()) {
+			assert.Equal(t, "Illegal move "+m.String(), err.Error())
+		}
+	}
+	assert.Len(t, g.Moves(), 3)
+}
+
+func TestStock(t *testing.T) {
+	g, _ := New(WithDeck(deck.Unshuffled), DrawThree, Redeals(1))
+	assert.Nil(t, g.Move(Move{Kind: Draw}))
+	assert.Equal(t, decktest.Cards("3h", "4h", "5h"), g.Waste())
+	for len(g.Stock()) > 0 {
+		assert.Nil(t, g.Move(Move{Kind: Draw}))
+	}
+	assert.Len(t, g.Waste(), 24)
+	assert.Equal(t, 1, g.RedealsLeft())
+	assert.Nil(t, g.Move(Move{Kind: Redeal}))
+	assert.Equal(t, 0, g.RedealsLeft())
+	assert.Equal(t, decktest.Cards("3h"), g.Stock()[:1])
+	for len(g.Stock()) > 0 {
+		assert.Nil(t, g.Move(Move{Kind: Draw}))
+	}
+	assert.NotNil(t, g.Move(Move{Kind: Redeal}))
+	for _, m := range g.LegalMoves() {
+		assert.NotEqual(t, Redeal, m.Kind)
+	}
+
+	// a short stock turns what's left
+	g = empty()
+	g.stock = decktest.Cards("2c", "3c")
+	g.draw = 3
+	assert.Nil(t, g.Move(Move{Kind: Draw}))
+	assert.Equal(t, decktest.Cards("2c", "3c"), g.Waste())
+	assert.Len(t, g.Stock(), 0)
+}
+
+func TestAutoMove(t *testing.T) {
+	g := empty()
+	g.found(deck.CLUB, deck.FOUR)
+	g.found(deck.SPADE, deck.THREE)
+	g.found(deck.HEART, deck.THREE)
+	g.tableau[0] = Pile{Cards: decktest.Cards("Ad")}
+	g.tableau[1] = Pile{Cards: decktest.Cards("4h")}
+	g.tableau[2] = Pile{Cards: decktest.Cards("4s", "2d")}
+	g.tableau[3] = Pile{Cards: decktest.Cards("6c")}
+	g.waste = decktest.Cards("5c", "3d")
+	// the 4♥ is safe with the black threes up, the 4♠ once the 3♦ is up too,
+	// and the 5♣ waits for the red fours
+	played := g.AutoMove()
+	assert.Len(t, played, 5)
+	assert.Equal(t, decktest.Cards("Ad", "2d", "3d"), g.Foundation(deck.DIAMOND))
+	assert.Len(t, g.Foundation(deck.HEART), 4)
+	assert.Len(t, g.Foundation(deck.SPADE), 4)
+	assert.Len(t, g.Tableau(2).Cards, 0)
+	assert.Equal(t, decktest.Cards("5c"), g.Waste())
+	assert.Len(t, g.Tableau(3).Cards, 1)
+}
+
+func TestAutoPlay(t *testing.T) {
+	g, _ := New(WithDeck(deck.Unshuffled), AutoPlay)
+	// the A♣ and the A♦ go up as soon as they're dealt
+	assert.Equal(t, decktest.Cards("Ac"), g.Foundation(deck.CLUB))
+	assert.Equal(t, decktest.Cards("Ad"), g.Foundation(deck.DIAMOND))
+	assert.Len(t, g.Tableau(0).Cards, 0)
+	assert.Equal(t, []Move{{Kind: TableauToFoundation, From: 0, To: 0}, {Kind: TableauToFoundation, From: 2, To: 1}}, g.Moves())
+}
+
+func TestClone(t *testing.T) {
+	g, _ := New()
+	c := g.Clone()
+	c.Move(Move{Kind: Draw})
+	assert.Len(t, g.Waste(), 0)
+	assert.Len(t, c.Waste(), 1)
+	assert.Equal(t, g.Stock()[1:], c.Stock())
+}
+
+// TestRandomPlay plays random legal moves and checks no card is lost and every column builds down
+func TestRandomPlay(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	for game := 0; game < 50; game++ {
+		options := []func(*Options){WithDeck(deck.FromSeed(string(rune('a' + game))))}
+		if game%2 == 0 {
+			options = append(options, DrawThree, Redeals(2))
+		}
+		g, err := New(options...)
+		if !assert.Nil(t, err) {
+			return
+		}
+		for i := 0; i < 300; i++ {
+			moves := g.LegalMoves()
+			if len(moves) == 0 {
+				break
+			}
+			if !assert.Nil(t, g.Move(moves[r.Intn(len(moves))])) {
+				return
+			}
+			seen := map[deck.Card]bool{}
+			count := func(cards []deck.Card) {
+				for _, c := range cards {
+					assert.False(t, seen[c], c.String())
+					seen[c] = true
+				}
+			}
+			count(g.Stock())
+			count(g.Waste())
+			for s := range g.foundations {
+				count(g.Foundation(deck.Suit(s)))
+			}
+			for col := 0; col < 7; col++ {
+				p := g.Tableau(col)
+				count(p.Cards)
+				up := p.FaceUp()
+				assert.True(t, len(up) > 0 || len(p.Cards) == 0)
+				for k := 1; k < len(up); k++ {
+					assert.True(t, solitaire.BuildsOn(up[k], up[k-1]), "%s on %s", up[k], up[k-1])
+				}
+			}
+			assert.Len(t, seen, 52)
+		}
+	}
+}
+
+func TestUndo(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	for _, options := range [][]func(*Options){deal(3), deal(3, DrawThree, Redeals(1), AutoPlay)} {
+		g, _ := New(options...)
+		start := g.String()
+		for i := 0; i < 200; i++ {
+			moves := g.LegalMoves()
+			if len(moves) == 0 {
+				break
+			}
+			// a move and the safe moves after it are taken back one at a time
+			before, played := g.String(), len(g.Moves())
+			assert.Nil(t, g.Move(moves[0]))
+			for len(g.Moves()) > played {
+				assert.Nil(t, g.Undo())
+			}
+			assert.Equal(t, before, g.String())
+			assert.Nil(t, g.Move(moves[r.Intn(len(moves))]))
+		}
+		for len(g.Moves()) > 0 {
+			assert.Nil(t, g.Undo())
+		}
+		assert.Equal(t, start, g.String())
+		assert.Equal(t, 0, g.redeals)
+	}
+	g, _ := New()
+	err := g.Undo()
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "Nothing to undo", err.Error())
+	}
+}
@@ -0,0 +1,297 @@
+package klondike
+
+import (
+	"errors"
+	"fmt"
+
+	"adamclerk/deck"
+	"adamclerk/deck/games/internal/solitaire"
+)
+
+// MoveKind is what a move does
+type MoveKind int
+
+// Constants for MoveKind
+const (
+	Draw                MoveKind = iota // turns cards from the stock onto the waste
+	Redeal                              // turns the waste back over into the stock
+	WasteToFoundation                   // plays the top of the waste to its foundation
+	WasteToTableau                      // builds the top of the waste on a column
+	TableauToFoundation                 // plays the bottom card of a column to its foundation
+	TableauToTableau                    // moves face up cards from one column to another
+	FoundationToTableau                 // takes the top of a foundation back onto a column
+)
+
+var moveKinds = []string{"Draw", "Redeal", "WasteToFoundation", "WasteToTableau", "TableauToFoundation", "TableauToTableau", "FoundationToTableau"}
+
+func (k MoveKind) String() string {
+	if k < 0 || int(k) >= len(moveKinds) {
+		return fmt.Sprintf("MoveKind(%d)", int(k))
+	}
+	return moveKinds[k]
+}
+
+// Move is a move in a game. From and To are columns, 0 to 6, or the suit of a foundation;
+// Cards is how many cards a tableau to tableau move takes.
+type Move struct {
+	Kind  MoveKind
+	From  int
+	To    int
+	Cards int
+}
+
+// String writes the move short, with columns counted from 1: T1, T2... for the tableau, W for the waste,
+// and the foundation's suit
+func (m Move) String() string {
+	switch m.Kind {
+	case Draw:
+		return "Draw"
+	case Redeal:
+		return "Redeal"
+	case WasteToFoundation:
+		return fmt.Sprintf("W-%s", solitaire.SuitName(m.To))
+	case WasteToTableau:
+		return fmt.Sprintf("W-T%d", m.To+1)
+	case TableauToFoundation:
+		return fmt.Sprintf("T%d-%s", m.From+1, solitaire.SuitName(m.To))
+	case TableauToTableau:
+		if m.Cards > 1 {
+			return fmt.Sprintf("T%d-T%d x%d", m.From+1, m.To+1, m.Cards)
+		}
+		return fmt.Sprintf("T%d-T%d", m.From+1, m.To+1)
+	case FoundationToTableau:
+		return fmt.Sprintf("%s-T%d", solitaire.SuitName(m.From), m.To+1)
+	}
+	return m.Kind.String()
+}
+
+// LegalMoves returns every move that can be played now, foundation moves first.
+// Moving a king that heads its column to an empty column is left out, since it changes nothing.
+func (g *Klondike) LegalMoves() []Move {
+	moves := []Move{}
+	if c, ok := g.wasteTop(); ok && g.foundations.Accepts(c) {
+		moves = append(moves, Move{Kind: WasteToFoundation, To: c.Suit()})
+	}
+	for i, p := range g.tableau {
+		if c, ok := p.Top(); ok && g.foundations.Accepts(c) {
+			moves = append(moves, Move{Kind: TableauToFoundation, From: i, To: c.Suit()})
+		}
+	}
+	for i, p := range g.tableau {
+		for k := p.FaceDown; k < len(p.Cards); k++ {
+			for j := range g.tableau {
+				if j != i && g.toTableau(p.Cards[k], j) && !(k == 0 && len(g.tableau[j].Cards) == 0) {
+					moves = append(moves, Move{Kind: TableauToTableau, From: i, To: j, Cards: len(p.Cards) - k})
+				}
+			}
+		}
+	}
+	if c, ok := g.wasteTop(); ok {
+		for j := range g.tableau {
+			if g.toTableau(c, j) {
+				moves = append(moves, Move{Kind: WasteToTableau, To: j})
+			}
+		}
+	}
+	for s, f := range g.foundations {
+		if len(f) == 0 {
+			continue
+		}
+		for j := range g.tableau {
+			if g.toTableau(f[len(f)-1], j) {
+				moves = append(moves, Move{Kind: FoundationToTableau, From: s, To: j})
+			}
+		}
+	}
+	if len(g.stock) > 0 {
+		moves = append(moves, Move{Kind: Draw})
+	} else if len(g.waste) > 0 && g.RedealsLeft() != 0 {
+		moves = append(moves, Move{Kind: Redeal})
+	}
+	return moves
+}
+
+// toTableau tells you if the card can go on the column: a king on an empty one, or a build
+func (g *Klondike) toTableau(c deck.Card, column int) bool {
+	top, ok := g.tableau[column].Top()
+	if !ok {
+		return deck.Face(c.Face()) == deck.KING
+	}
+	return solitaire.BuildsOn(c, top)
+}
+
+// legal tells you if the move can be played now
+func (g *Klondike) legal(m Move) bool {
+	switch m.Kind {
+	case Draw:
+		return len(g.stock) > 0
+	case Redeal:
+		return len(g.stock) == 0 && len(g.waste) > 0 && g.RedealsLeft() != 0
+	case WasteToFoundation:
+		c, ok := g.wasteTop()
+		return ok && c.Suit() == m.To && g.foundations.Accepts(c)
+	case WasteToTableau:
+		c, ok := g.wasteTop()
+		return ok && column(m.To) && g.toTableau(c, m.To)
+	case TableauToFoundation:
+		if !column(m.From) {
+			return false
+		}
+		c, ok := g.tableau[m.From].Top()
+		return ok && c.Suit() == m.To && g.foundations.Accepts(c)
+	case TableauToTableau:
+		if !column(m.From) || !column(m.To) || m.From == m.To {
+			return false
+		}
+		p := g.tableau[m.From]
+		if m.Cards < 1 || m.Cards > len(p.Cards)-p.FaceDown {
+			return false
+		}
+		return g.toTableau(p.Cards[len(p.Cards)-m.Cards], m.To)
+	case FoundationToTableau:
+		if m.From < 0 || m.From > 3 || !column(m.To) {
+			return false
+		}
+		f := g.foundations[m.From]
+		return len(f) > 0 && g.toTableau(f[len(f)-1], m.To)
+	}
+	return false
+}
+
+func column(i int) bool {
+	return i >= 0 && i < 7
+}
+
+// Move plays a move. With AutoPlay, safe cards then go to the foundations.
+func (g *Klondike) Move(m Move) error {
+	if !g.legal(m) {
+		return fmt.Errorf("Illegal move %s", m)
+	}
+	g.play(m)
+	if g.autoPlay {
+		g.AutoMove()
+	}
+	return nil
+}
+
+// play plays a legal move and records it, with what it takes to undo it:
+// the cards drawn, or 1 if a face down card was turned over
+func (g *Klondike) play(m Move) {
+	info := 0
+	switch m.Kind {
+	case Draw:
+		n := g.draw
+		if n > len(g.stock) {
+			n = len(g.stock)
+		}
+		g.waste = append(g.waste, g.stock[:n]...)
+		g.stock = g.stock[n:]
+		info = n
+	case Redeal:
+		g.stock, g.waste = g.waste, nil
+		g.redeals++
+	case WasteToFoundation:
+		c := g.waste[len(g.waste)-1]
+		g.waste = g.waste[:len(g.waste)-1]
+		g.foundations[m.To] = append(g.foundations[m.To], c)
+	case WasteToTableau:
+		c := g.waste[len(g.waste)-1]
+		g.waste = g.waste[:len(g.waste)-1]
+		g.tableau[m.To].Cards = append(g.tableau[m.To].Cards, c)
+	case TableauToFoundation:
+		p := &g.tableau[m.From]
+		g.foundations[m.To] = append(g.foundations[m.To], p.Cards[len(p.Cards)-1])
+		p.Cards = p.Cards[:len(p.Cards)-1]
+		if p.Turn() {
+			info = 1
+		}
+	case TableauToTableau:
+		p := &g.tableau[m.From]
+		at := len(p.Cards) - m.Cards
+		g.tableau[m.To].Cards = append(g.tableau[m.To].Cards, p.Cards[at:]...)
+		p.Cards = p.Cards[:at]
+		if p.Turn() {
+			info = 1
+		}
+	case FoundationToTableau:
+		f := g.foundations[m.From]
+		g.tableau[m.To].Cards = append(g.tableau[m.To].Cards, f[len(f)-1])
+		g.foundations[m.From] = f[:len(f)-1]
+	}
+	g.moves = append(g.moves, m)
+	g.undo = append(g.undo, info)
+	g.debugf("%s\n", m)
+}
+
+// Undo takes back the last move, automatic moves one at a time
+func (g *Klondike) Undo() error {
+	if len(g.moves) == 0 {
+		return errors.New("Nothing to undo")
+	}
+	g.takeBack()
+	return nil
+}
+
+func (g *Klondike) takeBack() {
+	last := len(g.moves) - 1
+	m, info := g.moves[last], g.undo[last]
+	g.moves, g.undo = g.moves[:last], g.undo[:last]
+	switch m.Kind {
+	case Draw:
+		at := len(g.waste) - info
+		g.stock = append(append([]deck.Card{}, g.waste[at:]...), g.stock...)
+		g.waste = g.waste[:at]
+	case Redeal:
+		g.stock, g.waste = nil, g.stock
+		g.redeals--
+	case WasteToFoundation:
+		f := g.foundations[m.To]
+		g.waste = append(g.waste, f[len(f)-1])
+		g.foundations[m.To] = f[:len(f)-1]
+	case WasteToTableau:
+		p := &g.tableau[m.To]
+		g.waste = append(g.waste, p.Cards[len(p.Cards)-1])
+		p.Cards = p.Cards[:len(p.Cards)-1]
+	case TableauToFoundation:
+		f := g.foundations[m.To]
+		p := &g.tableau[m.From]
+		p.FaceDown += info
+		p.Cards = append(p.Cards, f[len(f)-1])
+		g.foundations[m.To] = f[:len(f)-1]
+	case TableauToTableau:
+		to := &g.tableau[m.To]
+		p := &g.tableau[m.From]
+		p.FaceDown += info
+		at := len(to.Cards) - m.Cards
+		p.Cards = append(p.Cards, to.Cards[at:]...)
+		to.Cards = to.Cards[:at]
+	case FoundationToTableau:
+		p := &g.tableau[m.To]
+		g.foundations[m.From] = append(g.foundations[m.From], p.Cards[len(p.Cards)-1])
+		p.Cards = p.Cards[:len(p.Cards)-1]
+	}
+}
+
+// AutoMove plays every safe card to the foundations, from the waste and the tableau,
+// and returns the moves it played
+func (g *Klondike) AutoMove() []Move {
+	played := []Move{}
+	for m, ok := g.nextSafe(); ok; m, ok = g.nextSafe() {
+		g.play(m)
+		played = append(played, m)
+	}
+	return played
+}
+
+// nextSafe returns a move of a safe card to its foundation, from the waste first
+func (g *Klondike) nextSafe() (Move, bool) {
+	if c, ok := g.wasteTop(); ok && g.foundations.Safe(c) {
+		return Move{Kind: WasteToFoundation, To: c.Suit()}, true
+	}
+	for i := range g.tableau {
+		if c, ok := g.tableau[i].Top(); ok && g.foundations.Safe(c) {
+			return Move{Kind: TableauToFoundation, From: i, To: c.Suit()}, true
+		}
+	}
+	return Move{}, false
+}
@@ -0,0 +1,341 @@
+package klondike
+
+import (
+	"sort"
+	"time"
+
+	"adamclerk/deck"
+	"adamclerk/deck/games/internal/solitaire"
+)
+
+// Mode is what the solver may look at
+type Mode = solitaire.Mode
+
+// Constants for Mode
+const (
+	Thoughtful = solitaire.Thoughtful // every card is known, the face down ones included
+	Standard   = solitaire.Standard   // face down cards stay unknown until they are turned over
+)
+
+// Verdict is what the solver found
+type Verdict = solitaire.Verdict
+
+// Constants for Verdict
+const (
+	Solved     = solitaire.Solved     // the moves win the game
+	Unwinnable = solitaire.Unwinnable // every position was searched: no sequence of moves wins
+	Lost       = solitaire.Lost       // in standard mode, the solver turned a card over and could not win from there
+	Undecided  = solitaire.Undecided  // the search ran out of states or time
+)
+
+// Solution is the solver's answer
+type Solution struct {
+	Verdict Verdict
+	Moves   []Move // the winning moves, when solved
+	States  int    // the positions searched
+}
+
+// SolveOptions how to configure the solver
+type SolveOptions = solitaire.SolveOptions
+
+// WithMode sets what the solver may look at
+func WithMode(mode Mode) func(*SolveOptions) {
+	return solitaire.WithMode(mode)
+}
+
+// MaxStates sets how many positions the solver searches before it gives up, a million by default
+func MaxStates(count int) func(*SolveOptions) {
+	return solitaire.MaxStates(count)
+}
+
+// Timeout sets how long the solver searches before it gives up; there's no limit by default
+func Timeout(d time.Duration) func(*SolveOptions) {
+	return solitaire.Timeout(d)
+}
+
+// Solve searches for moves that win the game from the current position, which it leaves as it is.
+//
+// The search is depth first, with a transposition table so that no position is searched twice.
+// It plays safe cards to the foundations after every move, as AutoPlay does, and goes through the stock
+// only to play a card from it. In thoughtful mode it knows every card, so a deal it can't win, searching
+// every position within its limits, is proved unwinnable, taking for granted that safe cards go up. In standard mode it plays as a player would:
+// it may look ahead and back out of moves until one turns a face down card over, and then it has
+// to go on from there. The stock is known in both modes, since it can be looked through.
+//
+// The moves include the safe moves to the foundations, unless the game plays them with AutoPlay;
+// either way they replay the win with Move.
+func (g *Klondike) Solve(options ...func(*SolveOptions)) (*Solution, error) {
+	opt, err := solitaire.NewSolveOptions(options...)
+	if err != nil {
+		return nil, err
+	}
+	s := &solver{g: g.Clone()}
+	s.g.debug, s.g.autoPlay = false, false
+	start := len(s.g.moves)
+	s.search = solitaire.NewSearch(s, opt, start)
+
+	solution := &Solution{Verdict: s.search.Run(), States: s.search.States}
+	if solution.Verdict == Solved {
+		for i, m := range s.g.moves[start:] {
+			if !g.autoPlay || !s.search.Auto[start+i] {
+				solution.Moves = append(solution.Moves, m)
+			}
+		}
+	}
+	return solution, nil
+}
+
+// solver plays a game for the search
+type solver struct {
+	g      *Klondike
+	search *solitaire.Search
+}
+
+func (s *solver) Won() bool {
+	return s.g.Won()
+}
+
+func (s *solver) PlaySafe() bool {
+	m, ok := s.g.nextSafe()
+	if ok {
+		s.g.play(m)
+	}
+	return ok
+}
+
+func (s *solver) TakeBack() {
+	s.g.takeBack()
+}
+
+// Candidates turns the moves worth trying into tries for the search, telling it when one turns a card over
+func (s *solver) Candidates() []solitaire.Candidate {
+	tries := []solitaire.Candidate{}
+	for _, c := range s.candidates() {
+		c := c
+		tries = append(tries, func() (int, bool, bool) {
+			down := s.faceDown()
+			played, ok := s.try(c)
+			return played, ok, s.faceDown() < down
+		})
+	}
+	return tries
+}
+
+// candidate is a move worth trying, after turning the stock steps times to bring card to the top of the waste
+type candidate struct {
+	steps int
+	card  deck.Card
+	move  Move
+	score int
+}
+
+// try plays a candidate, with the safe moves after each move, and returns how many moves it played.
+// It fails when safe moves from the waste have moved the card out of reach.
+func (s *solver) try(c candidate) (int, bool) {
+	played := 0
+	for i := 0; i < c.steps; i++ {
+		if len(s.g.stock) > 0 {
+			played += s.play(Move{Kind: Draw})
+		} else if s.g.legal(Move{Kind: Redeal}) {
+			played += s.play(Move{Kind: Redeal})
+		} else {
+			return played, false
+		}
+	}
+	if c.steps > 0 {
+		if top, ok := s.g.wasteTop(); !ok || top != c.card || !s.g.legal(c.move) {
+			return played, false
+		}
+	}
+	return played + s.play(c.move), true
+}
+
+// play plays a move, then the safe moves, and returns how many moves it played
+func (s *solver) play(m Move) int {
+	return s.search.Play(func() { s.g.play(m) })
+}
+
+// faceDown counts the face down cards
+func (s *solver) faceDown() int {
+	n := 0
+	for _, p := range s.g.tableau {
+		n += p.FaceDown
+	}
+	return n
+}
+
+// Key writes the position. Drawing one card at a time without a redeal limit, any card of the stock
+// and the waste can be reached whatever the waste holds, so only their order counts.
+func (s *solver) Key(k *solitaire.Key) {
+	g := s.g
+	for _, p := range g.tableau {
+		k.Column(p.FaceDown, p.Cards)
+	}
+	for _, f := range g.foundations {
+		k.Write(byte(len(f)))
+	}
+	k.Cards(g.waste)
+	if g.draw != 1 || g.redealLimit != Unlimited {
+		k.Write(0xfe, byte(g.redeals))
+	}
+	k.Cards(g.stock)
+}
+
+// talon returns the cards of the waste and the stock that can be brought to the top of the waste,
+// with the fewest turns of the stock it takes. Without a redeal limit, going round once is enough.
+func (s *solver) talon() []candidate {
+	g := s.g
+	waste, stock, redeals := len(g.waste), len(g.stock), g.redeals
+	card := func(i int) deck.Card {
+		if i < len(g.waste) {
+			return g.waste[i]
+		}
+		return g.stock[i-len(g.waste)]
+	}
+	reached := make([]bool, waste+stock)
+	cards := []candidate{}
+	for steps, rounds := 0, 0; ; steps++ {
+		if waste > 0 && !reached[waste-1] {
+			reached[waste-1] = true
+			cards = append(cards, candidate{steps: steps, card: card(waste - 1)})
+		}
+		switch {
+		case stock > 0:
+			n := g.draw
+			if n > stock {
+				n = stock
+			}
+			waste, stock = waste+n, stock-n
+		case waste > 0 && (g.redealLimit == Unlimited && rounds == 0 || g.redealLimit != Unlimited && redeals < g.redealLimit):
+			waste, stock = 0, waste
+			redeals++
+			rounds++
+		default:
+			return cards
+		}
+	}
+}
+
+// candidates returns the moves worth trying, the most promising first: to the foundations, then the ones
+// that turn a card over, free a card for its foundation, make room for a card or empty a column for a king,
+// then cards from the stock. Safe moves to the foundations have been played already, and of several
+// empty columns only the first is tried.
+func (s *solver) candidates() []candidate {
+	g := s.g
+	moves := []candidate{}
+	add := func(m Move, score int) {
+		moves = append(moves, candidate{move: m, score: score})
+	}
+	firstEmpty := -1
+	for j, p := range g.tableau {
+		if len(p.Cards) == 0 {
+			firstEmpty = j
+			break
+		}
+	}
+	target := func(j int) bool {
+		return len(g.tableau[j].Cards) > 0 || j == firstEmpty
+	}
+
+	for i, p := range g.tableau {
+		if c, ok := p.Top(); ok && g.foundations.Accepts(c) {
+			add(Move{Kind: TableauToFoundation, From: i, To: c.Suit()}, 800+p.FaceDown)
+		}
+	}
+	// the cards that could move onto the tableau, to tell which moves make room for one
+	talon := s.talon()
+	var movable [52]bool
+	kings := false
+	for _, t := range talon {
+		movable[t.card] = true
+		kings = kings || deck.Face(t.card.Face()) == deck.KING
+	}
+	for _, p := range g.tableau {
+		for k := p.FaceDown; k < len(p.Cards); k++ {
+			movable[p.Cards[k]] = true
+		}
+		kings = kings || p.FaceDown > 0 && deck.Face(p.Cards[p.FaceDown].Face()) == deck.KING
+	}
+	for _, f := range g.foundations {
+		if len(f) > 0 {
+			movable[f[len(f)-1]] = true
+		}
+	}
+	takes := func(c deck.Card) bool {
+		f := c.Face() - 1
+		if f < 0 {
+			return false
+		}
+		for suit := 0; suit < 4; suit++ {
+			if n := deck.NewCard(deck.Face(f), deck.Suit(suit)); movable[n] && solitaire.BuildsOn(n, c) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for i, p := range g.tableau {
+		for k := p.FaceDown; k < len(p.Cards); k++ {
+			king := deck.Face(p.Cards[k].Face()) == deck.KING
+			score := 0
+			switch {
+			case k > p.FaceDown && g.foundations.Accepts(p.Cards[k-1]):
+				score = 600
+			case k > p.FaceDown && takes(p.Cards[k-1]):
+				score = 250
+			case k > p.FaceDown:
+				score = 50
+			case p.FaceDown > 0:
+				score = 700 + p.FaceDown
+			case !king && kings:
+				score = 300
+			case !king:
+				score = 60
+			default:
+				continue
+			}
+			for j := range g.tableau {
+				if j != i && target(j) && g.toTableau(p.Cards[k], j) {
+					add(Move{Kind: TableauToTableau, From: i, To: j, Cards: len(p.Cards) - k}, score)
+				}
+			}
+		}
+	}
+	for _, t := range talon {
+		// cards on top of the waste come first, then the ones the fewest turns away
+		penalty := t.steps
+		if penalty > 99 {
+			penalty = 99
+		}
+		if g.foundations.Accepts(t.card) {
+			t.move, t.score = Move{Kind: WasteToFoundation, To: t.card.Suit()}, 650-penalty
+			if t.steps == 0 {
+				t.score = 900
+			}
+			moves = append(moves, t)
+		}
+		for j := range g.tableau {
+			if target(j) && g.toTableau(t.card, j) {
+				t.move, t.score = Move{Kind: WasteToTableau, To: j}, 500-penalty
+				moves = append(moves, t)
+			}
+		}
+	}
+	for f, cards := range g.foundations {
+		if len(cards) == 0 {
+			continue
+		}
+		score := 40
+		if takes(cards[len(cards)-1]) {
+			score = 100
+		}
+		for j := range g.tableau {
+			if target(j) && g.toTableau(cards[len(cards)-1], j) {
+				add(Move{Kind: FoundationToTableau, From: f, To: j}, score)
+			}
+		}
+	}
+
+	sort.SliceStable(moves, func(i, j int) bool { return moves[i].score > moves[j].score })
+	return moves
+}
@@ -0,0 +1,87 @@
+package klondike
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"adamclerk/deck"
+	"github.com/stretchr/testify/assert"
+)
+
+// deal returns the options for a deal shuffled from a seed
+func deal(n int, options ...func(*Options)) []func(*Options) {
+	return append([]func(*Options){WithDeck(deck.FromSeed(fmt.Sprint("deal", n)))}, options...)
+}
+
+// replay plays the moves on a new game and tells you if they win it
+func replay(t *testing.T, options []func(*Options), moves []Move) bool {
+	g, _ := New(options...)
+	for _, m := range moves {
+		if !assert.Nil(t, g.Move(m)) {
+			return false
+		}
+	}
+	return g.Won()
+}
+
+func TestSolve(t *testing.T) {
+	tests := []struct {
+		options []func(*Options)
+		mode    Mode
+		verdict Verdict
+	}{
+		{deal(0), Thoughtful, Solved},
+		{deal(0, AutoPlay), Thoughtful, Solved},
+		{deal(6, DrawThree), Thoughtful, Solved},
+		{deal(2, DrawThree, Redeals(2), AutoPlay), Thoughtful, Solved},
+		{deal(16), Standard, Solved},
+		// the solver has to turn a card over before it knows it's the wrong one
+		{deal(0), Standard, Lost},
+	}
+	for i, test := range tests {
+		g, _ := New(test.options...)
+		before := g.String()
+		solution, err := g.Solve(WithMode(test.mode), MaxStates(50000))
+		if !assert.Nil(t, err, i) {
+			continue
+		}
+		assert.Equal(t, test.verdict, solution.Verdict, i)
+		assert.True(t, solution.States > 0, i)
+		assert.Equal(t, before, g.String(), i)
+		if solution.Verdict == Solved {
+			assert.True(t, replay(t, test.options, solution.Moves), i)
+		} else {
+			assert.Len(t, solution.Moves, 0, i)
+		}
+	}
+}
+
+func TestUnwinnable(t *testing.T) {
+	for _, options := range [][]func(*Options){deal(21, DrawThree), deal(21, Redeals(0))} {
+		g, _ := New(options...)
+		solution, err := g.Solve()
+		if assert.Nil(t, err) {
+			assert.Equal(t, Unwinnable, solution.Verdict)
+			assert.True(t, solution.States < 1000)
+			assert.Len(t, solution.Moves, 0)
+		}
+	}
+}
+
+func TestSolveLimits(t *testing.T) {
+	g, _ := New(deal(2)...)
+	solution, err := g.Solve(MaxStates(100))
+	if assert.Nil(t, err) {
+		assert.Equal(t, Undecided, solution.Verdict)
+		assert.Equal(t, 100, solution.States)
+	}
+	solution, err = g.Solve(Timeout(time.Nanosecond))
+	if assert.Nil(t, err) {
+		assert.Equal(t, Undecided, solution.Verdict)
+	}
+	_, err = g.Solve(WithMode(Mode(5)))
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "Unknown mode Mode(5)", err.Error())
+	}
+}
@@ -0,0 +1,234 @@
+// Package spider is Spider solitaire: two decks' worth of cards in ten columns, built down to runs of a suit.
+package spider
+
+import (
+	"fmt"
+	"strings"
+
+	"adamclerk/deck"
+	"adamclerk/deck/games/internal/solitaire"
+)
+
+// Spider is a game of Spider solitaire.
+// Rules can be found here: https://bicyclecards.com/how-to-play/spider-solitaire/
+type Spider struct {
+	debug     bool
+	suits     int
+	signature string
+	tableau   [10]Pile
+	stock     []deck.Card
+	completed []deck.Suit
+	moves     []Move
+	undo      []int
+	penalty   int
+}
+
+// Pile is a column of the tableau. The first FaceDown cards are face down, the last card is on top.
+type Pile = solitaire.Pile
+
+// Options how to configure a game of Spider
+type Options struct {
+	DeckOptions []func(*deck.Options)
+	Suits       int
+	Debug       bool
+}
+
+// WithDeck allows the a game to be configured with a specific deck, from the composition of the suits chosen
+func WithDeck(deckOptions ...func(*deck.Options)) func(*Options) {
+	return func(o *Options) {
+		o.DeckOptions = deckOptions
+	}
+}
+
+// WithSignature deals the deal a deck signature describes, as returned by Signature, to replay or share it
+func WithSignature(signature string) func(*Options) {
+	return WithDeck(deck.FromSignature(signature), deck.Unshuffled)
+}
+
+// OneSuit plays with eight sets of spades, the easiest game
+func OneSuit(o *Options) {
+	o.Suits = 1
+}
+
+// TwoSuits plays with four sets each of hearts and spades
+func TwoSuits(o *Options) {
+	o.Suits = 2
+}
+
+// FourSuits plays with two full decks, the hardest game and the default
+func FourSuits(o *Options) {
+	o.Suits = 4
+}
+
+// Debug sets the debug param for the game
+func Debug(o *Options) {
+	o.Debug = true
+}
+
+// Composition returns the deck options for the 104 cards of a game with 1, 2 or 4 suits
+func Composition(suits int) ([]func(*deck.Options), error) {
+	switch suits {
+	case 1:
+		return []func(*deck.Options){deck.Suits(deck.SPADE), deck.Decks(8)}, nil
+	case 2:
+		return []func(*deck.Options){deck.Suits(deck.HEART, deck.SPADE), deck.Decks(4)}, nil
+	case 4:
+		return []func(*deck.Options){deck.Decks(2)}, nil
+	}
+	return nil, fmt.Errorf("Spider is played with 1, 2 or 4 suits, not %d", suits)
+}
+
+// New function creates a new game of Spider with four suits, unless configured otherwise
+func New(options ...func(*Options)) (*Spider, error) {
+	opt := Options{DeckOptions: []func(*deck.Options){}, Suits: 4}
+	for _, option := range options {
+		option(&opt)
+	}
+	deckOptions, err := Composition(opt.Suits)
+	if err != nil {
+		return nil, err
+	}
+	d, err := deck.New(append(deckOptions, opt.DeckOptions...)...)
+	if err != nil {
+		return nil, err
+	}
+	if err := fullDecks(d.Cards, opt.Suits); err != nil {
+		return nil, err
+	}
+	g := &Spider{
+		debug:     opt.Debug,
+		suits:     opt.Suits,
+		signature: d.GetSignature(),
+	}
+	g.deal(d.Cards)
+	g.debugf("%s\n", g)
+	return g, nil
+}
+
+// fullDecks checks there are the 104 cards of the composition, the same number of each
+func fullDecks(cards []deck.Card, suits int) error {
+	if len(cards) != 104 {
+		return fmt.Errorf("Spider needs 104 cards, not %d", len(cards))
+	}
+	count := map[deck.Card]int{}
+	for _, c := range cards {
+		if c < 0 || c >= 52 {
+			return fmt.Errorf("Unknown card %d", int(c))
+		}
+		count[c]++
+	}
+	if len(count) != 13*suits {
+		return fmt.Errorf("%d-suit Spider needs %d different cards, not %d", suits, 13*suits, len(count))
+	}
+	for _, c := range cards {
+		if count[c] != 8/suits {
+			return fmt.Errorf("%d-suit Spider needs %d of each card, %s is there %d times", suits, 8/suits, c, count[c])
+		}
+	}
+	return nil
+}
+
+// deal lays the columns out row by row, six cards in the first four and five in the others,
+// the last card of each face up; the rest is the stock
+func (g *Spider) deal(cards []deck.Card) {
+	n := 0
+	for row := 0; row < 6; row++ {
+		for col := range g.tableau {
+			if row == 5 && col >= 4 {
+				break
+			}
+			g.tableau[col].Cards = append(g.tableau[col].Cards, cards[n])
+			n++
+		}
+	}
+	for col := range g.tableau {
+		g.tableau[col].FaceDown = len(g.tableau[col].Cards) - 1
+	}
+	g.stock = append([]deck.Card{}, cards[n:]...)
+}
+
+func (g *Spider) debugf(format string, a ...interface{}) {
+	deck.Debugf(g.debug, format, a...)
+}
+
+// Signature returns the signature of the deck the game was dealt from
+func (g *Spider) Signature() string {
+	return g.signature
+}
+
+// Suits returns how many suits the game is played with
+func (g *Spider) Suits() int {
+	return g.suits
+}
+
+// Tableau returns a column, 0 to 9
+func (g *Spider) Tableau(column int) Pile {
+	return g.tableau[column]
+}
+
+// Run returns how many cards at the top of a column are a run: face up, one suit, in rank order down.
+// A run moves as one.
+func (g *Spider) Run(column int) int {
+	p := g.tableau[column]
+	if len(p.Cards) == 0 {
+		return 0
+	}
+	n := 1
+	for n < len(p.Cards)-p.FaceDown && follows(p.Cards[len(p.Cards)-n], p.Cards[len(p.Cards)-n-1]) {
+		n++
+	}
+	return n
+}
+
+// Stock returns the cards left to deal, the next row first
+func (g *Spider) Stock() []deck.Card {
+	return g.stock
+}
+
+// Completed returns the suits of the runs from king to ace taken off the tableau, in order
+func (g *Spider) Completed() []deck.Suit {
+	return g.completed
+}
+
+// Moves returns the moves played so far, the automatic removals of completed runs included
+func (g *Spider) Moves() []Move {
+	return g.moves
+}
+
+// Score returns the score: 500 to start with, less a point for every move and every undo,
+// and 100 more for every completed run
+func (g *Spider) Score() int {
+	return 500 - g.penalty + 100*len(g.completed)
+}
+
+// Won tells you if the eight runs are completed
+func (g *Spider) Won() bool {
+	return len(g.completed) == 8
+}
+
+// Clone returns a copy of the game that can be played on its own
+func (g *Spider) Clone() *Spider {
+	c := *g
+	for i, p := range g.tableau {
+		c.tableau[i] = p.Clone()
+	}
+	c.stock = append([]deck.Card{}, g.stock...)
+	c.completed = append([]deck.Suit{}, g.completed...)
+	c.moves = append([]Move{}, g.moves...)
+	c.undo = append([]int{}, g.undo...)
+	return &c
+}
+
+// String draws the game: the stock, the completed runs and the score, then the columns with face down cards as ##
+func (g *Spider) String() string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "Stock: %d  Completed:", len(g.stock))
+	for _, s := range g.completed {
+		fmt.Fprintf(b, " %s", deck.NewCard(deck.KING, s))
+	}
+	fmt.Fprintf(b, "  Score: %d\n", g.Score())
+	for i, p := range g.tableau {
+		fmt.Fprintf(b, "T%d:%s\n", i+1, p)
+	}
+	return b.String()
+}
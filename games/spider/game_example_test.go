@@ -0,0 +1,70 @@
+package spider
+
+import (
+	"fmt"
+
+	"adamclerk/deck"
+)
+
+// This example deals a two suit game from a seeded deck, plays the first legal move and deals a row
+func Example() {
+	game, err := New(TwoSuits, WithDeck(deck.FromSeed("spider")))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(game.LegalMoves())
+	for _, m := range []Move{game.LegalMoves()[0], {Kind: Deal}} {
+		if err := game.Move(m); err != nil {
+			panic(err)
+		}
+	}
+	fmt.Print(game)
+	// Output:
+	// [T1-T2 T1-T4 T1-T8 T6-T1 T6-T7 T7-T2 T7-T4 T7-T8 T10-T6 Deal]
+	// Stock: 40  Completed:  Score: 498
+	// T1: ## ## ## ## K♥ 6♠
+	// T2: ## ## ## ## ## 4♥ 3♠ 4♠
+	// T3: ## ## ## ## ## Q♠ 8♥
+	// T4: ## ## ## ## ## 4♥ K♠
+	// T5: ## ## ## ## 6♥ J♠
+	// T6: ## ## ## ## 2♥ 3♥
+	// T7: ## ## ## ## 3♥ 5♠
+	// T8: ## ## ## ## 4♥ J♠
+	// T9: ## ## ## ## Q♠ 6♠
+	// T10: ## ## ## ## A♠ T♥
+}
+
+// This example tells how many cards of each column move as one, after a few moves
+func ExampleSpider_Run() {
+	game, _ := New(OneSuit, WithDeck(deck.FromSeed("spider")))
+	for i := 0; i < 6; i++ {
+		game.Move(game.LegalMoves()[0])
+	}
+	for column := 0; column < 10; column++ {
+		fmt.Println(game.Tableau(column).FaceUp(), game.Run(column))
+	}
+	// Output:
+	// [K♠ Q♠] 2
+	// [Q♠] 1
+	// [8♠] 1
+	// [4♠ 3♠] 2
+	// [6♠ 5♠ 4♠] 3
+	// [2♠ A♠] 2
+	// [3♠] 1
+	// [4♠] 1
+	// [Q♠] 1
+	// [A♠] 1
+}
+
+// This example lays out the cards of each difficulty
+func ExampleComposition() {
+	for _, suits := range []int{1, 2, 4} {
+		options, _ := Composition(suits)
+		cards, _ := deck.New(append(options, deck.Unshuffled)...)
+		fmt.Println(suits, len(cards.Cards), cards.Cards[:3], cards.Cards[12:15])
+	}
+	// Output:
+	// 1 104 [A♠ 2♠ 3♠] [K♠ A♠ 2♠]
+	// 2 104 [A♥ 2♥ 3♥] [K♥ A♠ 2♠]
+	// 4 104 [A♣ 2♣ 3♣] [K♣ A♦ 2♦]
+}
@@ -0,0 +1,299 @@
+package spider
+
+import (
+	"math/rand"
+	"testing"
+
+	"adamclerk/deck"
+	"adamclerk/deck/internal/decktest"
+	"github.com/stretchr/testify/assert"
+)
+
+// empty returns a game with nothing dealt, to lay a position out by hand
+func empty(options ...func(*Options)) *Spider {
+	g, _ := New(options...)
+	g.tableau = [10]Pile{}
+	g.stock = nil
+	return g
+}
+
+// run returns the cards of a suit from one face down to another
+func run(suit deck.Suit, from, to deck.Face) []deck.Card {
+	cards := []deck.Card{}
+	for f := from; f >= to; f-- {
+		cards = append(cards, deck.NewCard(f, suit))
+	}
+	return cards
+}
+
+func TestNew(t *testing.T) {
+	uneven := decktest.Cards("Ks")
+	for i := 0; i < 8; i++ {
+		uneven = append(uneven, run(deck.SPADE, deck.KING, deck.ACE)...)
+	}
+	uneven = uneven[:104]
+
+	g, err := New(WithDeck(deck.Unshuffled))
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, 4, g.Suits())
+	assert.Equal(t, Pile{Cards: decktest.Cards("Ac", "Jc", "8d", "5h", "2s", "Qs"), FaceDown: 5}, g.Tableau(0))
+	assert.Equal(t, Pile{Cards: decktest.Cards("5c", "2d", "Qd", "9h", "6s"), FaceDown: 4}, g.Tableau(4))
+	assert.Len(t, g.Stock(), 50)
+	assert.Equal(t, 500, g.Score())
+	assert.False(t, g.Won())
+
+	// a deal replays from its signature
+	shuffled, _ := New(TwoSuits)
+	replay, err := New(WithSignature(shuffled.Signature()), TwoSuits)
+	if assert.Nil(t, err) {
+		assert.Equal(t, shuffled.String(), replay.String())
+		assert.Equal(t, shuffled.Stock(), replay.Stock())
+	}
+
+	tests := []struct {
+		options []func(*Options)
+		err     string
+	}{
+		{[]func(*Options){func(o *Options) { o.Suits = 3 }}, "Spider is played with 1, 2 or 4 suits, not 3"},
+		{[]func(*Options){WithDeck(deck.Decks(1))}, "Spider needs 104 cards, not 52"},
+		{[]func(*Options){TwoSuits, WithDeck(deck.Decks(2), deck.Suits(deck.SUITS...))}, "2-suit Spider needs 26 different cards, not 52"},
+		{[]func(*Options){OneSuit, WithDeck(deck.Unshuffled, deck.WithCards(uneven...))}, "1-suit Spider needs 8 of each card, K♠ is there 9 times"},
+	}
+	for _, test := range tests {
+		_, err := New(test.options...)
+		if assert.NotNil(t, err, test.err) {
+			assert.Equal(t, test.err, err.Error())
+		}
+	}
+}
+
+func TestComposition(t *testing.T) {
+	tests := []struct {
+		options []func(*Options)
+		suits   []deck.Suit
+	}{
+		{[]func(*Options){OneSuit}, []deck.Suit{deck.SPADE}},
+		{[]func(*Options){TwoSuits}, []deck.Suit{deck.HEART, deck.SPADE}},
+		{[]func(*Options){FourSuits}, deck.SUITS},
+		{nil, deck.SUITS},
+	}
+	for _, test := range tests {
+		g, err := New(test.options...)
+		if !assert.Nil(t, err) {
+			continue
+		}
+		count := map[deck.Card]int{}
+		cards := g.Stock()
+		for _, p := range g.tableau {
+			cards = append(cards, p.Cards...)
+		}
+		for _, c := range cards {
+			count[c]++
+		}
+		assert.Len(t, cards, 104)
+		assert.Len(t, count, 13*len(test.suits))
+		for _, s := range test.suits {
+			assert.Equal(t, 8/len(test.suits), count[deck.NewCard(deck.QUEEN, s)])
+		}
+		assert.Equal(t, len(test.suits), g.Suits())
+	}
+	_, err := Composition(5)
+	assert.NotNil(t, err)
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		pile Pile
+		run  int
+	}{
+		{Pile{}, 0},
+		{Pile{Cards: decktest.Cards("9s")}, 1},
+		{Pile{Cards: decktest.Cards("9s", "8s", "7s")}, 3},
+		{Pile{Cards: decktest.Cards("9s", "8h", "7h")}, 2},
+		{Pile{Cards: decktest.Cards("9s", "8s", "7s"), FaceDown: 1}, 2},
+		{Pile{Cards: decktest.Cards("9s", "8s", "6s")}, 1},
+		{Pile{Cards: run(deck.CLUB, deck.KING, deck.ACE)}, 13},
+	}
+	g := empty()
+	for i, test := range tests {
+		g.tableau[0] = test.pile
+		assert.Equal(t, test.run, g.Run(0), i)
+	}
+}
+
+func TestLegalMoves(t *testing.T) {
+	g := empty()
+	g.tableau[0] = Pile{Cards: decktest.Cards("Kd", "9s", "8s", "7h"), FaceDown: 1}
+	g.tableau[1] = Pile{Cards: decktest.Cards("9h")}
+	g.tableau[2] = Pile{Cards: decktest.Cards("Qc", "8c"), FaceDown: 1}
+	g.tableau[3] = Pile{Cards: decktest.Cards("3h", "2h"), FaceDown: 0}
+	for col := 4; col < 9; col++ {
+		g.tableau[col] = Pile{Cards: decktest.Cards("Kc")}
+	}
+	g.stock = decktest.Cards("2c", "3c", "4c", "5c", "6c", "7c", "8c", "9c", "Tc", "Jc")
+
+	moves := []string{}
+	for _, m := range g.LegalMoves() {
+		moves = append(moves, m.String())
+	}
+	// the 8♠ can't take the 7♥ with it, the run stops at a change of suit
+	assert.Equal(t, []string{"T1-T3", "T1-T10", "T3-T2", "T3-T10", "T4-T10"}, moves)
+
+	// the face down card turns over
+	assert.Nil(t, g.Move(Move{Kind: TableauToTableau, From: 2, To: 1, Cards: 1}))
+	assert.Equal(t, Pile{Cards: decktest.Cards("Qc")}, g.Tableau(2))
+	assert.Equal(t, decktest.Cards("9h", "8c"), g.Tableau(1).Cards)
+	assert.Equal(t, 499, g.Score())
+
+	illegal := []Move{
+		{Kind: Deal},
+		{Kind: TableauToTableau, From: 0, To: 1, Cards: 2},
+		{Kind: TableauToTableau, From: 3, To: 3, Cards: 1},
+		{Kind: TableauToTableau, From: 3, To: 4, Cards: 1},
+		{Kind: TableauToTableau, From: 10, To: 0, Cards: 1},
+		{Kind: Complete, From: 3, To: 2},
+		{Kind: MoveKind(5)},
+	}
+	for _, m := range illegal {
+		err := g.Move(m)
+		if assert.NotNil(t, err, m.String()) {
+			assert.Equal(t, "Illegal move "+m.String(), err.Error())
+		}
+	}
+	assert.Len(t, g.Moves(), 1)
+	assert.Equal(t, 499, g.Score())
+}
+
+func TestDeal(t *testing.T) {
+	g, _ := New(WithDeck(deck.Unshuffled))
+	next := g.Stock()[:10]
+	assert.Nil(t, g.Move(Move{Kind: Deal}))
+	assert.Len(t, g.Stock(), 40)
+	for col := 0; col < 10; col++ {
+		p := g.Tableau(col)
+		assert.Equal(t, next[col], p.Cards[len(p.Cards)-1])
+		assert.Len(t, p.FaceUp(), 2)
+	}
+	assert.Nil(t, g.Undo())
+	assert.Len(t, g.Stock(), 50)
+	assert.Equal(t, next, g.Stock()[:10])
+	assert.Equal(t, 498, g.Score())
+
+	// no row is dealt over an empty column
+	g = empty()
+	for col := 1; col < 10; col++ {
+		g.tableau[col] = Pile{Cards: decktest.Cards("Kc")}
+	}
+	g.stock = run(deck.HEART, deck.TEN, deck.ACE)
+	assert.NotNil(t, g.Move(Move{Kind: Deal}))
+	g.tableau[0] = Pile{Cards: decktest.Cards("Kd")}
+	assert.Nil(t, g.Move(Move{Kind: Deal}))
+	assert.Len(t, g.Stock(), 0)
+}
+
+func TestComplete(t *testing.T) {
+	g := empty()
+	g.tableau[0] = Pile{Cards: append(decktest.Cards("5d", "Jc"), run(deck.SPADE, deck.KING, deck.SIX)...), FaceDown: 2}
+	g.tableau[1] = Pile{Cards: append(decktest.Cards("9c"), run(deck.SPADE, deck.FIVE, deck.ACE)...), FaceDown: 1}
+	g.tableau[2] = Pile{Cards: append(decktest.Cards("Qd"), run(deck.HEART, deck.KING, deck.TWO)...)}
+	for col := 3; col < 10; col++ {
+		g.tableau[col] = Pile{Cards: decktest.Cards("Kc")}
+	}
+	g.stock = decktest.Cards("2c", "3c", "Ah", "4c", "5c", "6c", "7c", "8c", "9c", "Tc")
+
+	// the run goes up and the J♣ turns over
+	assert.Nil(t, g.Move(Move{Kind: TableauToTableau, From: 1, To: 0, Cards: 5}))
+	assert.Equal(t, []deck.Suit{deck.SPADE}, g.Completed())
+	assert.Equal(t, Pile{Cards: decktest.Cards("5d", "Jc"), FaceDown: 1}, g.Tableau(0))
+	assert.Equal(t, Pile{Cards: decktest.Cards("9c")}, g.Tableau(1))
+	assert.Equal(t, []Move{{Kind: TableauToTableau, From: 1, To: 0, Cards: 5}, {Kind: Complete, From: 0, To: int(deck.SPADE)}}, g.Moves())
+	assert.Equal(t, "T1-♠", g.Moves()[1].String())
+	assert.Equal(t, 599, g.Score())
+
+	// a dealt card can complete a run too
+	assert.Nil(t, g.Move(Move{Kind: Deal}))
+	assert.Equal(t, []deck.Suit{deck.SPADE, deck.HEART}, g.Completed())
+	assert.Equal(t, Pile{Cards: decktest.Cards("Qd")}, g.Tableau(2))
+	assert.Equal(t, 698, g.Score())
+
+	// a move is taken back with the runs it completed
+	assert.Nil(t, g.Undo())
+	assert.Equal(t, []deck.Suit{deck.SPADE}, g.Completed())
+	assert.Equal(t, Pile{Cards: append(decktest.Cards("Qd"), run(deck.HEART, deck.KING, deck.TWO)...)}, g.Tableau(2))
+	assert.Len(t, g.Stock(), 10)
+	assert.Len(t, g.Moves(), 2)
+	assert.Nil(t, g.Undo())
+	assert.Len(t, g.Completed(), 0)
+	assert.Len(t, g.Moves(), 0)
+	assert.Equal(t, Pile{Cards: append(decktest.Cards("5d", "Jc"), run(deck.SPADE, deck.KING, deck.SIX)...), FaceDown: 2}, g.Tableau(0))
+	assert.Equal(t, 496, g.Score())
+}
+
+func TestWon(t *testing.T) {
+	g := empty(OneSuit)
+	for col := 0; col < 8; col++ {
+		g.tableau[col] = Pile{Cards: run(deck.SPADE, deck.KING, deck.TWO)}
+	}
+	g.tableau[8] = Pile{Cards: decktest.Cards("As", "As", "As", "As")}
+	g.tableau[9] = Pile{Cards: decktest.Cards("As", "As", "As", "As")}
+	for from := 8; from < 10; from++ {
+		for to := 4 * (from - 8); to < 4*(from-7); to++ {
+			assert.Nil(t, g.Move(Move{Kind: TableauToTableau, From: from, To: to, Cards: 1}))
+		}
+	}
+	assert.True(t, g.Won())
+	assert.Equal(t, 500-8+800, g.Score())
+}
+
+func TestUndo(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	for _, options := range [][]func(*Options){{WithDeck(deck.FromSeed("spider"))}, {OneSuit, WithDeck(deck.FromSeed("spider"))}} {
+		g, _ := New(options...)
+		start := g.String()
+		for i := 0; i < 300; i++ {
+			moves := g.LegalMoves()
+			if len(moves) == 0 {
+				break
+			}
+			// a move and the runs it completes are taken back at once
+			before, played := g.Clone(), len(g.Moves())
+			assert.Nil(t, g.Move(moves[0]))
+			assert.Nil(t, g.Undo())
+			assert.Len(t, g.Moves(), played)
+			assert.Equal(t, before.tableau, g.tableau)
+			assert.Equal(t, before.Stock(), g.Stock())
+			assert.Nil(t, g.Move(moves[r.Intn(len(moves))]))
+			assert.Len(t, append(g.Stock(), cards(g)...), 104-13*len(g.Completed()))
+		}
+		for len(g.Moves()) > 0 {
+			assert.Nil(t, g.Undo())
+		}
+		g.penalty = 0
+		assert.Equal(t, start, g.String())
+	}
+	g, _ := New()
+	err := g.Undo()
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "Nothing to undo", err.Error())
+	}
+}
+
+// cards returns the cards of the tableau
+func cards(g *Spider) []deck.Card {
+	all := []deck.Card{}
+	for _, p := range g.tableau {
+		all = append(all, p.Cards...)
+	}
+	return all
+}
+
+func TestClone(t *testing.T) {
+	g, _ := New()
+	c := g.Clone()
+	c.Move(Move{Kind: Deal})
+	assert.Len(t, g.Stock(), 50)
+	assert.Len(t, c.Stock(), 40)
+	assert.Len(t, g.Tableau(0).Cards, 6)
+}
@@ -0,0 +1,218 @@
+package spider
+
+import (
+	"errors"
+	"fmt"
+
+	"adamclerk/deck"
+	"adamclerk/deck/games/internal/solitaire"
+)
+
+// MoveKind is what a move does
+type MoveKind int
+
+// Constants for MoveKind
+const (
+	TableauToTableau MoveKind = iota // moves face up cards from one column to another
+	Deal                             // deals a row from the stock, a card on each column
+	Complete                         // takes a run from king to ace off a column, which happens by itself
+)
+
+var moveKinds = []string{"TableauToTableau", "Deal", "Complete"}
+
+func (k MoveKind) String() string {
+	if k < 0 || int(k) >= len(moveKinds) {
+		return fmt.Sprintf("MoveKind(%d)", int(k))
+	}
+	return moveKinds[k]
+}
+
+// Move is a move in a game. From and To are columns, 0 to 9, or the suit of a completed run;
+// Cards is how many cards a tableau to tableau move takes.
+type Move struct {
+	Kind  MoveKind
+	From  int
+	To    int
+	Cards int
+}
+
+// String writes the move short, with columns counted from 1: T1, T2... for the tableau,
+// and the suit of a completed run
+func (m Move) String() string {
+	switch m.Kind {
+	case TableauToTableau:
+		if m.Cards > 1 {
+			return fmt.Sprintf("T%d-T%d x%d", m.From+1, m.To+1, m.Cards)
+		}
+		return fmt.Sprintf("T%d-T%d", m.From+1, m.To+1)
+	case Deal:
+		return "Deal"
+	case Complete:
+		return fmt.Sprintf("T%d-%s", m.From+1, solitaire.SuitName(m.To))
+	}
+	return m.Kind.String()
+}
+
+// buildsOn tells you if the card can go on the other in the tableau: one rank lower, whatever the suit
+func buildsOn(c, on deck.Card) bool {
+	return c.Face()+1 == on.Face()
+}
+
+// follows tells you if the card carries a run on from the other: one rank lower and the same suit
+func follows(c, on deck.Card) bool {
+	return buildsOn(c, on) && c.Suit() == on.Suit()
+}
+
+// LegalMoves returns every move that can be played now, the deal last.
+// Moving a whole column to an empty one is left out, since it changes nothing.
+func (g *Spider) LegalMoves() []Move {
+	moves := []Move{}
+	for i, p := range g.tableau {
+		for n := 1; n <= g.Run(i); n++ {
+			for j := range g.tableau {
+				if j != i && g.toTableau(p.Cards[len(p.Cards)-n], j) && !(n == len(p.Cards) && len(g.tableau[j].Cards) == 0) {
+					moves = append(moves, Move{Kind: TableauToTableau, From: i, To: j, Cards: n})
+				}
+			}
+		}
+	}
+	if g.canDeal() {
+		moves = append(moves, Move{Kind: Deal})
+	}
+	return moves
+}
+
+// toTableau tells you if the card can go on the column: any card on an empty one, or a build
+func (g *Spider) toTableau(c deck.Card, column int) bool {
+	top, ok := g.tableau[column].Top()
+	return !ok || buildsOn(c, top)
+}
+
+// canDeal tells you if a row can be dealt: there are cards left, and no column is empty
+func (g *Spider) canDeal() bool {
+	if len(g.stock) < len(g.tableau) {
+		return false
+	}
+	for _, p := range g.tableau {
+		if len(p.Cards) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// legal tells you if the move can be played now. Runs are completed by themselves, so a Complete move never is.
+func (g *Spider) legal(m Move) bool {
+	switch m.Kind {
+	case TableauToTableau:
+		if !column(m.From) || !column(m.To) || m.From == m.To {
+			return false
+		}
+		p := g.tableau[m.From]
+		if m.Cards < 1 || m.Cards > g.Run(m.From) {
+			return false
+		}
+		return g.toTableau(p.Cards[len(p.Cards)-m.Cards], m.To)
+	case Deal:
+		return g.canDeal()
+	}
+	return false
+}
+
+func column(i int) bool {
+	return i >= 0 && i < 10
+}
+
+// Move plays a move, then takes off the runs it completes
+func (g *Spider) Move(m Move) error {
+	if !g.legal(m) {
+		return fmt.Errorf("Illegal move %s", m)
+	}
+	g.penalty++
+	g.play(m)
+	g.complete()
+	return nil
+}
+
+// complete takes every run from king to ace off the tableau
+func (g *Spider) complete() {
+	for i, p := range g.tableau {
+		if g.Run(i) == 13 {
+			top, _ := p.Top()
+			g.play(Move{Kind: Complete, From: i, To: top.Suit()})
+		}
+	}
+}
+
+// play plays a legal move and records it, with what it takes to undo it: 1 if a face down card was turned over
+func (g *Spider) play(m Move) {
+	info := 0
+	switch m.Kind {
+	case TableauToTableau:
+		p := &g.tableau[m.From]
+		at := len(p.Cards) - m.Cards
+		g.tableau[m.To].Cards = append(g.tableau[m.To].Cards, p.Cards[at:]...)
+		p.Cards = p.Cards[:at]
+		if p.Turn() {
+			info = 1
+		}
+	case Deal:
+		for i := range g.tableau {
+			g.tableau[i].Cards = append(g.tableau[i].Cards, g.stock[i])
+		}
+		g.stock = g.stock[len(g.tableau):]
+	case Complete:
+		p := &g.tableau[m.From]
+		p.Cards = p.Cards[:len(p.Cards)-13]
+		g.completed = append(g.completed, deck.Suit(m.To))
+		if p.Turn() {
+			info = 1
+		}
+	}
+	g.moves = append(g.moves, m)
+	g.undo = append(g.undo, info)
+	g.debugf("%s\n", m)
+}
+
+// Undo takes back the last move, with the runs it completed. It costs a point like a move.
+func (g *Spider) Undo() error {
+	if len(g.moves) == 0 {
+		return errors.New("Nothing to undo")
+	}
+	g.penalty++
+	for g.moves[len(g.moves)-1].Kind == Complete {
+		g.takeBack()
+	}
+	g.takeBack()
+	return nil
+}
+
+func (g *Spider) takeBack() {
+	last := len(g.moves) - 1
+	m, info := g.moves[last], g.undo[last]
+	g.moves, g.undo = g.moves[:last], g.undo[:last]
+	switch m.Kind {
+	case TableauToTableau:
+		to := &g.tableau[m.To]
+		p := &g.tableau[m.From]
+		p.FaceDown += info
+		at := len(to.Cards) - m.Cards
+		p.Cards = append(p.Cards, to.Cards[at:]...)
+		to.Cards = to.Cards[:at]
+	case Deal:
+		row := make([]deck.Card, len(g.tableau))
+		for i := range g.tableau {
+			p := &g.tableau[i]
+			row[i] = p.Cards[len(p.Cards)-1]
+			p.Cards = p.Cards[:len(p.Cards)-1]
+		}
+		g.stock = append(row, g.stock...)
+	case Complete:
+		p := &g.tableau[m.From]
+		p.FaceDown += info
+		for f := deck.KING; f >= deck.ACE; f-- {
+			p.Cards = append(p.Cards, deck.NewCard(f, deck.Suit(m.To)))
+		}
+		g.completed = g.completed[:len(g.completed)-1]
+	}
+}
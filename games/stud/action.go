@@ -0,0 +1,111 @@
+package stud
+
+import (
+	"fmt"
+
+	"adamclerk/deck"
+)
+
+// Street is a betting round of a hand, named after the number of cards each player holds
+type Street int
+
+// Constants for Street
+const (
+	ThirdStreet Street = iota
+	FourthStreet
+	FifthStreet
+	SixthStreet
+	SeventhStreet
+	Showdown
+)
+
+func (s Street) String() string {
+	names := []string{"Third Street", "Fourth Street", "Fifth Street", "Sixth Street", "Seventh Street", "Showdown"}
+	if s < 0 || int(s) >= len(names) {
+		return fmt.Sprintf("Street(%d)", int(s))
+	}
+	return names[s]
+}
+
+// ActionType is what a player does when it's their turn, or a forced bet
+type ActionType int
+
+// Constants for ActionType
+const (
+	Fold ActionType = iota
+	Check
+	Call
+	Bet
+	Raise
+	Complete
+	PostAnte
+	PostBringIn
+)
+
+func (a ActionType) String() string {
+	names := []string{"Fold", "Check", "Call", "Bet", "Raise", "Complete", "PostAnte", "PostBringIn"}
+	if a < 0 || int(a) >= len(names) {
+		return fmt.Sprintf("ActionType(%d)", int(a))
+	}
+	return names[a]
+}
+
+// Decider chooses a player's actions. Bots and humans plug in by implementing it.
+//
+// The game is fixed limit, so every bet and raise is one bet of the street's size.
+// Bet, Raise and Complete are interchangeable, the hand records whichever applies.
+// The player with the bring-in acts first on third street and must answer
+// PostBringIn or Complete (Bet and Raise complete too).
+type Decider interface {
+	Act(state State) ActionType
+}
+
+// DeciderFunc adapts a function to the Decider interface
+type DeciderFunc func(state State) ActionType
+
+// Act calls f(state)
+func (f DeciderFunc) Act(state State) ActionType {
+	return f(state)
+}
+
+// PlayerState is what everyone can see of a player
+type PlayerState struct {
+	Name   string
+	Stack  int         // chips behind
+	Bet    int         // chips bet on this street
+	Up     []deck.Card // face up cards
+	InHand bool        // dealt in and not folded
+	AllIn  bool
+}
+
+// State is what a player sees when it's their turn to act
+type State struct {
+	Street     Street
+	Seat       int
+	Down       []deck.Card // the player's face down cards
+	Community  []deck.Card // the shared seventh street card, when the deck ran short
+	Pot        int         // every chip committed so far, including this street's bets
+	CurrentBet int         // the highest bet on this street
+	ToCall     int         // chips needed to call
+	BetSize    int         // the size of a bet or raise on this street
+	Raises     int         // bets, completions and raises made on this street
+	CanRaise   bool
+	BringIn    bool // the player must post the bring-in or complete
+	Players    []PlayerState
+	Actions    []ActionRecord // everything that happened this hand so far
+}
+
+// CanCheck tells you if checking is allowed
+func (s State) CanCheck() bool {
+	return s.ToCall == 0 && !s.BringIn
+}
+
+// ActionRecord is an action as it happened, with the chips it moved
+type ActionRecord struct {
+	Street Street
+	Seat   int
+	Type   ActionType
+	Amount int  // chips put in by the action
+	To     int  // the player's total bet for the street afterwards
+	AllIn  bool // the action put the player all in
+}
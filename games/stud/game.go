@@ -0,0 +1,613 @@
+// Package stud is a fixed limit Seven-Card Stud engine, with Razz as a variant.
+package stud
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"adamclerk/deck"
+	"adamclerk/deck/games/internal/betting"
+	"adamclerk/deck/poker"
+	"adamclerk/deck/pot"
+)
+
+// Stud is a table of players playing Seven-Card Stud, hand after hand.
+// Rules can be found here: https://www.pokernews.com/poker-rules/7-card-stud.htm
+type Stud struct {
+	debug       bool
+	deckOptions []func(*deck.Options)
+	ante        int
+	bringIn     int
+	smallBet    int
+	bigBet      int
+	cap         int
+	razz        bool
+	openPair    bool
+	maxHands    int
+	hands       int
+	players     []*Player
+}
+
+// Seat describes a player joining the table
+type Seat struct {
+	Name    string
+	Stack   int
+	Decider Decider
+}
+
+// Options how to configure a game of Stud
+type Options struct {
+	DeckOptions []func(*deck.Options)
+	Seats       []Seat
+	Ante        int
+	BringIn     int
+	SmallBet    int // the bet on third and fourth street
+	BigBet      int // the bet from fifth street on
+	Cap         int // bets allowed on a street, counting the bet or completion, unless heads up
+	Razz        bool
+	OpenPair    bool // the big bet on fourth street when a player shows an open pair
+	MaxHands    int
+	Debug       bool
+}
+
+// Player is a player at the table
+type Player struct {
+	name    string
+	stack   int
+	decider Decider
+}
+
+// Name returns the players name for verification and announcement
+func (p Player) Name() string {
+	return p.name
+}
+
+// Stack returns the chips the player has
+func (p Player) Stack() int {
+	return p.stack
+}
+
+// WithDeck allows the a game to be configured with a specific deck.
+// A new deck is created with these options for every hand.
+func WithDeck(deckOptions ...func(*deck.Options)) func(*Options) {
+	return func(o *Options) {
+		o.DeckOptions = deckOptions
+	}
+}
+
+// WithSeat adds a player to the table. Players sit in the order they are added.
+func WithSeat(name string, stack int, decider Decider) func(*Options) {
+	return func(o *Options) {
+		o.Seats = append(o.Seats, Seat{Name: name, Stack: stack, Decider: decider})
+	}
+}
+
+// Ante sets the ante every player posts before each hand
+func Ante(amount int) func(*Options) {
+	return func(o *Options) {
+		o.Ante = amount
+	}
+}
+
+// BringIn sets the forced bet of the player showing the worst upcard on third street.
+// It must be less than the small bet.
+func BringIn(amount int) func(*Options) {
+	return func(o *Options) {
+		o.BringIn = amount
+	}
+}
+
+// Limits sets the small bet, used on third and fourth street, and the big bet used after
+func Limits(small, big int) func(*Options) {
+	return func(o *Options) {
+		o.SmallBet = small
+		o.BigBet = big
+	}
+}
+
+// Cap sets how many bets can be made on a street: the bet or completion and the raises.
+// The cap doesn't apply when only two players are left.
+func Cap(bets int) func(*Options) {
+	return func(o *Options) {
+		o.Cap = bets
+	}
+}
+
+// Razz is a functional option used to play Razz, stud for the lowest hand.
+// Hands are ranked ace-to-five, the highest upcard brings in and the lowest hand showing acts first.
+func Razz(o *Options) {
+	o.Razz = true
+}
+
+// OpenPair is a functional option used to bet the big bet on fourth street
+// when any player still in the hand shows a pair. It doesn't apply to Razz.
+func OpenPair(o *Options) {
+	o.OpenPair = true
+}
+
+// MaxHands before Play returns an error.
+func MaxHands(hands int) func(*Options) {
+	return func(o *Options) {
+		o.MaxHands = hands
+	}
+}
+
+// Debug sets the debug param for the game
+func Debug(o *Options) {
+	o.Debug = true
+}
+
+// New function creates a new game of Stud
+func New(options ...func(*Options)) (*Stud, error) {
+	opt := Options{DeckOptions: []func(*deck.Options){}, BringIn: 1, SmallBet: 2, BigBet: 4, Cap: 4, MaxHands: 100000}
+	for _, option := range options {
+		option(&opt)
+	}
+	if err := betting.CheckSeats(len(opt.Seats), 8); err != nil {
+		return nil, err
+	}
+	if opt.SmallBet <= 0 || opt.BigBet < opt.SmallBet || opt.BringIn <= 0 || opt.BringIn >= opt.SmallBet || opt.Ante < 0 || opt.Cap < 1 {
+		return nil, errors.New("Invalid limits")
+	}
+
+	s := &Stud{
+		debug:       opt.Debug,
+		deckOptions: opt.DeckOptions,
+		ante:        opt.Ante,
+		bringIn:     opt.BringIn,
+		smallBet:    opt.SmallBet,
+		bigBet:      opt.BigBet,
+		cap:         opt.Cap,
+		razz:        opt.Razz,
+		openPair:    opt.OpenPair,
+		maxHands:    opt.MaxHands,
+	}
+	for _, seat := range opt.Seats {
+		if seat.Decider == nil {
+			return nil, errors.New("Seat " + seat.Name + " has no decider")
+		}
+		s.players = append(s.players, &Player{name: seat.Name, stack: seat.Stack, decider: seat.Decider})
+	}
+	return s, nil
+}
+
+// Players returns the players in seat order
+func (s *Stud) Players() []*Player {
+	return s.players
+}
+
+// Play plays hands until one player has all the chips
+func (s *Stud) Play() error {
+	for s.activePlayers() > 1 {
+		if s.hands >= s.maxHands {
+			return errors.New("Too many hands played")
+		}
+		if _, err := s.PlayHand(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Winner announces the winner, the only player with chips left
+func (s *Stud) Winner() []Player {
+	var winners []Player
+	for _, p := range s.players {
+		if p.stack > 0 {
+			winners = append(winners, *p)
+		}
+	}
+	return winners
+}
+
+func (s *Stud) activePlayers() int {
+	return betting.Active(s.stacks())
+}
+
+// stacks returns the players' chips in seat order
+func (s *Stud) stacks() []int {
+	stacks := make([]int, len(s.players))
+	for i, p := range s.players {
+		stacks[i] = p.stack
+	}
+	return stacks
+}
+
+// HandResult is the record of a hand
+type HandResult struct {
+	Number    int
+	BringIn   int // seat of the player who had the bring-in
+	Signature string
+	Stacks    []int         // stacks at the start of the hand, by seat
+	Down      [][]deck.Card // face down cards by seat, nil for players not dealt in
+	Up        [][]deck.Card // face up cards by seat
+	Community []deck.Card   // the shared seventh street card, dealt when the deck runs short
+	Actions   []ActionRecord
+	Pots      []pot.Pot
+	Shown     []bool            // players who showed their cards at showdown, by seat
+	Hands     []poker.HandValue // the value of shown hands, by seat
+	Lows      []poker.LowValue  // the value of shown hands in razz, by seat
+	Winnings  []int             // chips won, by seat
+}
+
+// PlayHand plays a single hand.
+// If a player's decider returns an illegal action the hand is abandoned,
+// every stack is restored and an error is returned.
+func (s *Stud) PlayHand() (*HandResult, error) {
+	if s.activePlayers() < 2 {
+		return nil, errors.New("Not enough players with chips")
+	}
+	s.hands++
+
+	d, err := deck.New(s.deckOptions...)
+	if err != nil {
+		return nil, err
+	}
+	n := &hand{
+		Hand: betting.New(s.stacks()),
+		game: s,
+		deck: d,
+		result: &HandResult{
+			Number:    s.hands,
+			Signature: d.GetSignature(),
+			Stacks:    s.stacks(),
+			Down:      make([][]deck.Card, len(s.players)),
+			Up:        make([][]deck.Card, len(s.players)),
+			Shown:     make([]bool, len(s.players)),
+			Hands:     make([]poker.HandValue, len(s.players)),
+			Lows:      make([]poker.LowValue, len(s.players)),
+			Winnings:  make([]int, len(s.players)),
+		},
+	}
+	if err := n.play(); err != nil {
+		return nil, err
+	}
+	for i, p := range s.players {
+		p.stack = n.Stacks[i]
+	}
+	return n.result, nil
+}
+
+// hand is the state of the hand being played
+type hand struct {
+	*betting.Hand
+	game    *Stud
+	deck    *deck.Deck
+	street  Street
+	betSize int
+	raises  int // full bets and raises on the current street
+	result  *HandResult
+}
+
+func (n *hand) debugf(format string, a ...interface{}) {
+	deck.Debugf(n.game.debug, format, a...)
+}
+
+func (n *hand) player(seat int) *Player {
+	return n.game.players[seat]
+}
+
+// put moves chips from a player's stack to their bet, all in if the stack is short, and records the action
+func (n *hand) put(seat, amount int, t ActionType, toBet bool) {
+	amount = n.Put(seat, amount, toBet)
+	n.result.Actions = append(n.result.Actions, ActionRecord{
+		Street: n.street,
+		Seat:   seat,
+		Type:   t,
+		Amount: amount,
+		To:     n.Bets[seat],
+		AllIn:  n.Stacks[seat] == 0,
+	})
+	n.debugf("  %s: %s %d\n", n.player(seat).name, t, amount)
+}
+
+// deal gives one card to every player still in the hand, starting from seat 0
+func (n *hand) deal(up bool) {
+	seats := n.Seats()
+	hands := make([]*deck.Deck, len(seats))
+	for i := range hands {
+		hands[i], _ = deck.New(deck.Empty)
+	}
+	n.deck.Deal(1, hands...)
+	for i, s := range seats {
+		if up {
+			n.result.Up[s] = append(n.result.Up[s], hands[i].Cards...)
+		} else {
+			n.result.Down[s] = append(n.result.Down[s], hands[i].Cards...)
+		}
+	}
+}
+
+func (n *hand) play() error {
+	g := n.game
+	n.debugf("Hand %d Started\n", n.result.Number)
+
+	if g.ante > 0 {
+		for _, s := range n.Seats() {
+			n.put(s, g.ante, PostAnte, false)
+		}
+	}
+	// seven cards each, or six and the community card when eight players stay to the river
+	if n.deck.NumberOfCards() < 6*n.InHand()+1 {
+		return errors.New("Not enough cards in the deck")
+	}
+	n.deal(false)
+	n.deal(false)
+	n.deal(true)
+	for _, s := range n.Seats() {
+		n.debugf("  %s shows %s\n", n.player(s).name, cardList(n.result.Up[s]))
+	}
+
+	n.result.BringIn = n.bringInSeat()
+	n.betSize = g.smallBet
+	if err := n.Round(n.result.BringIn, n.act); err != nil {
+		return err
+	}
+
+	for _, street := range []Street{FourthStreet, FifthStreet, SixthStreet, SeventhStreet} {
+		if n.InHand() < 2 {
+			break
+		}
+		n.street = street
+		switch {
+		case street < SeventhStreet:
+			n.deal(true)
+		case n.deck.NumberOfCards() >= n.InHand():
+			n.deal(false)
+		default:
+			community, _ := deck.New(deck.Empty)
+			n.deck.Deal(1, community)
+			n.result.Community = community.Cards
+			n.debugf("  Community card %s\n", cardList(community.Cards))
+		}
+		n.debugf("%s\n", street)
+
+		n.NewStreet()
+		n.raises = 0
+		if street >= FifthStreet || (street == FourthStreet && g.openPair && !g.razz && n.showsPair()) {
+			n.betSize = g.bigBet
+		}
+		if err := n.Round(n.firstToAct(), n.act); err != nil {
+			return err
+		}
+	}
+	n.street = Showdown
+	return n.award()
+}
+
+// ranksBelow tells you if card a ranks below card b in the game played: aces are high
+// in stud and low in razz. Equal faces are ranked by suit with DefaultCompare, clubs lowest.
+func (n *hand) ranksBelow(a, b deck.Card) bool {
+	ace := int(deck.ACE)
+	if !n.game.razz && a.Face() != b.Face() && (a.Face() == ace || b.Face() == ace) {
+		return b.Face() == ace
+	}
+	return deck.DefaultCompare(a, b).IsLessThan()
+}
+
+// bringInSeat finds the worst upcard: the lowest in stud, the highest in razz
+func (n *hand) bringInSeat() int {
+	seat := -1
+	for _, s := range n.Seats() {
+		if seat < 0 {
+			seat = s
+			continue
+		}
+		up, worst := n.result.Up[s][0], n.result.Up[seat][0]
+		if (!n.game.razz && n.ranksBelow(up, worst)) || (n.game.razz && n.ranksBelow(worst, up)) {
+			seat = s
+		}
+	}
+	return seat
+}
+
+// showsPair tells you if a player still in the hand has a pair among their upcards
+func (n *hand) showsPair() bool {
+	for _, s := range n.Seats() {
+		faces := map[int]bool{}
+		for _, c := range n.result.Up[s] {
+			if faces[c.Face()] {
+				return true
+			}
+			faces[c.Face()] = true
+		}
+	}
+	return false
+}
+
+// firstToAct finds the best hand showing, the first seat winning ties
+func (n *hand) firstToAct() int {
+	seat, best := -1, 0
+	for _, s := range n.Seats() {
+		if v := n.value(n.result.Up[s]); seat < 0 || v > best {
+			seat, best = s, v
+		}
+	}
+	return seat
+}
+
+// value ranks cards for the game played, higher is better
+func (n *hand) value(cards []deck.Card) int {
+	if n.game.razz {
+		return poker.EvaluateAceToFive(cards).Value
+	}
+	return poker.Evaluate(cards).Value
+}
+
+func (n *hand) state(seat int) State {
+	s := State{
+		Street:     n.street,
+		Seat:       seat,
+		Down:       append([]deck.Card{}, n.result.Down[seat]...),
+		Community:  append([]deck.Card{}, n.result.Community...),
+		CurrentBet: n.CurrentBet,
+		ToCall:     n.ToCall(seat),
+		BetSize:    n.betSize,
+		Raises:     n.raises,
+		CanRaise:   n.CanRaise(seat) && (n.raises < n.game.cap || n.InHand() == 2),
+		BringIn:    n.street == ThirdStreet && seat == n.result.BringIn && !n.Acted[seat],
+		Pot:        n.Pot(),
+		Actions:    append([]ActionRecord{}, n.result.Actions...),
+	}
+	for i, q := range n.game.players {
+		s.Players = append(s.Players, PlayerState{
+			Name:   q.name,
+			Stack:  n.Stacks[i],
+			Bet:    n.Bets[i],
+			Up:     append([]deck.Card{}, n.result.Up[i]...),
+			InHand: !n.Folded[i],
+			AllIn:  n.AllIn(i),
+		})
+	}
+	return s
+}
+
+// act asks a player for an action and applies it
+func (n *hand) act(seat int) error {
+	p := n.player(seat)
+	s := n.state(seat)
+	a := p.decider.Act(s)
+	invalid := func(reason string) error {
+		return fmt.Errorf("Invalid action %s by %s: %s", a, p.name, reason)
+	}
+
+	if s.BringIn {
+		switch a {
+		case PostBringIn:
+			n.put(seat, n.game.bringIn, PostBringIn, true)
+			n.CurrentBet = n.Bets[seat]
+			return nil
+		case Bet, Raise, Complete:
+			n.raise(seat)
+			return nil
+		}
+		return invalid("the bring-in must be posted or completed")
+	}
+
+	switch a {
+	case Fold:
+		n.Folded[seat] = true
+		n.put(seat, 0, Fold, true)
+		return nil
+	case Check:
+		if s.ToCall > 0 {
+			return invalid("there is a bet to call")
+		}
+		n.put(seat, 0, Check, true)
+		return nil
+	case Call:
+		if s.ToCall == 0 {
+			return invalid("there is nothing to call")
+		}
+		n.put(seat, s.ToCall, Call, true)
+		return nil
+	case Bet, Raise, Complete:
+		if !s.CanRaise && n.raises >= n.game.cap && n.InHand() > 2 {
+			return invalid("the betting is capped")
+		}
+		if !s.CanRaise {
+			return invalid("betting is not open to this player")
+		}
+		n.raise(seat)
+		return nil
+	}
+	return invalid("unknown action")
+}
+
+// raise bets, completes or raises by one bet, or all in for less
+func (n *hand) raise(seat int) {
+	to, t := n.CurrentBet+n.betSize, Raise
+	switch {
+	case n.CurrentBet == 0 && n.street != ThirdStreet:
+		to, t = n.betSize, Bet
+	case n.CurrentBet < n.betSize:
+		// the bring-in, or an all in for less than a bet, is completed to a full bet
+		to, t = n.betSize, Complete
+	}
+	if most := n.Bets[seat] + n.Stacks[seat]; to > most {
+		to = most
+	}
+	// the first completion, or an all in for at least half a bet, counts as a full bet
+	// and reopens the betting
+	if (t == Complete && n.raises == 0) || 2*(to-n.CurrentBet) >= n.betSize {
+		n.raises++
+		n.Reopen(seat)
+	}
+	n.CurrentBet = to
+	n.put(seat, to-n.Bets[seat], t, true)
+}
+
+// award gives the pots to the winners, at showdown or to the last player left
+func (n *hand) award() error {
+	r := n.result
+	showdown := n.InHand() > 1
+	values := make([]int, len(n.Folded))
+	cards := make([][]deck.Card, len(n.Folded))
+	for i, f := range n.Folded {
+		if f {
+			continue
+		}
+		cards[i] = append(append(append([]deck.Card{}, r.Down[i]...), r.Up[i]...), r.Community...)
+		if !showdown {
+			continue
+		}
+		values[i] = n.value(cards[i])
+		r.Shown[i] = true
+		if n.game.razz {
+			r.Lows[i] = poker.EvaluateAceToFive(cards[i])
+			n.debugf("  %s shows %s: %s\n", n.player(i).name, cardList(cards[i]), r.Lows[i])
+		} else {
+			r.Hands[i] = poker.Evaluate(cards[i])
+			n.debugf("  %s shows %s: %s\n", n.player(i).name, cardList(cards[i]), r.Hands[i])
+		}
+	}
+
+	split, err := n.Award(values, pot.OddChips(func(winners []int) []int {
+		return n.oddChips(winners, cards)
+	}))
+	if err != nil {
+		return err
+	}
+	r.Pots = split.Pots
+	r.Winnings = split.Winnings
+	for i, won := range r.Winnings {
+		if won > 0 {
+			n.debugf("  %s wins %d\n", n.player(i).name, won)
+		}
+	}
+	return nil
+}
+
+// oddChips orders the winners by their best card by suit: the highest card in stud,
+// the lowest in razz. The first gets the odd chip.
+func (n *hand) oddChips(winners []int, cards [][]deck.Card) []int {
+	best := make(map[int]deck.Card)
+	for _, w := range winners {
+		for i, c := range cards[w] {
+			if i == 0 || n.ranksBelow(best[w], c) != n.game.razz {
+				best[w] = c
+			}
+		}
+	}
+	ordered := append([]int{}, winners...)
+	sort.Slice(ordered, func(i, j int) bool {
+		a, b := best[ordered[i]], best[ordered[j]]
+		if n.game.razz {
+			return n.ranksBelow(a, b)
+		}
+		return n.ranksBelow(b, a)
+	})
+	return ordered
+}
+
+func cardList(cards []deck.Card) string {
+	str := ""
+	for i, c := range cards {
+		if i > 0 {
+			str += " "
+		}
+		str += c.String()
+	}
+	return str
+}
@@ -0,0 +1,43 @@
+package stud
+
+import (
+	"fmt"
+
+	"adamclerk/deck"
+)
+
+// callingStation brings in, checks when it can and calls otherwise
+var callingStation = DeciderFunc(func(s State) ActionType {
+	switch {
+	case s.BringIn:
+		return PostBringIn
+	case s.CanCheck():
+		return Check
+	}
+	return Call
+})
+
+// This example plays an unshuffled deck, so Alice is dealt the odd clubs and Bob the even ones
+func Example() {
+	game, err := New(
+		WithDeck(deck.Unshuffled),
+		WithSeat("Alice", 100, callingStation),
+		WithSeat("Bob", 100, callingStation),
+		Ante(1),
+	)
+	if err != nil {
+		panic(err)
+	}
+	result, err := game.PlayHand()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%s brings in\n", game.Players()[result.BringIn].Name())
+	for i, p := range game.Players() {
+		fmt.Printf("%s shows %s: %s, %d chips\n", p.Name(), cardList(result.Up[i]), result.Hands[i], p.Stack())
+	}
+	// Output:
+	// Alice brings in
+	// Alice shows 5♣ 7♣ 9♣ J♣: Flush (A K J 9 7), 102 chips
+	// Bob shows 6♣ 8♣ T♣ Q♣: Flush (Q T 8 6 4), 98 chips
+}
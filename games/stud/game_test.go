@@ -0,0 +1,302 @@
+package stud
+
+import (
+	"math/rand"
+	"testing"
+
+	"adamclerk/deck"
+	"adamclerk/deck/internal/decktest"
+	"github.com/stretchr/testify/assert"
+)
+
+// script plays the actions in order and then plays passively
+func script(actions ...ActionType) Decider {
+	return DeciderFunc(func(s State) ActionType {
+		if len(actions) == 0 {
+			return passive(s)
+		}
+		a := actions[0]
+		actions = actions[1:]
+		return a
+	})
+}
+
+// passive brings in, checks when it can and calls otherwise
+func passive(s State) ActionType {
+	switch {
+	case s.BringIn:
+		return PostBringIn
+	case s.CanCheck():
+		return Check
+	}
+	return Call
+}
+
+// stacked returns a deck option dealing the given cards first; the rest of the deck follows in order.
+// Third street is dealt one card at a time from seat 0: down, down, then up.
+func stacked(cards ...string) func(*Options) {
+	return WithDeck(decktest.Stacked(cards...)...)
+}
+
+func stacks(g *Stud) []int {
+	var result []int
+	for _, p := range g.Players() {
+		result = append(result, p.Stack())
+	}
+	return result
+}
+
+func TestBringIn(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		razz  bool
+		cards []string
+		seat  int
+	}{
+		{"lowest suit", false, []string{"9h", "9d", "9s", "Th", "Td", "Ts", "2d", "2c", "Ks"}, 1},
+		{"aces are high", false, []string{"9h", "9d", "Th", "Td", "Ac", "3d"}, 1},
+		{"razz highest suit", true, []string{"9h", "9d", "9s", "Th", "Td", "Ts", "Kc", "Ks", "2d"}, 1},
+		{"razz aces are low", true, []string{"9h", "9d", "Th", "Td", "As", "2c"}, 1},
+	} {
+		options := []func(*Options){stacked(test.cards...)}
+		for i := 0; i < len(test.cards)/3; i++ {
+			options = append(options, WithSeat(string(rune('A'+i)), 100, DeciderFunc(passive)))
+		}
+		if test.razz {
+			options = append(options, Razz)
+		}
+		game, _ := New(options...)
+		result, err := game.PlayHand()
+		assert.Nil(t, err, test.name)
+		assert.Equal(t, test.seat, result.BringIn, test.name)
+		assert.Equal(t, ActionRecord{Street: ThirdStreet, Seat: test.seat, Type: PostBringIn, Amount: 1, To: 1}, result.Actions[0], test.name)
+	}
+}
+
+func TestBestHandShowingActsFirst(t *testing.T) {
+	cards := []string{
+		"9h", "9d", "9s", "Th", "Td", "Ts", // down cards
+		"2c", "5d", "7h", // third street
+		"8c", "5h", "Kh", // fourth street
+	}
+	for razz, seat := range map[bool]int{false: 1, true: 0} {
+		options := []func(*Options){
+			stacked(cards...),
+			WithSeat("Alice", 100, DeciderFunc(passive)),
+			WithSeat("Bob", 100, DeciderFunc(passive)),
+			WithSeat("Carol", 100, DeciderFunc(passive)),
+		}
+		if razz {
+			options = append(options, Razz)
+		}
+		game, _ := New(options...)
+		result, _ := game.PlayHand()
+		for _, a := range result.Actions {
+			if a.Street == FourthStreet {
+				assert.Equal(t, seat, a.Seat, "razz %v", razz)
+				break
+			}
+		}
+	}
+}
+
+func TestCompleteAndCap(t *testing.T) {
+	cards := []string{"9h", "9d", "9s", "9c", "Th", "Td", "Ts", "Tc", "2c", "Kd", "Kh", "Ks"}
+	game, _ := New(
+		stacked(cards...),
+		WithSeat("A", 100, script(PostBringIn, Raise)),
+		WithSeat("B", 100, script(Complete)),
+		WithSeat("C", 100, script(Raise)),
+		WithSeat("D", 100, script(Raise)),
+	)
+	result, err := game.PlayHand()
+	assert.Nil(t, err)
+	var types []ActionType
+	var to []int
+	for _, a := range result.Actions[:8] {
+		types = append(types, a.Type)
+		to = append(to, a.To)
+	}
+	assert.Equal(t, []ActionType{PostBringIn, Complete, Raise, Raise, Raise, Call, Call, Call}, types)
+	assert.Equal(t, []int{1, 2, 4, 6, 8, 8, 8, 8}, to)
+
+	game, _ = New(
+		stacked(cards...),
+		WithSeat("A", 100, script(PostBringIn, Raise)),
+		WithSeat("B", 100, script(Complete, Raise)),
+		WithSeat("C", 100, script(Raise)),
+		WithSeat("D", 100, script(Raise)),
+	)
+	_, err = game.PlayHand()
+	assert.Equal(t, "Invalid action Raise by B: the betting is capped", err.Error())
+	assert.Equal(t, []int{100, 100, 100, 100}, stacks(game))
+}
+
+func TestOpenPairOnFourthStreet(t *testing.T) {
+	// Alice pairs her nines on fourth street and bets first
+	cards := []string{"Ah", "Kh", "As", "Ks", "9c", "2d", "9h", "3d"}
+	for openPair, size := range map[bool]int{false: 2, true: 4} {
+		options := []func(*Options){
+			stacked(cards...),
+			WithSeat("Alice", 100, script(Call, Bet)),
+			WithSeat("Bob", 100, DeciderFunc(passive)),
+		}
+		if openPair {
+			options = append(options, OpenPair)
+		}
+		game, _ := New(options...)
+		result, err := game.PlayHand()
+		assert.Nil(t, err)
+		for _, a := range result.Actions {
+			if a.Street == FourthStreet {
+				assert.Equal(t, ActionRecord{Street: FourthStreet, Seat: 0, Type: Bet, Amount: size, To: size}, a, "open pair %v", openPair)
+				break
+			}
+		}
+	}
+}
+
+func TestHeadsUpIsNotCapped(t *testing.T) {
+	fold := script(PostBringIn, Raise, Raise, Raise, Fold)
+	game, _ := New(
+		stacked("9h", "9d", "Th", "Td", "2c", "Kd"),
+		WithSeat("Alice", 100, fold),
+		WithSeat("Bob", 100, script(Complete, Raise, Raise, Raise)),
+	)
+	result, err := game.PlayHand()
+	assert.Nil(t, err)
+	assert.Equal(t, 26, result.Pots[0].Amount)
+	assert.Equal(t, []int{88, 112}, stacks(game))
+}
+
+func TestBringInMustBePostedOrCompleted(t *testing.T) {
+	for _, a := range []ActionType{Check, Fold, Call} {
+		game, _ := New(
+			stacked("9h", "9d", "Th", "Td", "2c", "Kd"),
+			WithSeat("Alice", 100, script(a)),
+			WithSeat("Bob", 100, DeciderFunc(passive)),
+		)
+		_, err := game.PlayHand()
+		assert.Equal(t, "Invalid action "+a.String()+" by Alice: the bring-in must be posted or completed", err.Error())
+	}
+}
+
+func TestCommunityCard(t *testing.T) {
+	var options []func(*Options)
+	for i := 0; i < 8; i++ {
+		options = append(options, WithSeat(string(rune('A'+i)), 100, DeciderFunc(passive)))
+	}
+	game, _ := New(append(options, WithDeck(deck.Unshuffled))...)
+	result, err := game.PlayHand()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(result.Community))
+	for seat := range game.Players() {
+		assert.Equal(t, 2, len(result.Down[seat]))
+		assert.Equal(t, 4, len(result.Up[seat]))
+		assert.True(t, result.Shown[seat])
+	}
+}
+
+func TestRazzShowdown(t *testing.T) {
+	game, _ := New(
+		stacked("Ac", "9c", "2c", "Tc", "3d", "Js", "4d", "Jd", "5h", "8s", "Kh", "7s", "Qh", "6s"),
+		WithSeat("Alice", 100, DeciderFunc(passive)),
+		WithSeat("Bob", 100, DeciderFunc(passive)),
+		Razz,
+	)
+	result, err := game.PlayHand()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, result.BringIn)
+	assert.Equal(t, "5-4-3-2-A number one", result.Lows[0].String())
+	assert.Equal(t, []int{101, 99}, stacks(game))
+}
+
+func TestOddChipToHighestCardBySuit(t *testing.T) {
+	game, _ := New(
+		stacked(
+			"Ah", "As", "2h", "Kh", "Ks", "3h", "Qd", "Qc", "4h", // Carol brings in
+			"Jd", "Jc", "5h",
+			"9c", "9d", "3d", "3c", "2c", "2d",
+		),
+		WithSeat("Alice", 100, DeciderFunc(passive)),
+		WithSeat("Bob", 100, DeciderFunc(passive)),
+		WithSeat("Carol", 100, script(PostBringIn, Fold)),
+	)
+	result, err := game.PlayHand()
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 1}, result.Pots[0].Winners)
+	// Bob's ace of spades beats Alice's ace of hearts for the odd chip
+	assert.Equal(t, []int{100, 101, 99}, stacks(game))
+}
+
+func TestPlayUntilOnePlayerIsLeft(t *testing.T) {
+	deck.Seed()
+	raise := DeciderFunc(func(s State) ActionType {
+		if s.CanRaise {
+			return Raise
+		}
+		return passive(s)
+	})
+	game, _ := New(
+		WithSeat("Alice", 50, raise),
+		WithSeat("Bob", 50, DeciderFunc(passive)),
+		WithSeat("Carol", 50, raise),
+		Ante(1),
+	)
+	err := game.Play()
+	assert.Nil(t, err)
+	winners := game.Winner()
+	assert.Equal(t, 1, len(winners))
+	assert.Equal(t, 150, winners[0].Stack())
+}
+
+func TestNewErrors(t *testing.T) {
+	_, err := New(WithSeat("Alice", 100, DeciderFunc(passive)))
+	assert.Equal(t, "At least two seats are needed", err.Error())
+	_, err = New(WithSeat("Alice", 100, nil), WithSeat("Bob", 100, nil))
+	assert.Equal(t, "Seat Alice has no decider", err.Error())
+	_, err = New(WithSeat("Alice", 100, DeciderFunc(passive)), WithSeat("Bob", 100, DeciderFunc(passive)), BringIn(2))
+	assert.Equal(t, "Invalid limits", err.Error())
+}
+
+func TestRandomPlayKeepsEveryChip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	random := DeciderFunc(func(s State) ActionType {
+		switch r.Intn(4) {
+		case 0:
+			if !s.BringIn {
+				return Fold
+			}
+		case 1:
+			if s.CanRaise {
+				return Raise
+			}
+		}
+		return passive(s)
+	})
+	for game := 0; game < 50; game++ {
+		var options []func(*Options)
+		for seat := 0; seat < 2+game%7; seat++ {
+			options = append(options, WithSeat(string(rune('A'+seat)), 5+r.Intn(100), random))
+		}
+		if game%2 == 1 {
+			options = append(options, Razz)
+		}
+		g, _ := New(append(options, Ante(game%3))...)
+		total := 0
+		for _, p := range g.Players() {
+			total += p.Stack()
+		}
+		for g.activePlayers() > 1 {
+			result, err := g.PlayHand()
+			if !assert.Nil(t, err) {
+				return
+			}
+			sum := 0
+			for _, p := range g.Players() {
+				sum += p.Stack()
+			}
+			assert.Equal(t, total, sum, "hand %d", result.Number)
+		}
+	}
+}
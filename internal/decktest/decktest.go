@@ -0,0 +1,33 @@
+// Package decktest builds the cards and decks that tests deal from
+package decktest
+
+import (
+	"strings"
+
+	"adamclerk/deck"
+)
+
+// Cards reads cards in short notation, e.g. Cards("Ah", "Kd") or Cards("Ah Kd"), and panics on a card it can't read
+func Cards(cards ...string) []deck.Card {
+	parsed, err := deck.ParseCards(strings.Join(cards, " "))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// Stacked returns the options for an unshuffled deck dealing the cards first, in order, then the rest of the deck
+func Stacked(cards ...string) []func(*deck.Options) {
+	order := Cards(cards...)
+	used := map[deck.Card]bool{}
+	for _, c := range order {
+		used[c] = true
+	}
+	full, _ := deck.New(deck.Unshuffled)
+	for _, c := range full.Cards {
+		if !used[c] {
+			order = append(order, c)
+		}
+	}
+	return []func(*deck.Options){deck.Unshuffled, deck.WithCards(order...)}
+}
@@ -0,0 +1,11 @@
+// Package splitmix splits one seed into many, for work done in seeded blocks or chunks.
+package splitmix
+
+// Mix mixes the seed and a block number with a splitmix64 step, so that neighbouring blocks,
+// and the blocks of neighbouring seeds, get unrelated seeds
+func Mix(seed int64, number int) int64 {
+	z := uint64(seed) + uint64(number+1)*0x9e3779b97f4a7c15
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return int64(z ^ z>>31)
+}
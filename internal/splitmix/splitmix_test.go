@@ -0,0 +1,20 @@
+package splitmix
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMix(t *testing.T) {
+	// neither the blocks of one seed nor those of the seeds next to it share a seed
+	seen := map[int64]bool{}
+	for seed := int64(-2); seed <= 2; seed++ {
+		for block := 0; block < 1000; block++ {
+			mixed := Mix(seed, block)
+			assert.False(t, seen[mixed], "seed %d block %d", seed, block)
+			seen[mixed] = true
+		}
+	}
+	assert.Equal(t, Mix(7, 3), Mix(7, 3))
+}
@@ -0,0 +1,120 @@
+package mentalpoker
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+)
+
+var one = big.NewInt(1)
+
+// key is an SRA (Pohlig-Hellman) key pair: m^encrypt^decrypt = m mod p
+type key struct {
+	encrypt, decrypt *big.Int
+}
+
+// Party is one participant in the protocol. It never shares its deck key,
+// and shares a card key only when that card is revealed.
+type Party struct {
+	name     string
+	prime    *big.Int
+	order    *big.Int // prime - 1, the exponent modulus
+	random   io.Reader
+	deckKey  key
+	cardKeys []key
+}
+
+// Name returns the party's name
+func (p *Party) Name() string {
+	return p.name
+}
+
+// newParty creates a party working modulo the prime, which it checks is a safe prime:
+// the cards are encoded as quadratic residues, and with any other prime their exponents leak information.
+func newParty(name string, prime *big.Int, random io.Reader) (*Party, error) {
+	if !safePrime(prime) {
+		return nil, errors.New("The prime must be a safe prime")
+	}
+	p := &Party{name: name, prime: prime, order: new(big.Int).Sub(prime, one), random: random}
+	k, err := p.newKey()
+	if err != nil {
+		return nil, err
+	}
+	p.deckKey = k
+	return p, nil
+}
+
+// safePrime tells you if p is a prime 2q + 1 with q prime too
+func safePrime(p *big.Int) bool {
+	if p == nil || p.Cmp(big.NewInt(5)) < 0 || !p.ProbablyPrime(20) {
+		return false
+	}
+	q := new(big.Int).Rsh(p, 1)
+	return q.ProbablyPrime(20)
+}
+
+// newKey picks a random exponent invertible modulo p-1
+func (p *Party) newKey() (key, error) {
+	gcd := new(big.Int)
+	for i := 0; i < 1000; i++ {
+		e, err := rand.Int(p.random, p.order)
+		if err != nil {
+			return key{}, err
+		}
+		if e.Cmp(one) <= 0 {
+			continue
+		}
+		if gcd.GCD(nil, nil, e, p.order).Cmp(one) != 0 {
+			continue
+		}
+		return key{encrypt: e, decrypt: new(big.Int).ModInverse(e, p.order)}, nil
+	}
+	return key{}, errors.New("Could not generate a key")
+}
+
+// encryptAndShuffle is the first pass: every card is locked with the deck key and the order is shuffled.
+func (p *Party) encryptAndShuffle(cards []*big.Int) ([]*big.Int, error) {
+	out := make([]*big.Int, len(cards))
+	for i, c := range cards {
+		out[i] = new(big.Int).Exp(c, p.deckKey.encrypt, p.prime)
+	}
+	for i := len(out) - 1; i > 0; i-- {
+		j, err := rand.Int(p.random, big.NewInt(int64(i+1)))
+		if err != nil {
+			return nil, err
+		}
+		out[i], out[j.Int64()] = out[j.Int64()], out[i]
+	}
+	return out, nil
+}
+
+// relock is the second pass: the deck key is removed and each card is locked
+// with its own key, so cards can later be revealed one at a time.
+func (p *Party) relock(cards []*big.Int) ([]*big.Int, error) {
+	p.cardKeys = make([]key, len(cards))
+	out := make([]*big.Int, len(cards))
+	for i, c := range cards {
+		k, err := p.newKey()
+		if err != nil {
+			return nil, err
+		}
+		p.cardKeys[i] = k
+		out[i] = new(big.Int).Exp(c, p.deckKey.decrypt, p.prime)
+		out[i].Exp(out[i], k.encrypt, p.prime)
+	}
+	return out, nil
+}
+
+// KeyShare returns the party's decryption key for the card at index
+func (p *Party) KeyShare(index int) (*big.Int, error) {
+	if index < 0 || index >= len(p.cardKeys) {
+		return nil, errors.New("No key for card")
+	}
+	return new(big.Int).Set(p.cardKeys[index].decrypt), nil
+}
+
+// unlock removes one layer of encryption with a key share
+func (p *Party) unlock(card, share *big.Int) *big.Int {
+	return new(big.Int).Exp(card, share, p.prime)
+}
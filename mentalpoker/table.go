@@ -0,0 +1,250 @@
+// Package mentalpoker shuffles and deals a deck between parties that don't trust each other,
+// without a dealer, using the SRA commutative encryption scheme.
+//
+// Every card is encoded as a number modulo a shared safe prime p and encrypted
+// as m^e mod p. Encryptions by different parties commute, so each party in turn
+// locks every card with its own deck key and shuffles. In a second pass each
+// party swaps its deck key for one key per card. A card is revealed when every
+// party hands over its key share for that position; a card can be revealed
+// privately to a single party by giving the shares to that party only.
+//
+// Cards are encoded as quadratic residues so the well known quadratic residue
+// leak of SRA tells nothing about them. Parties run in-process; the protocol
+// assumes they follow it honestly and does not detect a cheating party.
+package mentalpoker
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"adamclerk/deck"
+)
+
+// Options how to configure a table
+type Options struct {
+	DeckOptions []func(*deck.Options)
+	Parties     []string
+	Prime       *big.Int
+	Random      io.Reader
+}
+
+// Table holds the parties and the encrypted deck
+type Table struct {
+	prime         *big.Int
+	parties       []*Party
+	cards         []*big.Int // the encrypted deck, in dealing order
+	numberOfDecks int
+	decode        map[string]deck.Card
+	revealed      map[int]deck.Card
+	next          int
+	shuffled      bool
+}
+
+// RFC3526Prime is the 2048-bit MODP safe prime from RFC 3526 group 14
+var RFC3526Prime, _ = new(big.Int).SetString(
+	"FFFFFFFFFFFFFFFFC90FDAA22168C234C4C6628B80DC1CD1"+
+		"29024E088A67CC74020BBEA63B139B22514A08798E3404DD"+
+		"EF9519B3CD3A431B302B0A6DF25F14374FE1356D6D51C245"+
+		"E485B576625E7EC6F44C42E9A637ED6B0BFF5CB6F406B7ED"+
+		"EE386BFB5A899FA5AE9F24117C4B1FE649286651ECE45B3D"+
+		"C2007CB8A163BF0598DA48361C55D39A69163FA8FD24CF5F"+
+		"83655D23DCA3AD961C62F356208552BB9ED529077096966D"+
+		"670C354E4ABC9804F1746C08CA18217C32905E462E36CE3B"+
+		"E39E772C180E86039B2783A2EC07A28FB5C55DF06F4C52C9"+
+		"DE2BCBF6955817183995497CEA956AE515D2261898FA0510"+
+		"15728E5A8AACAA68FFFFFFFFFFFFFFFF", 16)
+
+// WithDeck allows the table to be configured with a specific deck.
+// The deck is always shuffled by the protocol; its order going in doesn't matter.
+func WithDeck(deckOptions ...func(*deck.Options)) func(*Options) {
+	return func(o *Options) {
+		o.DeckOptions = deckOptions
+	}
+}
+
+// Parties sets the names of the participants, in the order they shuffle
+func Parties(names ...string) func(*Options) {
+	return func(o *Options) {
+		o.Parties = names
+	}
+}
+
+// Prime sets the shared modulus. It must be a safe prime (p = 2q + 1 with q prime), big enough
+// for every card to encode to a different number; New rejects any other.
+func Prime(p *big.Int) func(*Options) {
+	return func(o *Options) {
+		o.Prime = p
+	}
+}
+
+// Random sets the source of randomness used by every party for keys and shuffles
+func Random(r io.Reader) func(*Options) {
+	return func(o *Options) {
+		o.Random = r
+	}
+}
+
+// New creates a table and its parties. Call Shuffle before revealing any card.
+func New(options ...func(*Options)) (*Table, error) {
+	opt := Options{DeckOptions: []func(*deck.Options){}, Parties: []string{"Player1", "Player2"}, Prime: RFC3526Prime, Random: rand.Reader}
+	for _, option := range options {
+		option(&opt)
+	}
+	if len(opt.Parties) == 0 {
+		return nil, errors.New("At least one party is needed")
+	}
+
+	d, err := deck.New(append(opt.DeckOptions, deck.Unshuffled)...)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Table{
+		prime:         opt.Prime,
+		numberOfDecks: d.NumberOfDecks,
+		decode:        map[string]deck.Card{},
+		revealed:      map[int]deck.Card{},
+	}
+	for _, name := range opt.Parties {
+		p, err := newParty(name, opt.Prime, opt.Random)
+		if err != nil {
+			return nil, err
+		}
+		t.parties = append(t.parties, p)
+	}
+	for _, card := range d.Cards {
+		m := t.encode(card)
+		if other, ok := t.decode[m.String()]; m.Sign() == 0 || (ok && other != card) {
+			return nil, errors.New("The prime is too small to tell the cards apart")
+		}
+		t.cards = append(t.cards, m)
+		t.decode[m.String()] = card
+	}
+	return t, nil
+}
+
+// encode maps a card to the quadratic residue (card+2)^2 mod p
+func (t *Table) encode(card deck.Card) *big.Int {
+	m := big.NewInt(int64(card) + 2)
+	return m.Exp(m, big.NewInt(2), t.prime)
+}
+
+// Parties returns the participants in shuffling order
+func (t *Table) Parties() []*Party {
+	return t.parties
+}
+
+// Shuffle runs both encryption passes. Each party encrypts and shuffles in turn,
+// then each party replaces its deck key with per-card keys. A table is shuffled once.
+func (t *Table) Shuffle() error {
+	if t.shuffled {
+		return errors.New("The deck is already shuffled")
+	}
+	t.shuffled = true
+	var err error
+	for _, p := range t.parties {
+		if t.cards, err = p.encryptAndShuffle(t.cards); err != nil {
+			return err
+		}
+	}
+	for _, p := range t.parties {
+		if t.cards, err = p.relock(t.cards); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NumberOfCards tells you how many cards are in the encrypted deck
+func (t *Table) NumberOfCards() int {
+	return len(t.cards)
+}
+
+// Reveal shows the card at index to everyone. Every party provides its key share.
+func (t *Table) Reveal(index int) (deck.Card, error) {
+	if card, ok := t.revealed[index]; ok {
+		return card, nil
+	}
+	card, err := t.unlock(index, nil)
+	if err != nil {
+		return 0, err
+	}
+	t.revealed[index] = card
+	return card, nil
+}
+
+// RevealTo shows the card at index to a single party. Every other party sends
+// its key share to that party, which applies them and its own key locally.
+// The card stays hidden from everyone else.
+func (t *Table) RevealTo(index int, party *Party) (deck.Card, error) {
+	return t.unlock(index, party)
+}
+
+func (t *Table) unlock(index int, owner *Party) (deck.Card, error) {
+	if index < 0 || index >= len(t.cards) {
+		return 0, errors.New("Card index out of range")
+	}
+	m := t.cards[index]
+	for _, p := range t.parties {
+		if p == owner {
+			continue
+		}
+		share, err := p.KeyShare(index)
+		if err != nil {
+			return 0, err
+		}
+		m = p.unlock(m, share)
+	}
+	if owner != nil {
+		share, err := owner.KeyShare(index)
+		if err != nil {
+			return 0, err
+		}
+		m = owner.unlock(m, share)
+	}
+	card, ok := t.decode[m.String()]
+	if !ok {
+		return 0, fmt.Errorf("Card %d did not decrypt to a known card", index)
+	}
+	return card, nil
+}
+
+// Deal gives the next cards in the encrypted deck to the parties, privately, round robin.
+// It returns each party's hand in the same order as Parties.
+func (t *Table) Deal(cards int) ([]*deck.Deck, error) {
+	hands := make([]*deck.Deck, len(t.parties))
+	for i := range hands {
+		hands[i], _ = deck.New(deck.Empty)
+	}
+	if t.next+cards*len(t.parties) > len(t.cards) {
+		return nil, errors.New("Not enough cards")
+	}
+	for c := 0; c < cards; c++ {
+		for i, p := range t.parties {
+			card, err := t.RevealTo(t.next, p)
+			if err != nil {
+				return nil, err
+			}
+			hands[i].Cards = append(hands[i].Cards, card)
+			t.next++
+		}
+	}
+	return hands, nil
+}
+
+// Deck reveals every card to everyone and returns them as a regular deck, in dealing order
+func (t *Table) Deck() (*deck.Deck, error) {
+	d, _ := deck.New(deck.Empty)
+	d.NumberOfDecks = t.numberOfDecks
+	for i := range t.cards {
+		card, err := t.Reveal(i)
+		if err != nil {
+			return nil, err
+		}
+		d.Cards = append(d.Cards, card)
+	}
+	return d, nil
+}
@@ -0,0 +1,153 @@
+package mentalpoker
+
+import (
+	"math/big"
+	"sort"
+	"testing"
+
+	"adamclerk/deck"
+	"github.com/stretchr/testify/assert"
+)
+
+// 2039 = 2*1019 + 1 is a safe prime big enough to tell 52 cards apart
+var smallPrime = big.NewInt(2039)
+
+func sortedSignature(d *deck.Deck) string {
+	cards := append([]deck.Card{}, d.Cards...)
+	sort.Slice(cards, func(i, j int) bool { return cards[i] < cards[j] })
+	sorted, _ := deck.New(deck.WithCards(cards...), deck.Unshuffled)
+	return sorted.GetSignature()
+}
+
+func TestShuffleKeepsEveryCard(t *testing.T) {
+	table, err := New(Parties("alice", "bob"))
+	assert.Nil(t, err)
+	assert.Nil(t, table.Shuffle())
+
+	d, err := table.Deck()
+	assert.Nil(t, err)
+	unshuffled, _ := deck.New(deck.Unshuffled)
+	assert.Equal(t, 52, d.NumberOfCards())
+	assert.Equal(t, sortedSignature(unshuffled), sortedSignature(d))
+	assert.NotEqual(t, unshuffled.GetSignature(), d.GetSignature())
+}
+
+func TestShuffleShoe(t *testing.T) {
+	table, _ := New(Prime(smallPrime), WithDeck(deck.Decks(2), deck.Faces(deck.ACE, deck.KING)))
+	table.Shuffle()
+	d, err := table.Deck()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, d.NumberOfDecks)
+	assert.Equal(t, 16, d.NumberOfCards())
+}
+
+func TestCiphertextsHideCards(t *testing.T) {
+	table, _ := New(Prime(smallPrime))
+	plain := map[string]bool{}
+	for _, c := range table.cards {
+		plain[c.String()] = true
+	}
+	table.Shuffle()
+	hidden := 0
+	for _, c := range table.cards {
+		if !plain[c.String()] {
+			hidden++
+		}
+	}
+	assert.True(t, hidden > 40)
+}
+
+func TestDealPrivately(t *testing.T) {
+	table, _ := New(Prime(smallPrime), Parties("alice", "bob"))
+	table.Shuffle()
+	hands, err := table.Deal(2)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, hands[0].NumberOfCards())
+	assert.Equal(t, 2, hands[1].NumberOfCards())
+
+	// alice's first card was dealt at position 0 and matches the public reveal
+	card, _ := table.Reveal(0)
+	assert.Equal(t, hands[0].Cards[0], card)
+	card, _ = table.Reveal(1)
+	assert.Equal(t, hands[1].Cards[0], card)
+}
+
+func TestRevealRequiresEveryShare(t *testing.T) {
+	table, _ := New(Prime(smallPrime), Parties("alice", "bob"))
+	table.Shuffle()
+	card, _ := table.Reveal(5)
+
+	m := table.cards[5]
+	share, _ := table.Parties()[0].KeyShare(5)
+	partial := table.Parties()[0].unlock(m, share)
+	assert.NotEqual(t, table.encode(card).String(), partial.String())
+
+	share, _ = table.Parties()[1].KeyShare(5)
+	full := table.Parties()[1].unlock(partial, share)
+	assert.Equal(t, table.encode(card).String(), full.String())
+}
+
+func TestRevealBeforeShuffle(t *testing.T) {
+	table, _ := New(Prime(smallPrime))
+	_, err := table.Reveal(0)
+	assert.Equal(t, "No key for card", err.Error())
+	table.Shuffle()
+	_, err = table.Reveal(52)
+	assert.Equal(t, "Card index out of range", err.Error())
+}
+
+func TestDealTooManyCards(t *testing.T) {
+	table, _ := New(Prime(smallPrime), WithDeck(deck.Faces(deck.ACE)))
+	table.Shuffle()
+	_, err := table.Deal(3)
+	assert.Equal(t, "Not enough cards", err.Error())
+	// nothing was revealed, the cards can still be dealt
+	hands, err := table.Deal(2)
+	assert.Nil(t, err)
+	assert.Len(t, hands[1].Cards, 2)
+}
+
+func TestShuffleTwice(t *testing.T) {
+	table, _ := New(Prime(smallPrime))
+	assert.Nil(t, table.Shuffle())
+	err := table.Shuffle()
+	assert.Equal(t, "The deck is already shuffled", err.Error())
+	d, err := table.Deck()
+	assert.Nil(t, err)
+	assert.Len(t, d.Cards, 52)
+}
+
+func TestNoParties(t *testing.T) {
+	_, err := New(Parties())
+	assert.Equal(t, "At least one party is needed", err.Error())
+}
+
+func TestSafePrime(t *testing.T) {
+	// 2029 is prime but 1014 isn't, and 2041 = 13 * 157
+	for _, p := range []*big.Int{big.NewInt(2029), big.NewInt(2041), big.NewInt(4), nil} {
+		_, err := New(Prime(p))
+		if assert.NotNil(t, err, p) {
+			assert.Equal(t, "The prime must be a safe prime", err.Error())
+		}
+	}
+	_, err := New(Prime(smallPrime))
+	assert.Nil(t, err)
+	assert.True(t, safePrime(RFC3526Prime))
+}
+
+func TestPrimeTooSmall(t *testing.T) {
+	// modulo 83, (c+2)^2 and (81-c)^2 are the same; modulo 5, (3+2)^2 is 0
+	for _, p := range []int64{83, 5} {
+		_, err := New(Prime(big.NewInt(p)))
+		if assert.NotNil(t, err, p) {
+			assert.Equal(t, "The prime is too small to tell the cards apart", err.Error())
+		}
+	}
+	// a small deck is fine with a small prime
+	table, err := New(Prime(big.NewInt(23)), WithDeck(deck.Faces(deck.ACE)))
+	assert.Nil(t, err)
+	table.Shuffle()
+	d, _ := table.Deck()
+	aces, _ := deck.New(deck.Faces(deck.ACE), deck.Unshuffled)
+	assert.Equal(t, aces.GetSignature(), sortedSignature(d))
+}
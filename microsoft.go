@@ -0,0 +1,31 @@
+package deck
+
+// MicrosoftDeal is a functional option used to create the deck of a numbered Microsoft FreeCell deal.
+// It reproduces the deal of the classic Windows game exactly, from the same linear congruential generator:
+// the cards come in the order they are dealt, across eight columns, a row at a time.
+// The classic deals are numbered 1 to 32000; other numbers follow the same generator.
+// The deck isn't shuffled again.
+func MicrosoftDeal(number int) func(*Options) {
+	return func(o *Options) {
+		o.Cards = microsoftDeal(uint32(number))
+		o.Shuffled = false
+	}
+}
+
+// microsoftDeal deals like the Microsoft C runtime's rand seeded with the deal number: it picks each card
+// from the ones left, ace of clubs to king of spades, and fills the gap with the last of them
+func microsoftDeal(seed uint32) []Card {
+	left := make([]Card, 52)
+	for i := range left {
+		left[i] = Card(i)
+	}
+	cards := make([]Card, 52)
+	for i := range cards {
+		seed = (seed*214013 + 2531011) & 0x7fffffff
+		j := int(seed>>16) % len(left)
+		cards[i] = left[j]
+		left[j] = left[len(left)-1]
+		left = left[:len(left)-1]
+	}
+	return cards
+}
@@ -0,0 +1,50 @@
+package deck
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rows lays the deck out the way FreeCell deals it, eight cards a row
+func rows(d *Deck) string {
+	lines := []string{}
+	for i := 0; i < len(d.Cards); i += 8 {
+		line := []string{}
+		for j := i; j < i+8 && j < len(d.Cards); j++ {
+			line = append(line, d.Cards[j].String())
+		}
+		lines = append(lines, strings.Join(line, " "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestMicrosoftDeal(t *testing.T) {
+	deal1, _ := New(MicrosoftDeal(1))
+	assert.Equal(t, strings.Join([]string{
+		"J♦ 2♦ 9♥ J♣ 5♦ 7♥ 7♣ 5♥",
+		"K♦ K♣ 9♠ 5♠ A♦ Q♣ K♥ 3♥",
+		"2♠ K♠ 9♦ Q♦ J♠ A♠ A♥ 3♣",
+		"4♣ 5♣ T♠ Q♥ 4♥ A♣ 4♦ 7♠",
+		"3♠ T♦ 4♠ T♥ 8♥ 2♣ J♥ 7♦",
+		"6♦ 8♠ 8♦ Q♠ 6♣ 3♦ 8♣ T♣",
+		"6♠ 9♣ 2♥ 6♥",
+	}, "\n"), rows(deal1))
+
+	deal617, _ := New(MicrosoftDeal(617))
+	assert.Equal(t, strings.Join([]string{
+		"7♦ A♦ 5♣ 3♠ 5♠ 8♣ 2♦ A♥",
+		"T♦ 7♠ Q♦ A♣ 6♦ 8♥ A♠ K♥",
+		"T♥ Q♣ 3♥ 9♦ 6♠ 8♦ 3♦ T♣",
+		"K♦ 5♥ 9♠ 3♣ 8♠ 7♥ 4♦ J♠",
+		"4♣ Q♠ 9♣ 9♥ 7♣ 6♥ 2♣ 2♠",
+		"4♠ T♠ 2♥ 5♦ J♣ 6♣ J♥ Q♥",
+		"J♦ K♠ K♣ 4♥",
+	}, "\n"), rows(deal617))
+
+	// a deal is the same every time, and ignores options that would shuffle it
+	again, _ := New(MicrosoftDeal(617), FromSeed("617"))
+	assert.Equal(t, deal617.GetSignature(), again.GetSignature())
+	assert.Len(t, again.Cards, 52)
+}
@@ -0,0 +1,339 @@
+package poker
+
+import (
+	"errors"
+	"math/big"
+	"math/rand"
+	"runtime"
+	"sync"
+
+	"adamclerk/deck"
+	"adamclerk/deck/internal/splitmix"
+)
+
+// EquityOptions is the struct used to describe how equity should be calculated
+type EquityOptions struct {
+	Board           []deck.Card // the board cards already dealt
+	Dead            []deck.Card // cards known to be out of play
+	ExhaustiveLimit int         // enumerate every board when there are at most this many
+	Trials          int         // number of boards dealt by Monte Carlo otherwise
+	Workers         int         // number of goroutines
+	Seed            int64       // seed for the Monte Carlo boards
+}
+
+// Equity is one player's share of the pot over all boards
+type Equity struct {
+	Win    float64 // fraction of boards won outright
+	Tie    float64 // fraction of boards split
+	Equity float64 // expected share of the pot
+}
+
+// EquityResult is the result of CalculateEquity
+type EquityResult struct {
+	Players    []Equity // in the same order as the hole cards given
+	Boards     int      // number of boards evaluated
+	Exhaustive bool     // every possible board was evaluated
+}
+
+// Board is a functional option used to give the board cards already dealt
+func Board(cards ...deck.Card) func(*EquityOptions) {
+	return func(o *EquityOptions) {
+		o.Board = cards
+	}
+}
+
+// Dead is a functional option used to remove cards from the stub, like folded or burned cards
+func Dead(cards ...deck.Card) func(*EquityOptions) {
+	return func(o *EquityOptions) {
+		o.Dead = cards
+	}
+}
+
+// ExhaustiveLimit is a functional option used to set the number of possible boards up to which every board is evaluated
+func ExhaustiveLimit(boards int) func(*EquityOptions) {
+	return func(o *EquityOptions) {
+		o.ExhaustiveLimit = boards
+	}
+}
+
+// Trials is a functional option used to set the number of boards dealt when using Monte Carlo, at least one
+func Trials(boards int) func(*EquityOptions) {
+	return func(o *EquityOptions) {
+		o.Trials = boards
+	}
+}
+
+// Workers is a functional option used to set the number of goroutines sharing the work
+func Workers(count int) func(*EquityOptions) {
+	return func(o *EquityOptions) {
+		o.Workers = count
+	}
+}
+
+// Seed is a functional option used to seed the Monte Carlo boards.
+// The same seed gives the same result whatever the number of workers.
+func Seed(seed int64) func(*EquityOptions) {
+	return func(o *EquityOptions) {
+		o.Seed = seed
+	}
+}
+
+// trialsPerChunk is how many Monte Carlo boards share one seeded source.
+// Chunks, not workers, own the randomness so the result doesn't depend on the number of workers.
+const trialsPerChunk = 10000
+
+// CalculateEquity calculates each Hold'em player's chance of winning from their two hole cards.
+// The missing board cards come from a deck.New deck minus the hole, board and dead cards.
+// Every board is evaluated when there are at most ExhaustiveLimit of them, otherwise
+// Trials random boards are dealt.
+func CalculateEquity(holes [][]deck.Card, options ...func(*EquityOptions)) (*EquityResult, error) {
+	if len(holes) < 2 {
+		return nil, errors.New("At least two players are needed")
+	}
+	opt, known, err := newEquityOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	players := make([]Hand, len(holes))
+	for i, hole := range holes {
+		if len(hole) != 2 {
+			return nil, errors.New("Every player needs two hole cards")
+		}
+		if known, err = addCards(known, hole); err != nil {
+			return nil, err
+		}
+		players[i] = NewHand(hole...)
+	}
+
+	stub := stubOf(known)
+	missing := 5 - len(opt.Board)
+	if missing > len(stub) {
+		return nil, errors.New("Not enough cards left to complete the board")
+	}
+
+	e := &equityCounter{players: players, board: NewHand(opt.Board...), total: newTally(len(players))}
+	boards := new(big.Int).Binomial(int64(len(stub)), int64(missing))
+	exhaustive := boards.IsInt64() && boards.Int64() <= int64(opt.ExhaustiveLimit)
+	if exhaustive {
+		e.exhaustive(stub, missing, opt.Workers)
+	} else {
+		e.monteCarlo(stub, missing, opt.Trials, opt.Workers, opt.Seed)
+	}
+	return e.total.result(exhaustive), nil
+}
+
+// newEquityOptions applies the options and returns the board and dead cards as a hand
+func newEquityOptions(options []func(*EquityOptions)) (EquityOptions, Hand, error) {
+	opt := EquityOptions{ExhaustiveLimit: 2000000, Trials: 200000, Workers: runtime.NumCPU(), Seed: 1}
+	for _, option := range options {
+		option(&opt)
+	}
+	if len(opt.Board) > 5 {
+		return opt, 0, errors.New("The board has at most 5 cards")
+	}
+	if opt.Trials < 1 {
+		return opt, 0, errors.New("Invalid number of trials")
+	}
+	if opt.Workers < 1 {
+		opt.Workers = 1
+	}
+	known, err := addCards(0, opt.Board)
+	if err != nil {
+		return opt, 0, err
+	}
+	known, err = addCards(known, opt.Dead)
+	return opt, known, err
+}
+
+// addCards adds cards to the known cards, failing if one is already known
+func addCards(known Hand, cards []deck.Card) (Hand, error) {
+	for _, c := range cards {
+		if known.Contains(c) {
+			return known, errors.New("Card used twice: " + c.String())
+		}
+		known = known.Add(c)
+	}
+	return known, nil
+}
+
+// stubOf returns the cards of a deck.New deck that aren't known, one per hand
+func stubOf(known Hand) []Hand {
+	full, _ := deck.New(deck.Unshuffled)
+	var stub []Hand
+	for _, c := range full.Cards {
+		if !known.Contains(c) {
+			stub = append(stub, NewHand(c))
+		}
+	}
+	return stub
+}
+
+// equityCounter evaluates boards for the players, adding every worker's tally to the total
+type equityCounter struct {
+	players []Hand
+	board   Hand
+
+	mu    sync.Mutex
+	total *tally
+}
+
+// tally counts wins and ties. Ties are counted by the number of players splitting
+// so that the totals are exact integers, whatever order the tallies are added in.
+type tally struct {
+	boards int
+	wins   []int
+	ties   [][]int // ties[player][players splitting]
+	values []int
+}
+
+func newTally(players int) *tally {
+	t := &tally{wins: make([]int, players), ties: make([][]int, players), values: make([]int, players)}
+	for i := range t.ties {
+		t.ties[i] = make([]int, players+1)
+	}
+	return t
+}
+
+func (e *equityCounter) newTally() *tally {
+	return newTally(len(e.players))
+}
+
+// showdown evaluates one complete board
+func showdown(t *tally, players []Hand, board Hand) {
+	best, winners := -1, 0
+	for i, p := range players {
+		v := (p | board).Value()
+		t.values[i] = v
+		if v > best {
+			best, winners = v, 1
+		} else if v == best {
+			winners++
+		}
+	}
+	t.boards++
+	for i, v := range t.values {
+		if v != best {
+			continue
+		}
+		if winners == 1 {
+			t.wins[i]++
+		} else {
+			t.ties[i][winners]++
+		}
+	}
+}
+
+func (e *equityCounter) add(t *tally) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.total.add(t)
+}
+
+// exhaustive evaluates every board. Each task fixes the first missing card.
+func (e *equityCounter) exhaustive(stub []Hand, missing, workers int) {
+	if missing == 0 {
+		t := e.newTally()
+		showdown(t, e.players, e.board)
+		e.add(t)
+		return
+	}
+	tasks := make(chan int, len(stub))
+	for i := 0; i+missing <= len(stub); i++ {
+		tasks <- i
+	}
+	close(tasks)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			t := e.newTally()
+			for first := range tasks {
+				enumerate(t, e.players, stub, first+1, missing-1, e.board|stub[first])
+			}
+			e.add(t)
+		}()
+	}
+	wg.Wait()
+}
+
+// enumerate evaluates every board made by adding missing cards from stub[start:]
+func enumerate(t *tally, players []Hand, stub []Hand, start, missing int, board Hand) {
+	if missing == 0 {
+		showdown(t, players, board)
+		return
+	}
+	for i := start; i+missing <= len(stub); i++ {
+		enumerate(t, players, stub, i+1, missing-1, board|stub[i])
+	}
+}
+
+// monteCarlo deals random boards, in chunks that each have their own seeded source
+func (e *equityCounter) monteCarlo(stub []Hand, missing, trials, workers int, seed int64) {
+	chunks := make(chan int, trials/trialsPerChunk+1)
+	for c := 0; c*trialsPerChunk < trials; c++ {
+		chunks <- c
+	}
+	close(chunks)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			t := e.newTally()
+			cards := make([]Hand, len(stub))
+			for c := range chunks {
+				r := rand.New(rand.NewSource(splitmix.Mix(seed, c)))
+				copy(cards, stub)
+				n := trialsPerChunk
+				if rest := trials - c*trialsPerChunk; rest < n {
+					n = rest
+				}
+				for i := 0; i < n; i++ {
+					board := e.board
+					// a partial Knuth shuffle picks the missing cards
+					for j := 0; j < missing; j++ {
+						k := j + r.Intn(len(cards)-j)
+						cards[j], cards[k] = cards[k], cards[j]
+						board |= cards[j]
+					}
+					showdown(t, e.players, board)
+				}
+			}
+			e.add(t)
+		}()
+	}
+	wg.Wait()
+}
+
+func (t *tally) add(other *tally) {
+	t.boards += other.boards
+	for i := range other.wins {
+		t.wins[i] += other.wins[i]
+		for k := range other.ties[i] {
+			t.ties[i][k] += other.ties[i][k]
+		}
+	}
+}
+
+func (t *tally) result(exhaustive bool) *EquityResult {
+	result := &EquityResult{Players: make([]Equity, len(t.wins)), Boards: t.boards, Exhaustive: exhaustive}
+	total := float64(t.boards)
+	for i := range result.Players {
+		ties, shares := 0, 0.0
+		for k, n := range t.ties[i] {
+			if n > 0 {
+				ties += n
+				shares += float64(n) / float64(k)
+			}
+		}
+		result.Players[i] = Equity{
+			Win:    float64(t.wins[i]) / total,
+			Tie:    float64(ties) / total,
+			Equity: (float64(t.wins[i]) + shares) / total,
+		}
+	}
+	return result
+}
@@ -0,0 +1,87 @@
+package poker
+
+import (
+	"math"
+	"testing"
+
+	"adamclerk/deck"
+	"adamclerk/deck/internal/decktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEquityOnTheTurn(t *testing.T) {
+	result, err := CalculateEquity([][]deck.Card{decktest.Cards("Ah Kh"), decktest.Cards("Qc Qd")}, Board(decktest.Cards("2h 7h Tc 3s")...))
+	assert.Nil(t, err)
+	assert.True(t, result.Exhaustive)
+	assert.Equal(t, 44, result.Boards)
+	// nine hearts, three aces and three kings
+	assert.InDelta(t, 15.0/44, result.Players[0].Win, 1e-9)
+	assert.InDelta(t, 29.0/44, result.Players[1].Equity, 1e-9)
+	assert.Equal(t, 0.0, result.Players[0].Tie)
+}
+
+func TestEquityWithDeadCards(t *testing.T) {
+	result, _ := CalculateEquity([][]deck.Card{decktest.Cards("Ah Kh"), decktest.Cards("Qc Qd")},
+		Board(decktest.Cards("2h 7h Tc 3s")...), Dead(decktest.Cards("9h 8h")...))
+	assert.Equal(t, 42, result.Boards)
+	assert.InDelta(t, 13.0/42, result.Players[0].Win, 1e-9)
+}
+
+func TestEquitySplitPot(t *testing.T) {
+	// the board plays for everyone
+	result, _ := CalculateEquity([][]deck.Card{decktest.Cards("2c 3d"), decktest.Cards("2d 3c"), decktest.Cards("2h 3h")},
+		Board(decktest.Cards("Ts Js Qs Ks As")...))
+	assert.Equal(t, 1, result.Boards)
+	for _, p := range result.Players {
+		assert.Equal(t, 1.0, p.Tie)
+		assert.InDelta(t, 1.0/3, p.Equity, 1e-9)
+	}
+}
+
+func TestEquityPreflopExhaustive(t *testing.T) {
+	if testing.Short() {
+		t.Skip("enumerates 1,712,304 boards")
+	}
+	result, _ := CalculateEquity([][]deck.Card{decktest.Cards("Ah As"), decktest.Cards("Kd Kc")})
+	assert.True(t, result.Exhaustive)
+	assert.Equal(t, 1712304, result.Boards)
+	assert.InDelta(t, 0.82, result.Players[0].Equity, 0.01)
+	assert.InDelta(t, 1, result.Players[0].Equity+result.Players[1].Equity, 1e-9)
+}
+
+func TestEquityMonteCarloIsDeterministic(t *testing.T) {
+	holes := [][]deck.Card{decktest.Cards("Ah As"), decktest.Cards("Kd Kc"), decktest.Cards("7s 8s")}
+	one, _ := CalculateEquity(holes, ExhaustiveLimit(0), Trials(50000), Workers(1), Seed(7))
+	four, _ := CalculateEquity(holes, ExhaustiveLimit(0), Trials(50000), Workers(4), Seed(7))
+	other, _ := CalculateEquity(holes, ExhaustiveLimit(0), Trials(50000), Workers(4), Seed(8))
+	assert.False(t, one.Exhaustive)
+	assert.Equal(t, 50000, one.Boards)
+	assert.Equal(t, one, four)
+	assert.NotEqual(t, one, other)
+	assert.InDelta(t, other.Players[0].Equity, one.Players[0].Equity, 0.02)
+}
+
+func TestEquityAdjacentSeedsAreIndependent(t *testing.T) {
+	holes := [][]deck.Card{decktest.Cards("Ah As"), decktest.Cards("Kd Kc")}
+	both, _ := CalculateEquity(holes, ExhaustiveLimit(0), Trials(2*trialsPerChunk), Seed(7))
+	first, _ := CalculateEquity(holes, ExhaustiveLimit(0), Trials(trialsPerChunk), Seed(7))
+	next, _ := CalculateEquity(holes, ExhaustiveLimit(0), Trials(trialsPerChunk), Seed(8))
+	// the second chunk of seed 7 isn't the first one of seed 8
+	second := math.Round(both.Players[0].Win*2*trialsPerChunk - first.Players[0].Win*trialsPerChunk)
+	assert.NotEqual(t, second, math.Round(next.Players[0].Win*trialsPerChunk))
+}
+
+func TestEquityErrors(t *testing.T) {
+	_, err := CalculateEquity([][]deck.Card{decktest.Cards("Ah As")})
+	assert.Equal(t, "At least two players are needed", err.Error())
+	_, err = CalculateEquity([][]deck.Card{decktest.Cards("Ah As"), decktest.Cards("Ah Kc")})
+	assert.Equal(t, "Card used twice: A♥", err.Error())
+	_, err = CalculateEquity([][]deck.Card{decktest.Cards("Ah As"), decktest.Cards("Kc")})
+	assert.Equal(t, "Every player needs two hole cards", err.Error())
+	_, err = CalculateEquity([][]deck.Card{decktest.Cards("Ah As"), decktest.Cards("Kc Kd")}, Board(decktest.Cards("2c 3c 4c 5c 6c 7c")...))
+	assert.Equal(t, "The board has at most 5 cards", err.Error())
+	for _, trials := range []int{0, -5} {
+		_, err = CalculateEquity([][]deck.Card{decktest.Cards("Ah As"), decktest.Cards("Kc Kd")}, Trials(trials), ExhaustiveLimit(0))
+		assert.Equal(t, "Invalid number of trials", err.Error())
+	}
+}
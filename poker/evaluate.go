@@ -0,0 +1,149 @@
+package poker
+
+import (
+	"adamclerk/deck"
+)
+
+// Evaluate returns the value of the best five card poker hand that can be made from cards.
+// With more than five cards every five card combination is tried.
+// With fewer than five cards only pairs, trips and quads count, as when comparing
+// exposed cards in stud.
+func Evaluate(cards []deck.Card) HandValue {
+	if len(cards) <= 5 {
+		return evaluate(cards)
+	}
+	best := HandValue{Value: -1}
+	hand := make([]deck.Card, 5)
+	combinations(len(cards), 5, func(index []int) {
+		for i, j := range index {
+			hand[i] = cards[j]
+		}
+		if v := evaluate(hand); v.Value > best.Value {
+			best = v
+		}
+	})
+	return best
+}
+
+// combinations calls fn with every k sized subset of 0..n-1, in lexicographic order
+func combinations(n, k int, fn func(index []int)) {
+	index := make([]int, k)
+	for i := range index {
+		index[i] = i
+	}
+	for {
+		fn(index)
+		i := k - 1
+		for i >= 0 && index[i] == n-k+i {
+			i--
+		}
+		if i < 0 {
+			return
+		}
+		index[i]++
+		for j := i + 1; j < k; j++ {
+			index[j] = index[j-1] + 1
+		}
+	}
+}
+
+// evaluate scores up to five cards
+func evaluate(cards []deck.Card) HandValue {
+	return evaluateHigh(cards, straightHigh)
+}
+
+// evaluateHigh scores up to five cards, finding straights with the given function
+func evaluateHigh(cards []deck.Card, straights func(ranks int) int) HandValue {
+	if len(cards) == 0 {
+		return HandValue{}
+	}
+	var counts [13]int
+	ranks := 0
+	flush := len(cards) == 5
+	for _, c := range cards {
+		r := rank(c)
+		counts[r]++
+		ranks |= 1 << uint(r)
+		if c.Suit() != cards[0].Suit() {
+			flush = false
+		}
+	}
+
+	// order the ranks by how often they appear, then by rank
+	order := make([]int, 0, len(cards))
+	for n := 4; n >= 1; n-- {
+		for r := 12; r >= 0; r-- {
+			if counts[r] == n {
+				order = append(order, r)
+			}
+		}
+	}
+
+	var category Category
+	straight := -1
+	if len(cards) == 5 && len(order) == 5 {
+		straight = straights(ranks)
+	}
+	switch {
+	case straight >= 0 && flush:
+		category = StraightFlush
+	case counts[order[0]] == 4:
+		category = FourOfAKind
+	case counts[order[0]] == 3 && len(order) > 1 && counts[order[1]] == 2:
+		category = FullHouse
+	case flush:
+		category = Flush
+	case straight >= 0:
+		category = Straight
+	case counts[order[0]] == 3:
+		category = ThreeOfAKind
+	case counts[order[0]] == 2 && len(order) > 1 && counts[order[1]] == 2:
+		category = TwoPair
+	case counts[order[0]] == 2:
+		category = OnePair
+	default:
+		category = HighCard
+	}
+	if straight >= 0 {
+		order = []int{straight}
+	}
+	return newHandValue(category, order)
+}
+
+// newHandValue packs the category and up to five ranks into a single ordered value
+func newHandValue(category Category, ranks []int) HandValue {
+	value := int(category)
+	kickers := make([]deck.Face, len(ranks))
+	for i := 0; i < 5; i++ {
+		value <<= 4
+		if i < len(ranks) {
+			value |= ranks[i] + 1
+			kickers[i] = face(ranks[i])
+		}
+	}
+	return HandValue{Category: category, Kickers: kickers, Value: value}
+}
+
+// straightHigh returns the rank of the highest card of a straight in the rank mask, or -1.
+// The ace also plays low in the wheel, a straight to the five.
+func straightHigh(ranks int) int {
+	if high := straightHighNoWheel(ranks); high >= 0 {
+		return high
+	}
+	const wheel = 1<<12 | 0xf
+	if ranks&wheel == wheel {
+		return 3
+	}
+	return -1
+}
+
+// straightHighNoWheel is straightHigh with the ace only playing high
+func straightHighNoWheel(ranks int) int {
+	for high := 12; high >= 4; high-- {
+		run := 0x1f << uint(high-4)
+		if ranks&run == run {
+			return high
+		}
+	}
+	return -1
+}
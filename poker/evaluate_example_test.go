@@ -0,0 +1,32 @@
+package poker
+
+import (
+	"fmt"
+
+	"adamclerk/deck"
+)
+
+func ExampleEvaluate() {
+	wheel := Evaluate([]deck.Card{
+		deck.NewCard(deck.ACE, deck.HEART),
+		deck.NewCard(deck.TWO, deck.CLUB),
+		deck.NewCard(deck.THREE, deck.DIAMOND),
+		deck.NewCard(deck.FOUR, deck.SPADE),
+		deck.NewCard(deck.FIVE, deck.HEART),
+	})
+	aces := Evaluate([]deck.Card{
+		deck.NewCard(deck.ACE, deck.HEART),
+		deck.NewCard(deck.ACE, deck.CLUB),
+		deck.NewCard(deck.KING, deck.DIAMOND),
+		deck.NewCard(deck.QUEEN, deck.SPADE),
+		deck.NewCard(deck.JACK, deck.HEART),
+	})
+
+	fmt.Println(wheel)
+	fmt.Println(aces)
+	fmt.Printf("Compare result is %d\n", Compare(wheel, aces))
+	// Output:
+	// Straight (5)
+	// One Pair (A K Q J)
+	// Compare result is 1
+}
@@ -0,0 +1,97 @@
+package poker
+
+import (
+	"testing"
+
+	"adamclerk/deck"
+	"adamclerk/deck/internal/decktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func cards(sig string) []deck.Card {
+	d, _ := deck.New(deck.FromSignature(sig), deck.Unshuffled)
+	return d.Cards
+}
+
+func TestEvaluateAllFiveCardHands(t *testing.T) {
+	full, _ := deck.New(deck.Unshuffled)
+	counts := map[Category]int{}
+	values := map[int]bool{}
+	hand := make([]deck.Card, 5)
+	combinations(52, 5, func(index []int) {
+		for i, j := range index {
+			hand[i] = full.Cards[j]
+		}
+		v := evaluate(hand)
+		counts[v.Category]++
+		values[v.Value] = true
+	})
+	assert.Equal(t, map[Category]int{
+		StraightFlush: 40,
+		FourOfAKind:   624,
+		FullHouse:     3744,
+		Flush:         5108,
+		Straight:      10200,
+		ThreeOfAKind:  54912,
+		TwoPair:       123552,
+		OnePair:       1098240,
+		HighCard:      1302540,
+	}, counts)
+	// the number of distinct five card hand values
+	assert.Equal(t, 7462, len(values))
+}
+
+func TestEvaluateWheel(t *testing.T) {
+	wheel := Evaluate(decktest.Cards("Ah 2c 3d 4s 5h"))
+	assert.Equal(t, Straight, wheel.Category)
+	assert.Equal(t, []deck.Face{deck.FIVE}, wheel.Kickers)
+
+	six := Evaluate(decktest.Cards("2c 3d 4s 5h 6h"))
+	broadway := Evaluate(decktest.Cards("Tc Jd Qs Kh Ah"))
+	assert.True(t, Compare(six, wheel).IsGreaterThan())
+	assert.True(t, Compare(broadway, six).IsGreaterThan())
+	assert.Equal(t, []deck.Face{deck.ACE}, broadway.Kickers)
+
+	steelWheel := Evaluate(decktest.Cards("As 2s 3s 4s 5s"))
+	assert.Equal(t, StraightFlush, steelWheel.Category)
+	assert.Equal(t, HighCard, Evaluate(decktest.Cards("Qs Ks As 2d 3s")).Category)
+}
+
+func TestEvaluateKickers(t *testing.T) {
+	v := Evaluate(decktest.Cards("Kh Kd 5s Qc Qh"))
+	assert.Equal(t, TwoPair, v.Category)
+	assert.Equal(t, []deck.Face{deck.KING, deck.QUEEN, deck.FIVE}, v.Kickers)
+	assert.Equal(t, "Two Pair (K Q 5)", v.String())
+
+	v = Evaluate(decktest.Cards("Ah Ad 2s 3c 4h"))
+	assert.Equal(t, OnePair, v.Category)
+	assert.Equal(t, []deck.Face{deck.ACE, deck.FOUR, deck.THREE, deck.TWO}, v.Kickers)
+
+	acesWithKing := Evaluate(decktest.Cards("Ah Ad Ks 3c 2h"))
+	acesWithQueen := Evaluate(decktest.Cards("As Ac Qs Jc Th"))
+	assert.True(t, Compare(acesWithKing, acesWithQueen).IsGreaterThan())
+	assert.True(t, Compare(Evaluate(decktest.Cards("Ah Kd 9s 5c 3h")), Evaluate(decktest.Cards("As Kc 9d 5d 3c"))).IsEqualTo())
+}
+
+func TestEvaluateSevenCards(t *testing.T) {
+	v := Evaluate(decktest.Cards("Ah Kh Qh Jh 2c 2d Th"))
+	assert.Equal(t, StraightFlush, v.Category)
+	assert.Equal(t, []deck.Face{deck.ACE}, v.Kickers)
+
+	v = Evaluate(decktest.Cards("5c 5d 5h 9s 9c 9d 2h"))
+	assert.Equal(t, FullHouse, v.Category)
+	assert.Equal(t, []deck.Face{deck.NINE, deck.FIVE}, v.Kickers)
+}
+
+func TestEvaluatePartialHands(t *testing.T) {
+	assert.Equal(t, HandValue{}, Evaluate(nil))
+	assert.Equal(t, OnePair, Evaluate(decktest.Cards("7c 7d")).Category)
+	assert.Equal(t, HighCard, Evaluate(decktest.Cards("2c 3c 4c 5c")).Category)
+	assert.True(t, Compare(Evaluate(decktest.Cards("Ac")), Evaluate(decktest.Cards("Kc"))).IsGreaterThan())
+	assert.Equal(t, FourOfAKind, Evaluate(cards("00010203")).Category)
+}
+
+func TestCategoryString(t *testing.T) {
+	assert.Equal(t, "Straight Flush", StraightFlush.String())
+	assert.Equal(t, "Category(9)", Category(9).String())
+}
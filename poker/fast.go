@@ -0,0 +1,163 @@
+package poker
+
+import (
+	"math/bits"
+
+	"adamclerk/deck"
+)
+
+// Hand is a set of distinct cards stored as a bitmask, 16 bits per suit with one bit per rank.
+// Its Value is computed without allocating, for simulations that evaluate millions of hands.
+type Hand uint64
+
+// Tables indexed by a 13 bit rank mask
+var (
+	straightTable [1 << 13]int8  // the high rank of the best straight, or -1
+	topFiveTable  [1 << 13]int32 // the five highest ranks, plus one, packed 4 bits each
+)
+
+func init() {
+	for mask := range straightTable {
+		straightTable[mask] = int8(straightHigh(mask))
+		value, m := 0, mask
+		for i := 0; i < 5; i++ {
+			value <<= 4
+			if m != 0 {
+				r := bits.Len(uint(m)) - 1
+				m &^= 1 << uint(r)
+				value |= r + 1
+			}
+		}
+		topFiveTable[mask] = int32(value)
+	}
+}
+
+// NewHand creates a hand from cards. Cards must be distinct.
+func NewHand(cards ...deck.Card) Hand {
+	var h Hand
+	for _, c := range cards {
+		h = h.Add(c)
+	}
+	return h
+}
+
+// Add returns the hand with the card added
+func (h Hand) Add(c deck.Card) Hand {
+	return h | 1<<uint(c.Suit()*16+rank(c))
+}
+
+// Contains tells you if the card is in the hand
+func (h Hand) Contains(c deck.Card) bool {
+	return h&(1<<uint(c.Suit()*16+rank(c))) != 0
+}
+
+// NumberOfCards is a utility function that tells you how many cards are in the hand
+func (h Hand) NumberOfCards() int {
+	return bits.OnesCount64(uint64(h))
+}
+
+// Evaluate returns the full HandValue of the best five cards in the hand
+func (h Hand) Evaluate() HandValue {
+	if h == 0 {
+		return HandValue{}
+	}
+	value := h.Value()
+	category := Category(value >> 20)
+	var ranks []int
+	for shift := uint(16); ; shift -= 4 {
+		r := (value >> shift) & 0xf
+		if r == 0 {
+			break
+		}
+		ranks = append(ranks, r-1)
+		if shift == 0 {
+			break
+		}
+	}
+	return newHandValue(category, ranks)
+}
+
+// Value returns the same value as Evaluate(cards).Value for a hand of up to seven cards, much faster.
+// Ranks are counted with bit sliced adders over the four suits and straights
+// and kickers come from lookup tables, so nothing is allocated.
+// BenchmarkHandValue and BenchmarkEvaluateSevenCards compare the two.
+func (h Hand) Value() int {
+	s0 := int(h & 0x1fff)
+	s1 := int(h >> 16 & 0x1fff)
+	s2 := int(h >> 32 & 0x1fff)
+	s3 := int(h >> 48 & 0x1fff)
+
+	flush := -1
+	switch {
+	case bits.OnesCount(uint(s0)) >= 5:
+		flush = s0
+	case bits.OnesCount(uint(s1)) >= 5:
+		flush = s1
+	case bits.OnesCount(uint(s2)) >= 5:
+		flush = s2
+	case bits.OnesCount(uint(s3)) >= 5:
+		flush = s3
+	}
+	if flush >= 0 {
+		if high := straightTable[flush]; high >= 0 {
+			return int(StraightFlush)<<20 | (int(high)+1)<<16
+		}
+	}
+
+	// count every rank with bit sliced adders: ones, twos and fours hold the bits of the count
+	twos := s0 & s1
+	ones := s0 ^ s1
+	carry := ones & s2
+	ones ^= s2
+	fours := twos & carry
+	twos ^= carry
+	carry = ones & s3
+	ones ^= s3
+	fours |= twos & carry
+	twos ^= carry
+	ranks := s0 | s1 | s2 | s3
+	trips := ones & twos
+	pairs := twos &^ ones
+
+	if fours != 0 {
+		q := top(fours)
+		return int(FourOfAKind)<<20 | (q+1)<<16 | kickers(ranks&^(1<<uint(q)), 1, 12)
+	}
+	if trips != 0 {
+		t := top(trips)
+		if rest := (trips &^ (1 << uint(t))) | pairs; rest != 0 {
+			return int(FullHouse)<<20 | (t+1)<<16 | (top(rest)+1)<<12
+		}
+	}
+	if flush >= 0 {
+		return int(Flush)<<20 | kickers(flush, 5, 16)
+	}
+	if high := straightTable[ranks]; high >= 0 {
+		return int(Straight)<<20 | (int(high)+1)<<16
+	}
+	if trips != 0 {
+		t := top(trips)
+		return int(ThreeOfAKind)<<20 | (t+1)<<16 | kickers(ranks&^trips, 2, 12)
+	}
+	if pairs != 0 {
+		p1 := top(pairs)
+		rest := pairs &^ (1 << uint(p1))
+		if rest != 0 {
+			p2 := top(rest)
+			used := 1<<uint(p1) | 1<<uint(p2)
+			return int(TwoPair)<<20 | (p1+1)<<16 | (p2+1)<<12 | kickers(ranks&^used, 1, 8)
+		}
+		return int(OnePair)<<20 | (p1+1)<<16 | kickers(ranks&^pairs, 3, 12)
+	}
+	return int(HighCard)<<20 | kickers(ranks, 5, 16)
+}
+
+// top returns the highest rank in the mask
+func top(mask int) int {
+	return bits.Len(uint(mask)) - 1
+}
+
+// kickers packs the n highest ranks of the mask into 4 bit slots, the first one at shift
+func kickers(mask, n int, shift uint) int {
+	return int(topFiveTable[mask]) >> uint(20-4*n) << (shift - uint(4*(n-1)))
+}
@@ -0,0 +1,119 @@
+package poker
+
+import (
+	"math/rand"
+	"testing"
+
+	"adamclerk/deck"
+	"adamclerk/deck/internal/decktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandValueMatchesEvaluateOnFiveCards(t *testing.T) {
+	full, _ := deck.New(deck.Unshuffled)
+	hand := make([]deck.Card, 5)
+	mismatches := 0
+	combinations(52, 5, func(index []int) {
+		for i, j := range index {
+			hand[i] = full.Cards[j]
+		}
+		if NewHand(hand...).Value() != evaluate(hand).Value {
+			mismatches++
+		}
+	})
+	assert.Equal(t, 0, mismatches)
+}
+
+func TestHandValueMatchesBestOfTwentyOne(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	full, _ := deck.New(deck.Unshuffled)
+	for i := 0; i < 200000; i++ {
+		perm := r.Perm(52)
+		hand := make([]deck.Card, 7)
+		for j := range hand {
+			hand[j] = full.Cards[perm[j]]
+		}
+		expected := Evaluate(hand)
+		if !assert.Equal(t, expected.Value, NewHand(hand...).Value(), "%v", hand) {
+			return
+		}
+		assert.Equal(t, expected, NewHand(hand...).Evaluate())
+	}
+}
+
+func TestHandValueAllSevenCardHands(t *testing.T) {
+	if testing.Short() {
+		t.Skip("enumerates 133,784,560 hands")
+	}
+	var masks [52]Hand
+	for c := range masks {
+		masks[c] = NewHand(deck.Card(c))
+	}
+	var counts [9]int
+	for a := 0; a < 46; a++ {
+		ha := masks[a]
+		for b := a + 1; b < 47; b++ {
+			hb := ha | masks[b]
+			for c := b + 1; c < 48; c++ {
+				hc := hb | masks[c]
+				for d := c + 1; d < 49; d++ {
+					hd := hc | masks[d]
+					for e := d + 1; e < 50; e++ {
+						he := hd | masks[e]
+						for f := e + 1; f < 51; f++ {
+							hf := he | masks[f]
+							for g := f + 1; g < 52; g++ {
+								counts[(hf|masks[g]).Value()>>20]++
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	assert.Equal(t, [9]int{23294460, 58627800, 31433400, 6461620, 6180020, 4047644, 3473184, 224848, 41584}, counts)
+}
+
+func TestHandCards(t *testing.T) {
+	h := NewHand(decktest.Cards("As Kd")...)
+	assert.Equal(t, 2, h.NumberOfCards())
+	assert.True(t, h.Contains(deck.NewCard(deck.ACE, deck.SPADE)))
+	assert.False(t, h.Contains(deck.NewCard(deck.ACE, deck.DIAMOND)))
+}
+
+func TestHandEvaluate(t *testing.T) {
+	v := NewHand(decktest.Cards("Ah 2c 3d 4s 5h Kc Kd")...).Evaluate()
+	assert.Equal(t, Straight, v.Category)
+	assert.Equal(t, []deck.Face{deck.FIVE}, v.Kickers)
+	assert.Equal(t, HandValue{}, Hand(0).Evaluate())
+}
+
+func benchmarkHands(n int) []Hand {
+	r := rand.New(rand.NewSource(1))
+	hands := make([]Hand, n)
+	for i := range hands {
+		perm := r.Perm(52)
+		for _, c := range perm[:7] {
+			hands[i] = hands[i].Add(deck.Card(c))
+		}
+	}
+	return hands
+}
+
+func BenchmarkHandValue(b *testing.B) {
+	hands := benchmarkHands(1 << 16)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		hands[n&(1<<16-1)].Value()
+	}
+}
+
+func BenchmarkEvaluateSevenCards(b *testing.B) {
+	hand, _ := deck.New()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		Evaluate(hand.Cards[:7])
+	}
+}
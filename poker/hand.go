@@ -0,0 +1,91 @@
+// Package poker evaluates poker hands made of deck cards.
+package poker
+
+import (
+	"fmt"
+	"strings"
+
+	"adamclerk/deck"
+)
+
+// Category is the kind of poker hand, from HighCard up to StraightFlush
+type Category int
+
+// Constants for Category
+const (
+	HighCard Category = iota
+	OnePair
+	TwoPair
+	ThreeOfAKind
+	Straight
+	Flush
+	FullHouse
+	FourOfAKind
+	StraightFlush
+)
+
+var categoryNames = []string{
+	"High Card",
+	"One Pair",
+	"Two Pair",
+	"Three of a Kind",
+	"Straight",
+	"Flush",
+	"Full House",
+	"Four of a Kind",
+	"Straight Flush",
+}
+
+func (c Category) String() string {
+	if c < HighCard || c > StraightFlush {
+		return fmt.Sprintf("Category(%d)", int(c))
+	}
+	return categoryNames[c]
+}
+
+// HandValue is the result of evaluating a hand
+type HandValue struct {
+	Category Category
+	// Kickers are the faces that break ties within the category, most significant first.
+	// For One Pair that's the pair followed by the three kickers, for a straight only its high card.
+	// The wheel (A-2-3-4-5) is a straight to the five.
+	Kickers []deck.Face
+	// Value orders all hands: a higher Value is a better hand and equal values split the pot.
+	Value int
+}
+
+func (h HandValue) String() string {
+	faces := make([]string, len(h.Kickers))
+	for i, f := range h.Kickers {
+		faces[i] = faceName(f)
+	}
+	return fmt.Sprintf("%s (%s)", h.Category, strings.Join(faces, " "))
+}
+
+// Compare compares 2 hand values.
+// returns 1 if i is the better hand.
+// returns -1 if it's worse.
+// returns 0 if they split.
+func Compare(i, j HandValue) deck.CompareResult {
+	if i.Value > j.Value {
+		return 1
+	}
+	if i.Value < j.Value {
+		return -1
+	}
+	return 0
+}
+
+// rank converts a card's face to its poker rank: 0 for a deuce up to 12 for an ace
+func rank(c deck.Card) int {
+	return (c.Face() + 12) % 13
+}
+
+// face converts a poker rank back to a deck face
+func face(rank int) deck.Face {
+	return deck.Face((rank + 1) % 13)
+}
+
+func faceName(f deck.Face) string {
+	return string("A23456789TJQK"[int(f)%13])
+}
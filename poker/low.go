@@ -0,0 +1,278 @@
+package poker
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+
+	"adamclerk/deck"
+)
+
+// lowGame tells how a LowValue ranks its cards, for naming it
+type lowGame int
+
+const (
+	eightOrBetter lowGame = iota + 1
+	aceToFive
+	deuceToSeven
+	badugi
+)
+
+// LowValue is the result of evaluating a hand for low
+type LowValue struct {
+	// Category is HighCard for an unpaired low. Pairs and better count against a low;
+	// straights and flushes only do in deuce-to-seven.
+	Category Category
+	// Faces are the cards making the low, worst first, e.g. 8 6 4 2 A.
+	// Paired hands list the pairs first, as in HandValue.Kickers.
+	Faces []deck.Face
+	// Value orders the lows of one evaluator: a higher Value is a better low.
+	// The zero value is no low at all.
+	Value int
+	game  lowGame
+}
+
+// Qualified tells you if the hand made a low
+func (l LowValue) Qualified() bool {
+	return l.Value > 0
+}
+
+// String names the low the way players do, e.g. "7-5-4-3-2 number one" or "4-card badugi 4-3-2-A"
+func (l LowValue) String() string {
+	if !l.Qualified() {
+		return "No Low"
+	}
+	faces := make([]string, len(l.Faces))
+	for i, f := range l.Faces {
+		faces[i] = faceName(f)
+	}
+	switch {
+	case l.game == badugi:
+		return fmt.Sprintf("%d-card badugi %s", len(faces), strings.Join(faces, "-"))
+	case l.Category != HighCard:
+		return fmt.Sprintf("%s (%s)", l.Category, strings.Join(faces, " "))
+	case len(faces) == 5:
+		return fmt.Sprintf("%s number %s", strings.Join(faces, "-"), numberName(l.number()))
+	}
+	return strings.Join(faces, "-")
+}
+
+// number is the position of an unpaired low among the lows with the same top card, the best being number one
+func (l LowValue) number() int {
+	ranks := make([]int, len(l.Faces))
+	for i, f := range l.Faces {
+		ranks[i] = l.lowRank(f)
+	}
+	lowest := 1
+	if l.game == deuceToSeven {
+		lowest = 2
+	}
+	number := 1
+	combinations(ranks[0]-lowest, 4, func(index []int) {
+		// index is ascending, so walk it backwards to compare the highest cards first
+		other := []int{ranks[0]}
+		for i := 3; i >= 0; i-- {
+			other = append(other, index[i]+lowest)
+		}
+		if l.game == deuceToSeven && other[0]-other[4] == 4 {
+			return
+		}
+		for i := 1; i < 5; i++ {
+			if other[i] != ranks[i] {
+				if other[i] < ranks[i] {
+					number++
+				}
+				return
+			}
+		}
+	})
+	return number
+}
+
+// lowRank is the rank of a face in the low's game: aces are 1 except in deuce-to-seven where they are 14
+func (l LowValue) lowRank(f deck.Face) int {
+	if l.game == deuceToSeven {
+		return (int(f)+12)%13 + 2
+	}
+	return int(f) + 1
+}
+
+func numberName(n int) string {
+	names := []string{"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine", "ten"}
+	if n < len(names) {
+		return names[n]
+	}
+	return fmt.Sprint(n)
+}
+
+// CompareLow compares 2 lows.
+// returns 1 if i is the better low.
+// returns -1 if it's worse.
+// returns 0 if they split.
+func CompareLow(i, j LowValue) deck.CompareResult {
+	if i.Value > j.Value {
+		return 1
+	}
+	if i.Value < j.Value {
+		return -1
+	}
+	return 0
+}
+
+// aceLow converts a card's face to its rank with the ace low: 1 for an ace up to 13 for a king
+func aceLow(c deck.Card) int {
+	return c.Face() + 1
+}
+
+// EvaluateLow8 returns the best eight-or-better low that can be made from cards:
+// five cards of different ranks, eight or lower, with aces low and straights and flushes ignored.
+// It returns the zero LowValue when no low qualifies.
+func EvaluateLow8(cards []deck.Card) LowValue {
+	ranks := 0
+	for _, c := range cards {
+		if r := aceLow(c); r <= 8 {
+			ranks |= 1 << uint(r)
+		}
+	}
+	// the five lowest ranks make the best low
+	var low []int
+	for r := 1; r <= 8 && len(low) < 5; r++ {
+		if ranks&(1<<uint(r)) != 0 {
+			low = append(low, r)
+		}
+	}
+	if len(low) < 5 {
+		return LowValue{}
+	}
+	faces := make([]deck.Face, 5)
+	packed := 0
+	for i := 4; i >= 0; i-- {
+		faces[4-i] = deck.Face(low[i] - 1)
+		packed = packed<<4 | low[i]
+	}
+	return LowValue{Category: HighCard, Faces: faces, Value: 1<<20 - packed, game: eightOrBetter}
+}
+
+// EvaluateAceToFive returns the best ace-to-five low, as played in California lowball and razz.
+// Aces are low, straights and flushes don't count and pairs count against the hand, so 5-4-3-2-A is the best.
+// With more than five cards the best five are used; fewer than five are compared as they are,
+// as when finding the bring-in from razz upcards.
+func EvaluateAceToFive(cards []deck.Card) LowValue {
+	return bestLow(cards, 5, evaluateAceToFive)
+}
+
+func evaluateAceToFive(cards []deck.Card) LowValue {
+	var counts [14]int
+	for _, c := range cards {
+		counts[aceLow(c)]++
+	}
+	// order the ranks by how often they appear, then by rank, as the high evaluator does
+	var order []int
+	for n := 4; n >= 1; n-- {
+		for r := 13; r >= 1; r-- {
+			if counts[r] == n {
+				order = append(order, r)
+			}
+		}
+	}
+	category := HighCard
+	switch {
+	case counts[order[0]] == 4:
+		category = FourOfAKind
+	case counts[order[0]] == 3 && len(order) > 1 && counts[order[1]] == 2:
+		category = FullHouse
+	case counts[order[0]] == 3:
+		category = ThreeOfAKind
+	case counts[order[0]] == 2 && len(order) > 1 && counts[order[1]] == 2:
+		category = TwoPair
+	case counts[order[0]] == 2:
+		category = OnePair
+	}
+	packed := int(category)
+	faces := make([]deck.Face, len(order))
+	for i := 0; i < 5; i++ {
+		packed <<= 4
+		if i < len(order) {
+			packed |= order[i]
+			faces[i] = deck.Face(order[i] - 1)
+		}
+	}
+	return LowValue{Category: category, Faces: faces, Value: 1<<24 - packed, game: aceToFive}
+}
+
+// EvaluateDeuceToSeven returns the best deuce-to-seven (Kansas City) low.
+// Aces are always high, so A-2-3-4-5 is no straight, and straights, flushes and pairs
+// all count against the hand, making 7-5-4-3-2 of mixed suits the best.
+// With more than five cards the best five are used.
+func EvaluateDeuceToSeven(cards []deck.Card) LowValue {
+	return bestLow(cards, 5, evaluateDeuceToSeven)
+}
+
+func evaluateDeuceToSeven(cards []deck.Card) LowValue {
+	high := evaluateHigh(cards, straightHighNoWheel)
+	if high.Category == Straight || high.Category == StraightFlush {
+		// list every card of a straight, not just its high card
+		top := (int(high.Kickers[0]) + 12) % 13
+		high.Kickers = make([]deck.Face, 5)
+		for i := range high.Kickers {
+			high.Kickers[i] = face(top - i)
+		}
+	}
+	return LowValue{Category: high.Category, Faces: high.Kickers, Value: 1<<24 - high.Value, game: deuceToSeven}
+}
+
+// EvaluateBadugi returns the best badugi: the largest set of up to four cards with
+// no two sharing a suit or a rank, aces low. More cards always beat fewer, then
+// the lower highest card wins, so a four card 4-3-2-A is the best.
+func EvaluateBadugi(cards []deck.Card) LowValue {
+	best := LowValue{}
+	for size := 4; size >= 1 && !best.Qualified(); size-- {
+		if size > len(cards) {
+			continue
+		}
+		combinations(len(cards), size, func(index []int) {
+			suits, ranks := 0, 0
+			for _, i := range index {
+				suits |= 1 << uint(cards[i].Suit())
+				ranks |= 1 << uint(aceLow(cards[i]))
+			}
+			if bits.OnesCount(uint(suits)) != size || bits.OnesCount(uint(ranks)) != size {
+				return
+			}
+			faces := make([]deck.Face, 0, size)
+			packed := 0
+			for r := 13; r >= 1; r-- {
+				if ranks&(1<<uint(r)) != 0 {
+					faces = append(faces, deck.Face(r-1))
+					packed = packed<<4 | r
+				}
+			}
+			packed <<= uint(4 * (4 - size))
+			if v := size<<16 | (0xffff - packed); v > best.Value {
+				best = LowValue{Category: HighCard, Faces: faces, Value: v, game: badugi}
+			}
+		})
+	}
+	return best
+}
+
+// bestLow tries every combination of size cards and keeps the best low
+func bestLow(cards []deck.Card, size int, evaluate func([]deck.Card) LowValue) LowValue {
+	if len(cards) == 0 {
+		return LowValue{}
+	}
+	if len(cards) <= size {
+		return evaluate(cards)
+	}
+	best := LowValue{}
+	hand := make([]deck.Card, size)
+	combinations(len(cards), size, func(index []int) {
+		for i, j := range index {
+			hand[i] = cards[j]
+		}
+		if v := evaluate(hand); v.Value > best.Value {
+			best = v
+		}
+	})
+	return best
+}
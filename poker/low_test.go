@@ -0,0 +1,70 @@
+package poker
+
+import (
+	"testing"
+
+	"adamclerk/deck/internal/decktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluateDeuceToSeven(t *testing.T) {
+	best := EvaluateDeuceToSeven(decktest.Cards("7c 5d 4h 3s 2c"))
+	assert.Equal(t, HighCard, best.Category)
+	assert.Equal(t, "7-5-4-3-2 number one", best.String())
+	assert.Equal(t, "7-6-5-3-2 number three", EvaluateDeuceToSeven(decktest.Cards("7c 6d 5h 3s 2c")).String())
+	assert.Equal(t, "8-5-4-3-2 number one", EvaluateDeuceToSeven(decktest.Cards("8c 5d 4h 3s 2c")).String())
+
+	// the ace is high and the wheel is no straight
+	wheel := EvaluateDeuceToSeven(decktest.Cards("Ac 2d 3h 4s 5c"))
+	assert.Equal(t, HighCard, wheel.Category)
+	assert.True(t, CompareLow(EvaluateDeuceToSeven(decktest.Cards("Kc Qd Jh Ts 8c")), wheel).IsGreaterThan())
+
+	straight := EvaluateDeuceToSeven(decktest.Cards("6c 5d 4h 3s 2c"))
+	assert.Equal(t, Straight, straight.Category)
+	assert.Equal(t, "Straight (6 5 4 3 2)", straight.String())
+	assert.True(t, CompareLow(wheel, straight).IsGreaterThan())
+
+	flush := EvaluateDeuceToSeven(decktest.Cards("7c 5c 4c 3c 2c"))
+	assert.Equal(t, Flush, flush.Category)
+	assert.True(t, CompareLow(EvaluateDeuceToSeven(decktest.Cards("2c 2d 4h 3s 5c")), flush).IsGreaterThan())
+	assert.True(t, CompareLow(best, EvaluateDeuceToSeven(decktest.Cards("7c 6d 4h 3s 2c"))).IsGreaterThan())
+}
+
+func TestEvaluateAceToFive(t *testing.T) {
+	wheel := EvaluateAceToFive(decktest.Cards("Ac 2c 3c 4c 5c"))
+	assert.Equal(t, "5-4-3-2-A number one", wheel.String())
+	assert.Equal(t, "6-4-3-2-A number one", EvaluateAceToFive(decktest.Cards("Ac 2c 3c 4c 6d")).String())
+	assert.Equal(t, "6-5-4-3-2 number five", EvaluateAceToFive(decktest.Cards("2c 3c 4c 5d 6d")).String())
+
+	pair := EvaluateAceToFive(decktest.Cards("Ac Ad 2c 3c 4c"))
+	assert.Equal(t, OnePair, pair.Category)
+	assert.Equal(t, "One Pair (A 4 3 2)", pair.String())
+	assert.True(t, CompareLow(EvaluateAceToFive(decktest.Cards("Kc Qd Jh Ts 9c")), pair).IsGreaterThan())
+	assert.True(t, CompareLow(pair, EvaluateAceToFive(decktest.Cards("2c 2d Ac 3c 4c"))).IsGreaterThan())
+
+	// razz: the best five of seven
+	razz := EvaluateAceToFive(decktest.Cards("Kc Kd 7h 6s 3c 2d Ah"))
+	assert.Equal(t, "7-6-3-2-A number six", razz.String())
+
+	// partial hands for the bring-in
+	assert.True(t, CompareLow(EvaluateAceToFive(decktest.Cards("Ac")), EvaluateAceToFive(decktest.Cards("Kc"))).IsGreaterThan())
+	assert.False(t, EvaluateAceToFive(nil).Qualified())
+}
+
+func TestEvaluateBadugi(t *testing.T) {
+	best := EvaluateBadugi(decktest.Cards("Ac 2d 3h 4s"))
+	assert.Equal(t, "4-card badugi 4-3-2-A", best.String())
+
+	three := EvaluateBadugi(decktest.Cards("Ac 2d 3h 4h"))
+	assert.Equal(t, "3-card badugi 3-2-A", three.String())
+	assert.True(t, CompareLow(EvaluateBadugi(decktest.Cards("Kc Qd Jh Ts")), three).IsGreaterThan())
+
+	// a paired card can't play
+	paired := EvaluateBadugi(decktest.Cards("Ac Ad 5h 6s"))
+	assert.Equal(t, "3-card badugi 6-5-A", paired.String())
+
+	two := EvaluateBadugi(decktest.Cards("Ac 2c 3c 4d"))
+	assert.Equal(t, "2-card badugi 4-A", two.String())
+	assert.True(t, CompareLow(best, EvaluateBadugi(decktest.Cards("Ac 2d 3h 5s"))).IsGreaterThan())
+	assert.True(t, CompareLow(EvaluateBadugi(decktest.Cards("Ac 2d 3h 5s")), EvaluateBadugi(decktest.Cards("2c 3d 4h 5s"))).IsGreaterThan())
+}
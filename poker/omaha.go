@@ -0,0 +1,115 @@
+package poker
+
+import (
+	"errors"
+
+	"adamclerk/deck"
+)
+
+// Split is the outcome of a showdown for a pot split between high and low.
+// Players are the indexes of the hands given to the showdown.
+type Split struct {
+	High []int // the players sharing the high half
+	Low  []int // the players sharing the low half, empty when no low qualifies and the high scoops
+}
+
+// Scoop tells you if the high winners take the whole pot
+func (s Split) Scoop() bool {
+	return len(s.Low) == 0
+}
+
+// NewSplit finds the winners of each half of the pot. lows may be nil for a high only game.
+func NewSplit(highs []HandValue, lows []LowValue) Split {
+	split := Split{}
+	best := -1
+	for i, h := range highs {
+		if h.Value > best {
+			best = h.Value
+			split.High = split.High[:0]
+		}
+		if h.Value == best {
+			split.High = append(split.High, i)
+		}
+	}
+	best = 0
+	for i, l := range lows {
+		if l.Value > best {
+			best = l.Value
+			split.Low = split.Low[:0]
+		}
+		if l.Value == best && best > 0 {
+			split.Low = append(split.Low, i)
+		}
+	}
+	return split
+}
+
+func checkOmaha(hole, board []deck.Card) error {
+	if len(hole) < 4 || len(hole) > 6 {
+		return errors.New("Omaha needs 4, 5 or 6 hole cards")
+	}
+	if len(board) < 3 || len(board) > 5 {
+		return errors.New("Omaha needs 3 to 5 board cards")
+	}
+	return nil
+}
+
+// omahaHands calls fn with every hand of exactly two hole cards and three board cards
+func omahaHands(hole, board []deck.Card, fn func(hand []deck.Card)) {
+	hand := make([]deck.Card, 5)
+	combinations(len(hole), 2, func(h []int) {
+		hand[0], hand[1] = hole[h[0]], hole[h[1]]
+		combinations(len(board), 3, func(b []int) {
+			hand[2], hand[3], hand[4] = board[b[0]], board[b[1]], board[b[2]]
+			fn(hand)
+		})
+	})
+}
+
+// EvaluateOmaha returns the best high hand made of exactly two hole cards and three board cards.
+// It works for 4, 5 and 6 card Omaha.
+func EvaluateOmaha(hole, board []deck.Card) (HandValue, error) {
+	if err := checkOmaha(hole, board); err != nil {
+		return HandValue{}, err
+	}
+	var best Hand
+	bestValue := -1
+	omahaHands(hole, board, func(hand []deck.Card) {
+		h := NewHand(hand...)
+		if v := h.Value(); v > bestValue {
+			best, bestValue = h, v
+		}
+	})
+	return best.Evaluate(), nil
+}
+
+// EvaluateOmahaLow returns the best eight-or-better low made of exactly two hole cards and three board cards.
+// It returns the zero LowValue when no low qualifies.
+func EvaluateOmahaLow(hole, board []deck.Card) (LowValue, error) {
+	if err := checkOmaha(hole, board); err != nil {
+		return LowValue{}, err
+	}
+	best := LowValue{}
+	omahaHands(hole, board, func(hand []deck.Card) {
+		if v := EvaluateLow8(hand); v.Value > best.Value {
+			best = v
+		}
+	})
+	return best, nil
+}
+
+// OmahaHiLo settles an Omaha eight-or-better showdown between the players' hole cards
+func OmahaHiLo(holes [][]deck.Card, board []deck.Card) (Split, error) {
+	highs := make([]HandValue, len(holes))
+	lows := make([]LowValue, len(holes))
+	for i, hole := range holes {
+		var err error
+		if highs[i], err = EvaluateOmaha(hole, board); err != nil {
+			return Split{}, err
+		}
+		if lows[i], err = EvaluateOmahaLow(hole, board); err != nil {
+			return Split{}, err
+		}
+	}
+	return NewSplit(highs, lows), nil
+}
@@ -0,0 +1,87 @@
+package poker
+
+import (
+	"testing"
+
+	"adamclerk/deck"
+	"adamclerk/deck/internal/decktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluateOmahaUsesTwoHoleCards(t *testing.T) {
+	// four aces in the hand only play as a pair of aces
+	v, err := EvaluateOmaha(decktest.Cards("Ac Ad Ah As"), decktest.Cards("Kc Kd Kh 2s 2d"))
+	assert.Nil(t, err)
+	assert.Equal(t, FullHouse, v.Category)
+	assert.Equal(t, []deck.Face{deck.KING, deck.ACE}, v.Kickers)
+
+	// a single heart in the hand doesn't make a flush with four on the board
+	v, _ = EvaluateOmaha(decktest.Cards("Ah Kc Qd Js"), decktest.Cards("2h 5h 8h Th 3c"))
+	assert.Equal(t, HighCard, v.Category)
+
+	// and four to the straight in the hand need three from the board
+	v, _ = EvaluateOmaha(decktest.Cards("9c Td Jh Qs"), decktest.Cards("Kc 2d 3h 4s 7c"))
+	assert.Equal(t, HighCard, v.Category)
+}
+
+func TestEvaluateOmahaSixCards(t *testing.T) {
+	v, err := EvaluateOmaha(decktest.Cards("Ah Kh 2c 3d 9s 9d"), decktest.Cards("9h 5h 8h"))
+	assert.Nil(t, err)
+	assert.Equal(t, Flush, v.Category)
+	assert.Equal(t, []deck.Face{deck.ACE, deck.KING, deck.NINE, deck.EIGHT, deck.FIVE}, v.Kickers)
+}
+
+func TestEvaluateOmahaCardCounts(t *testing.T) {
+	_, err := EvaluateOmaha(decktest.Cards("Ah Kh 2c"), decktest.Cards("9h 5h 8h"))
+	assert.Equal(t, "Omaha needs 4, 5 or 6 hole cards", err.Error())
+	_, err = EvaluateOmahaLow(decktest.Cards("Ah Kh 2c 3d"), decktest.Cards("9h 5h"))
+	assert.Equal(t, "Omaha needs 3 to 5 board cards", err.Error())
+}
+
+func TestEvaluateLow8(t *testing.T) {
+	low := EvaluateLow8(decktest.Cards("Ac 2d 3h 4s 5c Kd 8h"))
+	assert.True(t, low.Qualified())
+	assert.Equal(t, "5-4-3-2-A number one", low.String())
+
+	eight := EvaluateLow8(decktest.Cards("Ac 2d 4h 6s 8c"))
+	seven := EvaluateLow8(decktest.Cards("Ac 2d 4h 6s 7c"))
+	assert.True(t, CompareLow(seven, eight).IsGreaterThan())
+	assert.True(t, CompareLow(low, seven).IsGreaterThan())
+
+	none := EvaluateLow8(decktest.Cards("Ac 2d 4h 9s 8c 8d"))
+	assert.False(t, none.Qualified())
+	assert.Equal(t, "No Low", none.String())
+}
+
+func TestEvaluateOmahaLow(t *testing.T) {
+	low, _ := EvaluateOmahaLow(decktest.Cards("Ac 2d Kh Ks"), decktest.Cards("3c 6d 8h Qs Kd"))
+	assert.Equal(t, "8-6-3-2-A number six", low.String())
+
+	// three low cards in the hand aren't enough with two on the board
+	low, _ = EvaluateOmahaLow(decktest.Cards("Ac 2d 3h Ks"), decktest.Cards("4c 5d Th Qs Kd"))
+	assert.False(t, low.Qualified())
+
+	// counterfeited: the board duplicates the hand's low cards
+	low, _ = EvaluateOmahaLow(decktest.Cards("Ac 2d Kh Ks"), decktest.Cards("Ad 2c 7h 8s 9d"))
+	assert.False(t, low.Qualified())
+}
+
+func TestOmahaHiLo(t *testing.T) {
+	board := decktest.Cards("3c 4d 5h Ks Qd")
+	split, err := OmahaHiLo([][]deck.Card{
+		decktest.Cards("Ac 2d Kh Jc"), // wheel for both halves
+		decktest.Cards("Kc Kd 7h 7s"), // set of kings
+		decktest.Cards("Ad 2c 9h 9s"), // wheel too
+	}, board)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 2}, split.High)
+	assert.Equal(t, []int{0, 2}, split.Low)
+	assert.False(t, split.Scoop())
+
+	split, _ = OmahaHiLo([][]deck.Card{
+		decktest.Cards("Ac Ad Kh Jc"), // trip jacks
+		decktest.Cards("Kc Qc 7h 7s"), // king high straight
+	}, decktest.Cards("Js Jd Th 9s 9d"))
+	assert.Equal(t, []int{1}, split.High)
+	assert.True(t, split.Scoop())
+}
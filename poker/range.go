@@ -0,0 +1,347 @@
+package poker
+
+import (
+	"errors"
+	"math/big"
+	"math/rand"
+	"strings"
+	"sync"
+
+	"adamclerk/deck"
+	"adamclerk/deck/internal/splitmix"
+)
+
+// Combo is a specific pair of hole cards
+type Combo [2]deck.Card
+
+func (c Combo) String() string {
+	return c[0].String() + c[1].String()
+}
+
+func (c Combo) hand() Hand {
+	return NewHand(c[0], c[1])
+}
+
+// Range is a set of hole card combos a player might hold
+type Range []Combo
+
+// Without returns the combos that don't use any of the cards, the card removal effect of known cards
+func (r Range) Without(cards ...deck.Card) Range {
+	known := NewHand(cards...)
+	var result Range
+	for _, c := range r {
+		if c.hand()&known == 0 {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+const rankNames = "23456789TJQKA"
+const suitNames = "cdhs"
+
+// ParseRange reads a range in the usual notation: a comma separated list of
+// pairs (QQ, QQ+, 22-55), suited and offsuit hands (AKs, T9o, AK for both, KTo+, A2s-A5s),
+// specific combos (AhKh) and random for every combo.
+func ParseRange(notation string) (Range, error) {
+	seen := map[Combo]bool{}
+	var result Range
+	add := func(c Combo) {
+		// the higher card first
+		if rank(c[0]) < rank(c[1]) || (rank(c[0]) == rank(c[1]) && c[0].Suit() < c[1].Suit()) {
+			c[0], c[1] = c[1], c[0]
+		}
+		if !seen[c] {
+			seen[c] = true
+			result = append(result, c)
+		}
+	}
+	for _, token := range strings.Split(notation, ",") {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+		combos, err := parseToken(token)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range combos {
+			add(c)
+		}
+	}
+	if len(result) == 0 {
+		return nil, errors.New("Empty range")
+	}
+	return result, nil
+}
+
+func parseToken(token string) ([]Combo, error) {
+	invalid := errors.New("Invalid range: " + token)
+	if strings.EqualFold(token, "random") {
+		var combos []Combo
+		combinations(52, 2, func(index []int) {
+			combos = append(combos, Combo{deck.Card(index[0]), deck.Card(index[1])})
+		})
+		return combos, nil
+	}
+	if len(token) == 4 && strings.IndexByte(suitNames, token[1]) >= 0 && strings.IndexByte(suitNames, token[3]) >= 0 {
+		c1, err1 := deck.ParseCard(token[:2])
+		c2, err2 := deck.ParseCard(token[2:])
+		if err1 != nil || err2 != nil || c1 == c2 {
+			return nil, invalid
+		}
+		return []Combo{{c1, c2}}, nil
+	}
+	if dash := strings.IndexByte(token, '-'); dash >= 0 {
+		from, err1 := parseHands(token[:dash])
+		to, err2 := parseHands(token[dash+1:])
+		if err1 != nil || err2 != nil || from.suited != to.suited || from.plus || to.plus {
+			return nil, invalid
+		}
+		if from.high == from.low && to.high == to.low {
+			// a range of pairs, 22-55
+			return from.expand(from.high, to.high), nil
+		}
+		if from.high != to.high || from.high == from.low || to.high == to.low {
+			return nil, invalid
+		}
+		return from.expand(from.low, to.low), nil
+	}
+	h, err := parseHands(token)
+	if err != nil {
+		return nil, invalid
+	}
+	switch {
+	case h.plus && h.high == h.low:
+		return h.expand(h.high, 12), nil
+	case h.plus:
+		return h.expand(h.low, h.high-1), nil
+	}
+	return h.expand(h.low, h.low), nil
+}
+
+// hands is a parsed token like AKs, T9o+ or QQ
+type hands struct {
+	high, low int  // poker ranks, 0 for a deuce
+	suited    byte // 's', 'o' or 0 for both
+	plus      bool
+}
+
+func parseHands(token string) (hands, error) {
+	h := hands{}
+	if strings.HasSuffix(token, "+") {
+		h.plus = true
+		token = token[:len(token)-1]
+	}
+	if len(token) == 3 {
+		h.suited = token[2]
+		if h.suited != 's' && h.suited != 'o' {
+			return h, errors.New("Invalid suitedness")
+		}
+		token = token[:2]
+	}
+	if len(token) != 2 {
+		return h, errors.New("Invalid hand")
+	}
+	h.high = strings.IndexByte(rankNames, token[0])
+	h.low = strings.IndexByte(rankNames, token[1])
+	if h.high < 0 || h.low < 0 {
+		return h, errors.New("Invalid rank")
+	}
+	if h.high < h.low {
+		h.high, h.low = h.low, h.high
+	}
+	if h.high == h.low && h.suited != 0 {
+		return h, errors.New("Pairs are never suited")
+	}
+	return h, nil
+}
+
+// expand returns every combo between the two ranks: pairs when the hand is a pair,
+// otherwise the hand's high card with each kicker rank.
+func (h hands) expand(from, to int) []Combo {
+	if from > to {
+		from, to = to, from
+	}
+	var combos []Combo
+	for r := from; r <= to; r++ {
+		high, low := h.high, r
+		if h.high == h.low {
+			high = r
+		}
+		for s1 := 0; s1 < 4; s1++ {
+			for s2 := 0; s2 < 4; s2++ {
+				if high == low && s2 <= s1 {
+					continue
+				}
+				if (h.suited == 's' && s1 != s2) || (h.suited == 'o' && s1 == s2) {
+					continue
+				}
+				combos = append(combos, Combo{
+					deck.NewCard(face(high), deck.Suit(s1)),
+					deck.NewCard(face(low), deck.Suit(s2)),
+				})
+			}
+		}
+	}
+	return combos
+}
+
+// RangeEquity calculates each player's equity when every player holds a hand from their range.
+// Hand versus range is a range with a single combo. Combos that clash with the board, the dead
+// cards or each other are never dealt, so every consistent deal is equally likely.
+// Small problems are enumerated, the others are dealt by Monte Carlo with the same options as CalculateEquity.
+func RangeEquity(ranges []Range, options ...func(*EquityOptions)) (*EquityResult, error) {
+	if len(ranges) < 2 {
+		return nil, errors.New("At least two players are needed")
+	}
+	opt, known, err := newEquityOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	live := make([][]Hand, len(ranges))
+	deals := big.NewInt(1)
+	for i, r := range ranges {
+		for _, c := range r {
+			if h := c.hand(); h&known == 0 && h.NumberOfCards() == 2 {
+				live[i] = append(live[i], h)
+			}
+		}
+		if len(live[i]) == 0 {
+			return nil, errors.New("A range has no combos left")
+		}
+		deals.Mul(deals, big.NewInt(int64(len(live[i]))))
+	}
+	missing := 5 - len(opt.Board)
+	stubSize := 52 - known.NumberOfCards() - 2*len(ranges)
+	if missing > stubSize {
+		return nil, errors.New("Not enough cards left to complete the board")
+	}
+	deals.Mul(deals, new(big.Int).Binomial(int64(stubSize), int64(missing)))
+
+	board := NewHand(opt.Board...)
+	total := newTally(len(ranges))
+	var mu sync.Mutex
+	exhaustive := deals.IsInt64() && deals.Int64() <= int64(opt.ExhaustiveLimit)
+
+	var wg sync.WaitGroup
+	if exhaustive {
+		tasks := make(chan []Hand)
+		for w := 0; w < opt.Workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				t := newTally(len(ranges))
+				for players := range tasks {
+					used := known
+					for _, p := range players {
+						used |= p
+					}
+					enumerate(t, players, stubOf(used), 0, missing, board)
+				}
+				mu.Lock()
+				total.add(t)
+				mu.Unlock()
+			}()
+		}
+		eachDeal(live, known, nil, func(players []Hand) {
+			tasks <- append([]Hand{}, players...)
+		})
+		close(tasks)
+	} else {
+		if !canDeal(live, known, 0) {
+			return nil, errors.New("The ranges can't all be dealt at once")
+		}
+		chunks := make(chan int, opt.Trials/trialsPerChunk+1)
+		for c := 0; c*trialsPerChunk < opt.Trials; c++ {
+			chunks <- c
+		}
+		close(chunks)
+		for w := 0; w < opt.Workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				t := newTally(len(ranges))
+				players := make([]Hand, len(ranges))
+				for c := range chunks {
+					r := rand.New(rand.NewSource(splitmix.Mix(opt.Seed, c)))
+					n := trialsPerChunk
+					if rest := opt.Trials - c*trialsPerChunk; rest < n {
+						n = rest
+					}
+					for i := 0; i < n; i++ {
+						used := dealRanges(r, live, known, players)
+						showdown(t, players, dealBoard(r, used, board, missing))
+					}
+				}
+				mu.Lock()
+				total.add(t)
+				mu.Unlock()
+			}()
+		}
+	}
+	wg.Wait()
+	if total.boards == 0 {
+		return nil, errors.New("The ranges can't all be dealt at once")
+	}
+	return total.result(exhaustive), nil
+}
+
+// eachDeal calls fn with every assignment of one combo per range that doesn't reuse a card
+func eachDeal(live [][]Hand, used Hand, players []Hand, fn func(players []Hand)) {
+	if len(players) == len(live) {
+		fn(players)
+		return
+	}
+	for _, h := range live[len(players)] {
+		if h&used == 0 {
+			eachDeal(live, used|h, append(players, h), fn)
+		}
+	}
+}
+
+// canDeal tells you if one combo per range can be dealt without reusing a card
+func canDeal(live [][]Hand, used Hand, player int) bool {
+	if player == len(live) {
+		return true
+	}
+	for _, h := range live[player] {
+		if h&used == 0 && canDeal(live, used|h, player+1) {
+			return true
+		}
+	}
+	return false
+}
+
+// dealRanges picks a combo for each player, starting over when two combos share a card
+// so that every consistent deal is equally likely. It returns all the cards in use.
+func dealRanges(r *rand.Rand, live [][]Hand, known Hand, players []Hand) Hand {
+	for {
+		used := known
+		ok := true
+		for i, combos := range live {
+			h := combos[r.Intn(len(combos))]
+			if h&used != 0 {
+				ok = false
+				break
+			}
+			players[i] = h
+			used |= h
+		}
+		if ok {
+			return used
+		}
+	}
+}
+
+// dealBoard completes the board with random cards that aren't used
+func dealBoard(r *rand.Rand, used, board Hand, missing int) Hand {
+	for missing > 0 {
+		c := NewHand(deck.Card(r.Intn(52)))
+		if c&(used|board) == 0 {
+			board |= c
+			missing--
+		}
+	}
+	return board
+}
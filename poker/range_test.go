@@ -0,0 +1,111 @@
+package poker
+
+import (
+	"math"
+	"testing"
+
+	"adamclerk/deck/internal/decktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func parseRange(t *testing.T, notation string) Range {
+	r, err := ParseRange(notation)
+	assert.Nil(t, err)
+	return r
+}
+
+func TestParseRangeSizes(t *testing.T) {
+	for notation, size := range map[string]int{
+		"random":         1326,
+		"QQ":             6,
+		"QQ+":            18,
+		"22-55":          24,
+		"AKs":            4,
+		"AKo":            12,
+		"AK":             16,
+		"T9o":            12,
+		"A2s-A5s":        16,
+		"A5s-A2s":        16,
+		"KTo+":           36,
+		"A2s+":           48,
+		"AhKh":           1,
+		"AhKh, AKs":      4,
+		"QQ+, AKs, 76s ": 26,
+	} {
+		assert.Equal(t, size, len(parseRange(t, notation)), notation)
+	}
+}
+
+func TestParseRangeCombos(t *testing.T) {
+	assert.Equal(t, "A♥K♥", parseRange(t, "AhKh")[0].String())
+	for _, c := range parseRange(t, "KTo+") {
+		assert.Equal(t, 12, c[0].Face(), "the king is the higher card")
+		assert.NotEqual(t, c[0].Suit(), c[1].Suit())
+	}
+}
+
+func TestParseRangeErrors(t *testing.T) {
+	for _, notation := range []string{"QQs", "AX", "A", "AKx", "AhAh", "A2s-K5s", "22-A5s", "AKs+-AQs"} {
+		_, err := ParseRange(notation)
+		assert.NotNil(t, err, notation)
+	}
+	_, err := ParseRange(" , ")
+	assert.Equal(t, "Empty range", err.Error())
+	_, err = ParseRange("AKq")
+	assert.Equal(t, "Invalid range: AKq", err.Error())
+}
+
+func TestRangeWithout(t *testing.T) {
+	aces := parseRange(t, "AA")
+	assert.Equal(t, 3, len(aces.Without(decktest.Cards("Ah")...)))
+	assert.Equal(t, 1, len(aces.Without(decktest.Cards("Ah Ad")...)))
+}
+
+func TestHandVersusRangeMatchesHandVersusHand(t *testing.T) {
+	board := Board(decktest.Cards("2h 7h Tc 3s")...)
+	result, err := RangeEquity([]Range{parseRange(t, "AhKh"), parseRange(t, "QcQd")}, board)
+	assert.Nil(t, err)
+	assert.True(t, result.Exhaustive)
+	assert.InDelta(t, 15.0/44, result.Players[0].Equity, 1e-9)
+}
+
+func TestRangeVersusRangeCardRemoval(t *testing.T) {
+	// with Ah and Kh on the board only one combo of aces and of kings is left
+	result, err := RangeEquity([]Range{parseRange(t, "AA"), parseRange(t, "KK")},
+		Board(decktest.Cards("Ah Kh 2c 3d 4s")...), Dead(decktest.Cards("Ad Kd")...))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, result.Boards)
+	assert.Equal(t, 1.0, result.Players[0].Win)
+}
+
+func TestRangeVersusRangeMonteCarlo(t *testing.T) {
+	ranges := []Range{parseRange(t, "QQ+, AKs"), parseRange(t, "22-66, 76s")}
+	exact, _ := RangeEquity(ranges, Board(decktest.Cards("Ks 8d 5c")...))
+	assert.True(t, exact.Exhaustive)
+	sampled, _ := RangeEquity(ranges, Board(decktest.Cards("Ks 8d 5c")...), ExhaustiveLimit(0), Trials(100000), Workers(3))
+	assert.False(t, sampled.Exhaustive)
+	assert.InDelta(t, exact.Players[0].Equity, sampled.Players[0].Equity, 0.01)
+	again, _ := RangeEquity(ranges, Board(decktest.Cards("Ks 8d 5c")...), ExhaustiveLimit(0), Trials(100000), Workers(1))
+	assert.Equal(t, sampled, again)
+}
+
+func TestRangeEquityAdjacentSeedsAreIndependent(t *testing.T) {
+	ranges := []Range{parseRange(t, "QQ+, AKs"), parseRange(t, "22-66, 76s")}
+	both, _ := RangeEquity(ranges, ExhaustiveLimit(0), Trials(2*trialsPerChunk), Seed(7))
+	first, _ := RangeEquity(ranges, ExhaustiveLimit(0), Trials(trialsPerChunk), Seed(7))
+	next, _ := RangeEquity(ranges, ExhaustiveLimit(0), Trials(trialsPerChunk), Seed(8))
+	// the second chunk of seed 7 isn't the first one of seed 8
+	second := math.Round(both.Players[0].Win*2*trialsPerChunk - first.Players[0].Win*trialsPerChunk)
+	assert.NotEqual(t, second, math.Round(next.Players[0].Win*trialsPerChunk))
+}
+
+func TestRangeEquityErrors(t *testing.T) {
+	_, err := RangeEquity([]Range{parseRange(t, "AhKh"), parseRange(t, "AhKh")})
+	assert.Equal(t, "The ranges can't all be dealt at once", err.Error())
+	_, err = RangeEquity([]Range{parseRange(t, "AhKh"), parseRange(t, "AhKh")}, ExhaustiveLimit(0))
+	assert.Equal(t, "The ranges can't all be dealt at once", err.Error())
+	_, err = RangeEquity([]Range{parseRange(t, "AhKh"), parseRange(t, "QQ")}, Dead(decktest.Cards("Ah")...))
+	assert.Equal(t, "A range has no combos left", err.Error())
+	_, err = RangeEquity([]Range{parseRange(t, "AA"), parseRange(t, "KK")}, Trials(0))
+	assert.Equal(t, "Invalid number of trials", err.Error())
+}
@@ -0,0 +1,207 @@
+// Package pot splits the chips of a poker hand between the players.
+//
+// It builds the main pot and side pots from what each player put in, then
+// divides each pot between the best hands still in for it, high and low
+// halves included, handing out odd chips by a configurable seat order.
+package pot
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// Player is one seat's part in the hand
+type Player struct {
+	Contributed int  // every chip put in during the hand, antes and blinds included
+	Folded      bool // folded players can't win, but their chips stay in the pots
+	High        int  // showdown ranking for high: higher is better and equal values tie
+	Low         int  // showdown ranking for low: higher is better, 0 is no qualifying low
+}
+
+// Pot is a main or side pot
+type Pot struct {
+	Amount     int
+	Eligible   []int // seats that can win the pot
+	Winners    []int // seats sharing the pot, or its high half in a hi/lo game
+	LowWinners []int // seats sharing the low half, empty when nobody qualifies for low
+}
+
+// Result is the exact distribution of the chips
+type Result struct {
+	Pots     []Pot
+	Winnings []int // chips won, by seat
+}
+
+// Options is the struct used to describe how the pots are split
+type Options struct {
+	HiLo     bool
+	OddChips func(winners []int) []int // orders the winners who get odd chips first
+}
+
+// HiLo is a functional option used to split every pot between the best high and the best qualifying low.
+// The odd chip of an uneven pot goes to the high half, and a pot with no qualifying low goes to the high.
+func HiLo(o *Options) {
+	o.HiLo = true
+}
+
+// LeftOfButton is a functional option used to give odd chips to the winners closest to the left of the button.
+// This is the default, with the button on seat 0.
+func LeftOfButton(button, seats int) func(*Options) {
+	return func(o *Options) {
+		o.OddChips = func(winners []int) []int {
+			ordered := append([]int{}, winners...)
+			sort.Slice(ordered, func(i, j int) bool {
+				return (ordered[i]-button-1+seats)%seats < (ordered[j]-button-1+seats)%seats
+			})
+			return ordered
+		}
+	}
+}
+
+// SeatOrder is a functional option used to give odd chips to the winners in the lowest seats
+func SeatOrder(o *Options) {
+	o.OddChips = func(winners []int) []int {
+		ordered := append([]int{}, winners...)
+		sort.Ints(ordered)
+		return ordered
+	}
+}
+
+// OddChips is a functional option used to give odd chips by a custom rule, such as
+// to the best suit in stud. order returns the winners in the order they receive odd chips.
+func OddChips(order func(winners []int) []int) func(*Options) {
+	return func(o *Options) {
+		o.OddChips = order
+	}
+}
+
+// Distribute builds the pots from the players' contributions and pays the winners of each.
+// Players are given in seat order.
+func Distribute(players []Player, options ...func(*Options)) (*Result, error) {
+	opt := Options{}
+	LeftOfButton(0, len(players))(&opt)
+	for _, option := range options {
+		option(&opt)
+	}
+	live := false
+	for _, p := range players {
+		if p.Contributed < 0 {
+			return nil, errors.New("Contributions can't be negative")
+		}
+		live = live || !p.Folded
+	}
+	if !live {
+		return nil, errors.New("Every player folded")
+	}
+
+	result := &Result{Pots: SidePots(players), Winnings: make([]int, len(players))}
+	for i := range result.Pots {
+		pot := &result.Pots[i]
+		pot.Winners = best(pot.Eligible, func(s int) int { return players[s].High }, math.MinInt32)
+		high := pot.Amount
+		if opt.HiLo {
+			pot.LowWinners = best(pot.Eligible, func(s int) int { return players[s].Low }, 1)
+			if len(pot.LowWinners) > 0 {
+				low := pot.Amount / 2
+				high -= low
+				share(result.Winnings, low, opt.OddChips(pot.LowWinners))
+			}
+		}
+		share(result.Winnings, high, opt.OddChips(pot.Winners))
+	}
+	return result, nil
+}
+
+// SidePots splits the contributions into a main pot and side pots, each with the players who can win it.
+// Chips only folded players put in at some level go to the pot below. When every player who put chips in
+// folded, they make a single pot nobody is eligible for.
+func SidePots(players []Player) []Pot {
+	var pots []Pot
+	level, carry := 0, 0
+	for {
+		next := 0
+		for _, p := range players {
+			if p.Contributed > level && (next == 0 || p.Contributed < next) {
+				next = p.Contributed
+			}
+		}
+		if next == 0 {
+			switch {
+			case carry > 0 && len(pots) > 0:
+				pots[len(pots)-1].Amount += carry
+			case carry > 0:
+				pots = append(pots, Pot{Amount: carry})
+			}
+			return pots
+		}
+		pot := Pot{Amount: carry}
+		carry = 0
+		for i, p := range players {
+			if p.Contributed > level {
+				pot.Amount += min(p.Contributed, next) - level
+				if !p.Folded && p.Contributed >= next {
+					pot.Eligible = append(pot.Eligible, i)
+				}
+			}
+		}
+		level = next
+		switch {
+		case len(pot.Eligible) == 0 && len(pots) > 0:
+			pots[len(pots)-1].Amount += pot.Amount
+		case len(pot.Eligible) == 0:
+			carry = pot.Amount
+		case len(pots) > 0 && sameSeats(pots[len(pots)-1].Eligible, pot.Eligible):
+			pots[len(pots)-1].Amount += pot.Amount
+		default:
+			pots = append(pots, pot)
+		}
+	}
+}
+
+// best returns the eligible seats with the highest ranking of at least minimum
+func best(eligible []int, ranking func(seat int) int, minimum int) []int {
+	var winners []int
+	top := minimum
+	for _, s := range eligible {
+		r := ranking(s)
+		if r > top {
+			top = r
+			winners = winners[:0]
+		}
+		if r == top {
+			winners = append(winners, s)
+		}
+	}
+	return winners
+}
+
+// share divides amount equally, the odd chips going one each to the first winners
+func share(winnings []int, amount int, winners []int) {
+	each, odd := amount/len(winners), amount%len(winners)
+	for i, w := range winners {
+		winnings[w] += each
+		if i < odd {
+			winnings[w]++
+		}
+	}
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func sameSeats(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,21 @@
+package pot
+
+import "fmt"
+
+func ExampleDistribute() {
+	result, _ := Distribute([]Player{
+		{Contributed: 25, High: 4},               // all in for 25
+		{Contributed: 100, High: 9},              // best hand, covers everyone
+		{Contributed: 100, High: 9},              // ties seat 1
+		{Contributed: 10, Folded: true, High: 0}, // folded after the ante
+	}, LeftOfButton(3, 4))
+
+	for _, p := range result.Pots {
+		fmt.Printf("%d chips for %v won by %v\n", p.Amount, p.Eligible, p.Winners)
+	}
+	fmt.Println(result.Winnings)
+	// Output:
+	// 85 chips for [0 1 2] won by [1 2]
+	// 150 chips for [1 2] won by [1 2]
+	// [0 118 117 0]
+}
@@ -0,0 +1,150 @@
+package pot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistributeSingleWinner(t *testing.T) {
+	result, err := Distribute([]Player{
+		{Contributed: 10, High: 5},
+		{Contributed: 10, High: 7},
+		{Contributed: 4, Folded: true},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []Pot{{Amount: 24, Eligible: []int{0, 1}, Winners: []int{1}}}, result.Pots)
+	assert.Equal(t, []int{0, 24, 0}, result.Winnings)
+}
+
+func TestDistributeMultipleAllIns(t *testing.T) {
+	result, err := Distribute([]Player{
+		{Contributed: 50, High: 9},
+		{Contributed: 100, High: 8},
+		{Contributed: 200, High: 7},
+		{Contributed: 200, High: 1},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []Pot{
+		{Amount: 200, Eligible: []int{0, 1, 2, 3}, Winners: []int{0}},
+		{Amount: 150, Eligible: []int{1, 2, 3}, Winners: []int{1}},
+		{Amount: 200, Eligible: []int{2, 3}, Winners: []int{2}},
+	}, result.Pots)
+	assert.Equal(t, []int{200, 150, 200, 0}, result.Winnings)
+}
+
+func TestDistributeFoldedChips(t *testing.T) {
+	// the bet nobody called is the only side pot of its single caller
+	result, _ := Distribute([]Player{
+		{Contributed: 30, High: 1},
+		{Contributed: 100, High: 2},
+		{Contributed: 100, Folded: true},
+	})
+	assert.Equal(t, []Pot{
+		{Amount: 90, Eligible: []int{0, 1}, Winners: []int{1}},
+		{Amount: 140, Eligible: []int{1}, Winners: []int{1}},
+	}, result.Pots)
+
+	// a folded player put in more than anyone left: the excess joins the pot below
+	result, _ = Distribute([]Player{
+		{Contributed: 30, High: 2},
+		{Contributed: 20, High: 1},
+		{Contributed: 100, Folded: true},
+	})
+	assert.Equal(t, []Pot{
+		{Amount: 60, Eligible: []int{0, 1}, Winners: []int{0}},
+		{Amount: 90, Eligible: []int{0}, Winners: []int{0}},
+	}, result.Pots)
+	assert.Equal(t, []int{150, 0, 0}, result.Winnings)
+}
+
+func TestSidePotsMergesSameSeats(t *testing.T) {
+	// the folded player's level doesn't start a new pot for the same seats
+	pots := SidePots([]Player{
+		{Contributed: 40},
+		{Contributed: 15, Folded: true},
+		{Contributed: 40},
+	})
+	assert.Equal(t, []Pot{{Amount: 95, Eligible: []int{0, 2}}}, pots)
+	assert.Nil(t, SidePots([]Player{{}, {}}))
+}
+
+func TestSidePotsEveryoneFolded(t *testing.T) {
+	// no chips are lost, though nobody can win them
+	pots := SidePots([]Player{
+		{Contributed: 10, Folded: true},
+		{Contributed: 25, Folded: true},
+		{},
+	})
+	assert.Equal(t, []Pot{{Amount: 35}}, pots)
+}
+
+func TestDistributeOddChips(t *testing.T) {
+	players := []Player{
+		{Contributed: 5, High: 3},
+		{Contributed: 5, High: 3},
+		{Contributed: 5, High: 3},
+		{Contributed: 2, Folded: true},
+	}
+	result, _ := Distribute(players)
+	assert.Equal(t, []int{5, 6, 6, 0}, result.Winnings)
+
+	result, _ = Distribute(players, LeftOfButton(1, len(players)))
+	assert.Equal(t, []int{6, 5, 6, 0}, result.Winnings)
+
+	result, _ = Distribute(players, SeatOrder)
+	assert.Equal(t, []int{6, 6, 5, 0}, result.Winnings)
+
+	last := func(winners []int) []int {
+		ordered := []int{}
+		for i := len(winners) - 1; i >= 0; i-- {
+			ordered = append(ordered, winners[i])
+		}
+		return ordered
+	}
+	result, _ = Distribute(players, OddChips(last))
+	assert.Equal(t, []int{5, 6, 6, 0}, result.Winnings)
+}
+
+func TestDistributeHiLo(t *testing.T) {
+	players := []Player{
+		{Contributed: 11, High: 9, Low: 2},
+		{Contributed: 11, High: 4, Low: 5},
+		{Contributed: 11, High: 1, Low: 5},
+	}
+	result, err := Distribute(players, HiLo)
+	assert.Nil(t, err)
+	assert.Equal(t, []Pot{{Amount: 33, Eligible: []int{0, 1, 2}, Winners: []int{0}, LowWinners: []int{1, 2}}}, result.Pots)
+	// 17 to the high, the odd chip of the 16 chip low half to the left of the button
+	assert.Equal(t, []int{17, 8, 8}, result.Winnings)
+
+	// a single chip side pot can't be split, so it all goes to the high
+	players[2].Contributed = 12
+	result, _ = Distribute(players, HiLo)
+	assert.Equal(t, []int{17, 8, 9}, result.Winnings)
+}
+
+func TestDistributeHiLoNoLow(t *testing.T) {
+	result, _ := Distribute([]Player{
+		{Contributed: 7, High: 2},
+		{Contributed: 7, High: 3},
+	}, HiLo)
+	assert.Empty(t, result.Pots[0].LowWinners)
+	assert.Equal(t, []int{0, 14}, result.Winnings)
+}
+
+func TestDistributeScoop(t *testing.T) {
+	result, _ := Distribute([]Player{
+		{Contributed: 20, High: 6, Low: 3},
+		{Contributed: 20, High: 5, Low: 1},
+	}, HiLo)
+	assert.Equal(t, []int{40, 0}, result.Winnings)
+}
+
+func TestDistributeErrors(t *testing.T) {
+	_, err := Distribute([]Player{{Contributed: -1}, {Contributed: 2}})
+	assert.EqualError(t, err, "Contributions can't be negative")
+
+	_, err = Distribute([]Player{{Contributed: 2, Folded: true}, {Contributed: 2, Folded: true}})
+	assert.EqualError(t, err, "Every player folded")
+}
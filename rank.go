@@ -0,0 +1,98 @@
+package deck
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+)
+
+// Rank returns the lexicographic rank of the deck's order among all distinct
+// orders of the same cards (its Lehmer code). Rank 0 is the deck sorted with
+// DefaultCompare. Duplicate cards, as found in shoes, are counted once per
+// distinct order, so a shoe ranks as a multiset permutation.
+// A full 52 card deck needs at most 226 bits.
+func (d *Deck) Rank() *big.Int {
+	counts, total := composition(d.Cards)
+	values := sortedValues(counts)
+	rank := new(big.Int)
+	block := new(big.Int)
+	remaining := int64(len(d.Cards))
+	for _, card := range d.Cards {
+		// count the orders that put a smaller card in this position
+		for _, v := range values {
+			if v == card {
+				break
+			}
+			rank.Add(rank, block.Quo(block.Mul(total, big.NewInt(int64(counts[v]))), big.NewInt(remaining)))
+		}
+		total.Quo(total.Mul(total, big.NewInt(int64(counts[card]))), big.NewInt(remaining))
+		remaining--
+		counts[card]--
+	}
+	return rank
+}
+
+// NumberOfOrderings is a utility function that tells you how many distinct orders the cards in the deck have
+func (d *Deck) NumberOfOrderings() *big.Int {
+	_, total := composition(d.Cards)
+	return total
+}
+
+// FromRank creates a deck with the order given by rank.
+// The cards are chosen by options exactly like New, so FromRank works for
+// partial decks and shoes built with Faces, Suits, Decks or WithCards.
+// It is the inverse of Rank.
+func FromRank(rank *big.Int, options ...func(*Options)) (*Deck, error) {
+	d, err := New(append(options, Unshuffled)...)
+	if err != nil {
+		return nil, err
+	}
+	counts, total := composition(d.Cards)
+	if rank.Sign() < 0 || rank.Cmp(total) >= 0 {
+		return nil, errors.New("Rank out of range")
+	}
+
+	values := sortedValues(counts)
+	r := new(big.Int).Set(rank)
+	block := new(big.Int)
+	remaining := int64(len(d.Cards))
+	for i := range d.Cards {
+		for _, v := range values {
+			if counts[v] == 0 {
+				continue
+			}
+			block.Quo(block.Mul(total, big.NewInt(int64(counts[v]))), big.NewInt(remaining))
+			if r.Cmp(block) < 0 {
+				d.Cards[i] = v
+				total.Set(block)
+				break
+			}
+			r.Sub(r, block)
+		}
+		remaining--
+		counts[d.Cards[i]]--
+	}
+	return d, nil
+}
+
+// composition counts each card and the number of distinct orders of all of them.
+func composition(cards []Card) (map[Card]int, *big.Int) {
+	counts := map[Card]int{}
+	total := big.NewInt(1)
+	for i, card := range cards {
+		counts[card]++
+		// multiply by i+1 and divide by the new multiplicity, keeping the multinomial exact
+		total.Mul(total, big.NewInt(int64(i+1)))
+		total.Quo(total, big.NewInt(int64(counts[card])))
+	}
+	return counts, total
+}
+
+func sortedValues(counts map[Card]int) []Card {
+	values := make([]Card, 0, len(counts))
+	for v := range counts {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool { return DefaultCompare(values[i], values[j]).IsLessThan() })
+	return values
+}
@@ -0,0 +1,69 @@
+package deck
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRankSortedDeck(t *testing.T) {
+	deck, _ := New(WithCards(NewCard(ACE, CLUB), NewCard(ACE, HEART), NewCard(TWO, CLUB)), Unshuffled)
+	assert.Equal(t, int64(0), deck.Rank().Int64())
+	assert.Equal(t, int64(6), deck.NumberOfOrderings().Int64())
+}
+
+func TestRankLastOrder(t *testing.T) {
+	deck, _ := New(WithCards(NewCard(TWO, CLUB), NewCard(ACE, HEART), NewCard(ACE, CLUB)), Unshuffled)
+	assert.Equal(t, int64(5), deck.Rank().Int64())
+}
+
+func TestRankFullDeck(t *testing.T) {
+	deck, _ := New(Unshuffled)
+	max := new(big.Int).Sub(deck.NumberOfOrderings(), big.NewInt(1))
+	assert.Equal(t, 226, max.BitLen())
+	assert.True(t, deck.Rank().Cmp(max) < 0)
+}
+
+func TestFromRankRoundTrip(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		deck, _ := New()
+		result, err := FromRank(deck.Rank())
+		assert.Nil(t, err)
+		assert.Equal(t, deck.GetSignature(), result.GetSignature())
+	}
+}
+
+func TestFromRankShoe(t *testing.T) {
+	// 2 aces of hearts and 2 kings of hearts have 4!/(2!2!) = 6 orders
+	options := []func(*Options){Decks(2), Faces(ACE, KING), Suits(HEART)}
+	shoe, _ := New(options...)
+	assert.Equal(t, int64(6), shoe.NumberOfOrderings().Int64())
+
+	seen := map[string]bool{}
+	for i := int64(0); i < 6; i++ {
+		result, err := FromRank(big.NewInt(i), options...)
+		assert.Nil(t, err)
+		assert.Equal(t, i, result.Rank().Int64())
+		seen[result.GetSignature()] = true
+	}
+	assert.Equal(t, 6, len(seen))
+
+	result, _ := FromRank(big.NewInt(0), options...)
+	assert.Equal(t, "A♥\nA♥\nK♥\nK♥\n", result.String())
+}
+
+func TestFromRankOutOfRange(t *testing.T) {
+	_, err := FromRank(big.NewInt(24), Faces(ACE))
+	assert.Equal(t, "Rank out of range", err.Error())
+	_, err = FromRank(big.NewInt(-1))
+	assert.Equal(t, "Rank out of range", err.Error())
+}
+
+func BenchmarkRank(b *testing.B) {
+	b.ReportAllocs()
+	deck, _ := New()
+	for n := 0; n < b.N; n++ {
+		deck.Rank()
+	}
+}
@@ -0,0 +1,85 @@
+package deck
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// FromSeed is a functional option used to shuffle a deck deterministically from a seed string.
+// The shuffle is driven by HMAC_DRBG with SHA-256 (NIST SP 800-90A) instantiated with the seed,
+// rather than math/rand, so the same seed and options give the same GetSignature forever.
+// Use it to reproduce a deal from a deal ID. The empty string is a seed like any other.
+func FromSeed(seed string) func(*Options) {
+	return func(o *Options) {
+		o.seed = &seed
+	}
+}
+
+// shuffleSeeded is the same Knuth shuffle as Shuffle, drawing each swap from the DRBG.
+func (d *Deck) shuffleSeeded(seed string) {
+	drbg := newHMACDRBG([]byte(seed))
+	N := len(d.Cards)
+	for i := 0; i < N; i++ {
+		r := i + int(drbg.Intn(uint64(N-i)))
+		d.Cards[r], d.Cards[i] = d.Cards[i], d.Cards[r]
+	}
+}
+
+// hmacDRBG is HMAC_DRBG from NIST SP 800-90A using SHA-256,
+// without reseeding, nonce or personalization string.
+type hmacDRBG struct {
+	k, v []byte
+}
+
+func newHMACDRBG(seed []byte) *hmacDRBG {
+	d := &hmacDRBG{k: make([]byte, sha256.Size), v: make([]byte, sha256.Size)}
+	for i := range d.v {
+		d.v[i] = 0x01
+	}
+	d.update(seed)
+	return d
+}
+
+func (d *hmacDRBG) mac(data ...[]byte) []byte {
+	m := hmac.New(sha256.New, d.k)
+	for _, b := range data {
+		m.Write(b)
+	}
+	return m.Sum(nil)
+}
+
+func (d *hmacDRBG) update(data []byte) {
+	d.k = d.mac(d.v, []byte{0x00}, data)
+	d.v = d.mac(d.v)
+	if len(data) == 0 {
+		return
+	}
+	d.k = d.mac(d.v, []byte{0x01}, data)
+	d.v = d.mac(d.v)
+}
+
+// Read fills p with the output of a single generate request.
+func (d *hmacDRBG) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		d.v = d.mac(d.v)
+		n += copy(p[n:], d.v)
+	}
+	d.update(nil)
+	return n, nil
+}
+
+// Intn returns a uniform number in [0, n) from one 8 byte generate request per attempt.
+// Values that would bias the modulo are rejected.
+func (d *hmacDRBG) Intn(n uint64) uint64 {
+	threshold := -n % n
+	buf := make([]byte, 8)
+	for {
+		d.Read(buf)
+		x := binary.BigEndian.Uint64(buf)
+		if x >= threshold {
+			return x % n
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package deck
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// First HMAC_DRBG SHA-256 vector (no reseed, no personalization) from NIST CAVP
+func TestHMACDRBGKnownAnswer(t *testing.T) {
+	entropy, _ := hex.DecodeString("ca851911349384bffe89de1cbdc46e6831e44d34a4fb935ee285dd14b71a7488")
+	nonce, _ := hex.DecodeString("659ba96c601dc69fc902940805ec0ca8")
+	drbg := newHMACDRBG(append(entropy, nonce...))
+	out := make([]byte, 128)
+	drbg.Read(out)
+	drbg.Read(out)
+	assert.Equal(t, "e528e9abf2dece54d47c7e75e5fe302149f817ea9fb4bee6f4199697d04d5b89"+
+		"d54fbb978a15b5c443c9ec21036d2460b6f73ebad0dc2aba6e624abf07745bc1"+
+		"07694bb7547bb0995f70de25d6b29e2d3011bb19d27676c07162c8b5ccde0668"+
+		"961df86803482cb37ed6d5c0bb8d50cf1f50d476aa0458bdaba806f48be9dcb8", hex.EncodeToString(out))
+}
+
+func TestFromSeedIsStable(t *testing.T) {
+	deck, _ := New(FromSeed("8f3a"))
+	assert.Equal(t, "1071c14211019140b29213a073c050320381b080b13052c3902261631262b333a331829302c221a2708341a17253002360204351", deck.GetSignature())
+}
+
+func TestFromSeedIsDeterministic(t *testing.T) {
+	deck1, _ := New(FromSeed("deal #1234"), Decks(2))
+	deck2, _ := New(FromSeed("deal #1234"), Decks(2))
+	deck3, _ := New(FromSeed("deal #1235"), Decks(2))
+	assert.Equal(t, deck1.GetSignature(), deck2.GetSignature())
+	assert.NotEqual(t, deck1.GetSignature(), deck3.GetSignature())
+}
+
+func TestFromSeedEmpty(t *testing.T) {
+	deck1, _ := New(FromSeed(""))
+	deck2, _ := New(FromSeed(""))
+	unshuffled, _ := New(Unshuffled)
+	assert.Equal(t, deck1.GetSignature(), deck2.GetSignature())
+	assert.NotEqual(t, unshuffled.GetSignature(), deck1.GetSignature())
+}
+
+func TestFromSeedUnshuffled(t *testing.T) {
+	expected, _ := New(Unshuffled)
+	deck, _ := New(FromSeed("8f3a"), Unshuffled)
+	assert.Equal(t, expected.GetSignature(), deck.GetSignature())
+}
@@ -0,0 +1,40 @@
+package shufflestats
+
+import (
+	"math/rand"
+
+	"adamclerk/deck"
+)
+
+// Riffle returns a shuffle function performing the given number of
+// Gilbert-Shannon-Reeds riffle shuffles, the standard model of how people riffle.
+// The deck is cut binomially and the two packets are interleaved, dropping a card
+// from each packet with probability proportional to its size.
+// It takes seven riffles to mix a 52 card deck reasonably well.
+func Riffle(r *rand.Rand, times int) func(*deck.Deck) {
+	var buf []deck.Card
+	return func(d *deck.Deck) {
+		n := len(d.Cards)
+		if cap(buf) < n {
+			buf = make([]deck.Card, n)
+		}
+		buf = buf[:n]
+		for t := 0; t < times; t++ {
+			cut := 0
+			for i := 0; i < n; i++ {
+				cut += r.Intn(2)
+			}
+			copy(buf, d.Cards)
+			left, right := buf[:cut], buf[cut:]
+			for i := 0; i < n; i++ {
+				if r.Intn(len(left)+len(right)) < len(left) {
+					d.Cards[i] = left[0]
+					left = left[1:]
+				} else {
+					d.Cards[i] = right[0]
+					right = right[1:]
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,235 @@
+// Package shufflestats measures how random a shuffle function really is.
+//
+// A shuffle is run many times over a deck whose cards are labelled with their
+// starting position. From the resulting permutations the package estimates
+// position bias, how many adjacent pairs survive the shuffle, the number of
+// rising sequences and total variation distances from the uniform distribution.
+package shufflestats
+
+import (
+	"errors"
+	"math"
+
+	"adamclerk/deck"
+)
+
+// Options is the struct used to describe how an analysis should be run
+type Options struct {
+	Trials int // number of times the shuffle function is run
+	Size   int // number of cards in the analysed deck
+}
+
+// Report holds the statistics gathered by Analyze
+type Report struct {
+	Trials int
+	Size   int
+
+	// PositionChiSquare is the chi-square statistic of the card/position
+	// contingency table against a uniform distribution.
+	PositionChiSquare float64
+	// PositionDegreesOfFreedom is (Size-1)^2.
+	PositionDegreesOfFreedom int
+	// PositionPValue is the probability of a chi-square at least as large
+	// as PositionChiSquare if the shuffle were perfectly uniform.
+	PositionPValue float64
+
+	// AdjacentPairSurvival is the fraction of originally adjacent pairs that
+	// are still adjacent, in the same order, after shuffling.
+	AdjacentPairSurvival float64
+	// ExpectedAdjacentPairSurvival is the survival rate of a uniform shuffle (1/Size).
+	ExpectedAdjacentPairSurvival float64
+
+	// RisingSequences is a histogram, indexed by the number of rising sequences.
+	RisingSequences []int
+	// MeanRisingSequences is the average number of rising sequences per trial.
+	MeanRisingSequences float64
+	// ExpectedRisingSequences is the mean of a uniform shuffle ((Size+1)/2).
+	ExpectedRisingSequences float64
+
+	// PositionTotalVariation is the total variation distance between each
+	// card's observed position distribution and the uniform distribution,
+	// averaged over all cards.
+	PositionTotalVariation float64
+	// RisingSequenceTotalVariation is the total variation distance between the
+	// observed rising sequence histogram and the Eulerian distribution of a
+	// uniform shuffle. For riffle shuffles this is the whole story: their
+	// distance from uniform depends only on the rising sequences.
+	RisingSequenceTotalVariation float64
+}
+
+// Trials is a functional option used to set the number of shuffles performed.
+func Trials(count int) func(*Options) {
+	return func(o *Options) {
+		o.Trials = count
+	}
+}
+
+// Size is a functional option used to set the number of cards in the analysed deck.
+func Size(count int) func(*Options) {
+	return func(o *Options) {
+		o.Size = count
+	}
+}
+
+// Analyze runs shuffle many times and reports how far it is from a uniform shuffle.
+// The deck handed to shuffle holds the cards deck.Card(0) to deck.Card(Size-1)
+// in order, so shuffles over shoes larger than a single deck can be measured as well.
+// Both total variation estimates are biased upwards by sampling noise; compare
+// them against a known good shuffle such as (*deck.Deck).Shuffle run with the same options.
+func Analyze(shuffle func(*deck.Deck), options ...func(*Options)) (*Report, error) {
+	opt := Options{Trials: 10000, Size: 52}
+	for _, option := range options {
+		option(&opt)
+	}
+	if opt.Trials < 1 {
+		return nil, errors.New("Trials must be positive")
+	}
+	if opt.Size < 2 {
+		return nil, errors.New("Size must be at least 2")
+	}
+
+	n := opt.Size
+	counts := make([]int, n*n)
+	rising := make([]int, n+1)
+	position := make([]int, n)
+	survived := 0
+	cards := make([]deck.Card, n)
+	d := &deck.Deck{NumberOfDecks: 1}
+
+	for t := 0; t < opt.Trials; t++ {
+		for i := range cards {
+			cards[i] = deck.Card(i)
+		}
+		d.Cards = cards
+		shuffle(d)
+		if len(d.Cards) != n {
+			return nil, errors.New("Shuffle changed the number of cards")
+		}
+		for i := range position {
+			position[i] = -1
+		}
+		for pos, card := range d.Cards {
+			label := int(card)
+			if label < 0 || label >= n || position[label] != -1 {
+				return nil, errors.New("Shuffle did not return a permutation")
+			}
+			position[label] = pos
+			counts[label*n+pos]++
+		}
+		sequences := 1
+		for i := 0; i < n-1; i++ {
+			if position[i+1] == position[i]+1 {
+				survived++
+			}
+			if position[i+1] < position[i] {
+				sequences++
+			}
+		}
+		rising[sequences]++
+	}
+
+	report := &Report{
+		Trials:                       opt.Trials,
+		Size:                         n,
+		PositionDegreesOfFreedom:     (n - 1) * (n - 1),
+		AdjacentPairSurvival:         float64(survived) / float64(opt.Trials*(n-1)),
+		ExpectedAdjacentPairSurvival: 1 / float64(n),
+		RisingSequences:              rising,
+		ExpectedRisingSequences:      float64(n+1) / 2,
+	}
+
+	expected := float64(opt.Trials) / float64(n)
+	tv := 0.0
+	for _, c := range counts {
+		diff := float64(c) - expected
+		report.PositionChiSquare += diff * diff / expected
+		tv += math.Abs(diff)
+	}
+	report.PositionTotalVariation = tv / 2 / float64(opt.Trials) / float64(n)
+	report.PositionPValue = ChiSquareSurvival(report.PositionChiSquare, report.PositionDegreesOfFreedom)
+
+	eulerian := EulerianDistribution(n)
+	tv = 0
+	for r, c := range rising {
+		report.MeanRisingSequences += float64(r * c)
+		tv += math.Abs(float64(c)/float64(opt.Trials) - eulerian[r])
+	}
+	report.MeanRisingSequences /= float64(opt.Trials)
+	report.RisingSequenceTotalVariation = tv / 2
+
+	return report, nil
+}
+
+// EulerianDistribution returns the probability that a uniformly random
+// permutation of n cards has r rising sequences, indexed by r (index 0 is always 0).
+func EulerianDistribution(n int) []float64 {
+	// p[k] is the Eulerian number A(m, k) divided by m!, built up one card at a time.
+	p := make([]float64, n+1)
+	p[0] = 1
+	for m := 2; m <= n; m++ {
+		for k := m - 1; k >= 0; k-- {
+			v := float64(k+1) * p[k]
+			if k > 0 {
+				v += float64(m-k) * p[k-1]
+			}
+			p[k] = v / float64(m)
+		}
+	}
+	dist := make([]float64, n+1)
+	copy(dist[1:], p[:n])
+	return dist
+}
+
+// ChiSquareSurvival returns the probability that a chi-square distributed
+// variable with df degrees of freedom is at least x.
+func ChiSquareSurvival(x float64, df int) float64 {
+	if x <= 0 {
+		return 1
+	}
+	return gammaQ(float64(df)/2, x/2)
+}
+
+// gammaQ is the upper regularized incomplete gamma function.
+func gammaQ(a, x float64) float64 {
+	lg, _ := math.Lgamma(a)
+	prefix := math.Exp(a*math.Log(x) - x - lg)
+
+	if x < a+1 {
+		// series representation of P(a, x)
+		sum, term := 1/a, 1/a
+		for n := 1; n < 100000; n++ {
+			term *= x / (a + float64(n))
+			sum += term
+			if math.Abs(term) < math.Abs(sum)*1e-15 {
+				break
+			}
+		}
+		return 1 - sum*prefix
+	}
+
+	// continued fraction representation of Q(a, x), modified Lentz's method
+	const tiny = 1e-300
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for i := 1; i < 100000; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < 1e-15 {
+			break
+		}
+	}
+	return prefix * h
+}
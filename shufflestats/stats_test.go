@@ -0,0 +1,78 @@
+package shufflestats
+
+import (
+	"math/rand"
+	"testing"
+
+	"adamclerk/deck"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyzeKnuthShuffle(t *testing.T) {
+	rand.Seed(1)
+	report, err := Analyze((*deck.Deck).Shuffle, Trials(20000))
+	assert.Nil(t, err)
+	assert.Equal(t, 52, report.Size)
+	assert.Equal(t, 2601, report.PositionDegreesOfFreedom)
+	assert.True(t, report.PositionPValue > 0.001, "p-value %f", report.PositionPValue)
+	assert.InDelta(t, 1.0/52, report.AdjacentPairSurvival, 0.002)
+	assert.InDelta(t, 26.5, report.MeanRisingSequences, 0.1)
+	assert.True(t, report.RisingSequenceTotalVariation < 0.05)
+}
+
+func TestAnalyzeSingleRiffle(t *testing.T) {
+	report, err := Analyze(Riffle(rand.New(rand.NewSource(1)), 1), Trials(2000))
+	assert.Nil(t, err)
+	assert.True(t, report.PositionPValue < 1e-6)
+	// a single riffle leaves at most two rising sequences
+	assert.Equal(t, 0, sum(report.RisingSequences[3:]))
+	assert.True(t, report.RisingSequenceTotalVariation > 0.99)
+	assert.True(t, report.AdjacentPairSurvival > 0.4)
+}
+
+func TestAnalyzeSevenRiffles(t *testing.T) {
+	report, err := Analyze(Riffle(rand.New(rand.NewSource(1)), 7), Trials(20000))
+	assert.Nil(t, err)
+	// Bayer and Diaconis: seven riffles of 52 cards are 0.334 from uniform
+	assert.InDelta(t, 0.334, report.RisingSequenceTotalVariation, 0.03)
+}
+
+func TestAnalyzeRejectsBrokenShuffles(t *testing.T) {
+	_, err := Analyze(func(d *deck.Deck) { d.Cards = d.Cards[1:] }, Trials(1))
+	assert.Equal(t, "Shuffle changed the number of cards", err.Error())
+
+	_, err = Analyze(func(d *deck.Deck) { d.Cards[0] = d.Cards[1] }, Trials(1))
+	assert.Equal(t, "Shuffle did not return a permutation", err.Error())
+
+	_, err = Analyze((*deck.Deck).Shuffle, Trials(0))
+	assert.Equal(t, "Trials must be positive", err.Error())
+}
+
+func TestEulerianDistribution(t *testing.T) {
+	// A(4, k) = 1, 11, 11, 1
+	dist := EulerianDistribution(4)
+	assert.InDeltaSlice(t, []float64{0, 1.0 / 24, 11.0 / 24, 11.0 / 24, 1.0 / 24}, dist, 1e-12)
+	assert.InDelta(t, 1, sum64(EulerianDistribution(52)), 1e-9)
+}
+
+func TestChiSquareSurvival(t *testing.T) {
+	assert.InDelta(t, 0.05, ChiSquareSurvival(3.841, 1), 1e-3)
+	assert.InDelta(t, 0.05, ChiSquareSurvival(18.307, 10), 1e-3)
+	assert.InDelta(t, 0.5, ChiSquareSurvival(2600.33, 2601), 1e-2)
+}
+
+func sum(values []int) int {
+	total := 0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+
+func sum64(values []float64) float64 {
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}